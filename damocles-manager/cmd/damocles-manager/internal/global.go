@@ -58,6 +58,12 @@ var ConfDirFlag = &cli.StringFlag{
 	EnvVars: []string{"DAMOCLES_CONF_DIR", "VENUS_SECTOR_MANAGER_CONF_DIR", "VSM_CONF_DIR"},
 }
 
+var DefaultMinerFlag = &cli.StringFlag{
+	Name:    "default-miner",
+	Usage:   "the miner actor id/address used by the commands when the --miner flag is omitted",
+	EnvVars: []string{"DAMOCLES_DEFAULT_MINER"},
+}
+
 type stopper = func()
 
 func NewSigContext(parent context.Context) (context.Context, context.CancelFunc) {
@@ -179,6 +185,31 @@ func ShouldActor(s string, checkEmpty bool) (abi.ActorID, error) {
 	return abi.ActorID(actor), nil
 }
 
+// minerOrDefault returns the value of the --miner flag, or the default miner if the flag is omitted.
+func minerOrDefault(cctx *cli.Context) string {
+	if m := cctx.String("miner"); m != "" {
+		return m
+	}
+
+	return cctx.String(DefaultMinerFlag.Name)
+}
+
+// minerArgOrDefault returns the miner and the other positional args of the commands taking
+// `<miner actor> <args>...`, n is the number of the args after the miner.
+// The miner could be omitted if the default miner is set, ok is false if the args are not enough.
+func minerArgOrDefault(cctx *cli.Context, n int) (miner string, args []string, ok bool) {
+	args = cctx.Args().Slice()
+	if len(args) > n {
+		return args[0], args[1:], true
+	}
+
+	if def := cctx.String(DefaultMinerFlag.Name); def != "" && len(args) == n {
+		return def, args, true
+	}
+
+	return "", nil, false
+}
+
 func ShouldSectorNumber(s string) (abi.SectorNumber, error) {
 	num, err := strconv.ParseUint(s, 10, 64)
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
 	"github.com/ipfs-force-community/damocles/damocles-manager/cmd/damocles-manager/internal"
 	"github.com/stretchr/testify/require"
 )
@@ -14,3 +16,29 @@ func TestOutputJSONWithNil(t *testing.T) {
 	require.NoError(t, internal.OutputJSON(&sb, nil))
 	require.Equal(t, "null", strings.TrimRight(sb.String(), "\n"))
 }
+
+func TestFormatFIL(t *testing.T) {
+	amount := big.NewInt(1_234_567_890_000_000_000)
+
+	require.Equal(t, "1.2346 FIL", internal.FormatFIL(amount, 4, true))
+	require.Equal(t, "1.23", internal.FormatFIL(amount, 2, false))
+	require.Equal(t, "1 FIL", internal.FormatFIL(amount, 0, true))
+	require.Equal(t, "1234567890000000000 attoFIL", internal.FormatFIL(amount, -1, true))
+	require.Equal(t, "0.0000 FIL", internal.FormatFIL(abi.TokenAmount{}, 4, true))
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	for raw, expected := range map[string]abi.TokenAmount{
+		"1.5":          big.NewInt(1_500_000_000_000_000_000),
+		"1.5 FIL":      big.NewInt(1_500_000_000_000_000_000),
+		"100 nanoFIL":  big.NewInt(100_000_000_000),
+		"1234 attoFIL": big.NewInt(1234),
+	} {
+		amount, err := internal.ParseTokenAmount(raw)
+		require.NoError(t, err, raw)
+		require.True(t, expected.Equals(amount), "%s: got %s", raw, amount)
+	}
+
+	_, err := internal.ParseTokenAmount("1 BTC")
+	require.Error(t, err)
+}
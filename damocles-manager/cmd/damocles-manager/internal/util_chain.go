@@ -42,18 +42,19 @@ var utilChainHeadCmd = &cli.Command{
 var utilChainPreCommitInfoCmd = &cli.Command{
 	Name:      "pci",
 	Usage:     "Show on-chain pre-commit info for specified sector",
-	ArgsUsage: "<miner actor id> <sector number>",
+	ArgsUsage: "[<miner actor id>] <sector number>",
 	Action: func(cctx *cli.Context) error {
-		if count := cctx.Args().Len(); count < 2 {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
 			return cli.ShowSubcommandHelp(cctx)
 		}
 
-		maddr, err := ShouldAddress(cctx.Args().Get(0), true, true)
+		maddr, err := ShouldAddress(minerArg, true, true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
 
-		sectorNum, err := ShouldSectorNumber(cctx.Args().Get(1))
+		sectorNum, err := ShouldSectorNumber(args[0])
 		if err != nil {
 			return err
 		}
@@ -3,7 +3,9 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"os"
 
+	"github.com/BurntSushi/toml"
 	"github.com/docker/go-units"
 	"github.com/filecoin-project/go-address"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -19,6 +21,7 @@ import (
 	"github.com/filecoin-project/venus/venus-shared/types"
 
 	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/messager"
 )
 
@@ -28,15 +31,71 @@ var utilMinerCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		utilMinerInfoCmd,
 		utilMinerCreateCmd,
+		utilMinerConfigCmd,
+	},
+}
+
+var utilMinerConfigCmd = &cli.Command{
+	Name:      "config",
+	Usage:     "Print the effective config of the miner, with the defaults applied",
+	ArgsUsage: "[<miner address>]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output in json format instead of toml",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerArg, _, _ := minerArgOrDefault(cctx, 0)
+		mid, err := ShouldActor(minerArg, true)
+		if err != nil {
+			if errors.Is(err, ErrEmptyAddressString) {
+				return ShowHelp(cctx, err)
+			}
+
+			return err
+		}
+
+		api, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		mcfg, err := api.Damocles.GetMinerConfig(gctx, mid)
+		if err != nil {
+			return RPCCallError("GetMinerConfig", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, mcfg)
+		}
+
+		// in the same layout as the config file, so that it could be compared directly
+		resolved := struct {
+			Miners []modules.MinerConfig
+		}{
+			Miners: []modules.MinerConfig{*mcfg},
+		}
+
+		enc := toml.NewEncoder(os.Stdout)
+		enc.Indent = ""
+		if err := enc.Encode(&resolved); err != nil {
+			return fmt.Errorf("encode miner config: %w", err)
+		}
+
+		return nil
 	},
 }
 
 var utilMinerInfoCmd = &cli.Command{
 	Name:      "info",
 	Usage:     "Print miner info",
-	ArgsUsage: "<miner address>",
+	ArgsUsage: "[<miner address>]",
 	Action: func(cctx *cli.Context) error {
-		maddr, err := ShouldAddress(cctx.Args().First(), true, true)
+		minerArg, _, _ := minerArgOrDefault(cctx, 0)
+		maddr, err := ShouldAddress(minerArg, true, true)
 		if err != nil {
 			if errors.Is(err, ErrEmptyAddressString) {
 				return ShowHelp(cctx, err)
@@ -1,6 +1,15 @@
 package internal
 
-import "github.com/urfave/cli/v2"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+)
 
 var utilSealerCmd = &cli.Command{
 	Name:  "sealer",
@@ -11,5 +20,110 @@ var utilSealerCmd = &cli.Command{
 		utilSealerProvingCmd,
 		utilSealerActorCmd,
 		utilSealerSnapCmd,
+		utilSealerHealthCmd,
+		utilSealerAPICheckCmd,
+		utilSealerProverInfoCmd,
+	},
+}
+
+var utilSealerHealthCmd = &cli.Command{
+	Name:  "health",
+	Usage: "Check the dependencies of the sealer, exits with an error if any of them is unhealthy",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		report, err := api.Damocles.HealthCheck(ctx)
+		if err != nil {
+			return RPCCallError("HealthCheck", err)
+		}
+
+		if cctx.Bool("json") {
+			if err := OutputJSON(os.Stdout, report); err != nil {
+				return err
+			}
+		} else {
+			statuses := append([]core.HealthStatus{report.Chain, report.Messager, report.StateStore}, report.Stores...)
+			for _, st := range statuses {
+				if st.Healthy {
+					_, _ = fmt.Fprintf(os.Stdout, "%s: ok (%s)\n", st.Name, st.Elapsed)
+				} else {
+					_, _ = fmt.Fprintf(os.Stdout, "%s: %s (%s)\n", st.Name, st.Err, st.Elapsed)
+				}
+			}
+		}
+
+		if !report.Healthy {
+			return fmt.Errorf("unhealthy")
+		}
+
+		return nil
+	},
+}
+
+var utilSealerAPICheckCmd = &cli.Command{
+	Name:      "api-check",
+	Usage:     "Check if the api of the client matches the server's, all of the methods are checked if none is given",
+	ArgsUsage: "[<method>...]",
+	Action: func(cctx *cli.Context) error {
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		remote, err := api.Damocles.APIFingerprint(ctx)
+		if err != nil {
+			return RPCCallError("APIFingerprint", err)
+		}
+
+		if err := core.CheckAPIFingerprint(remote, cctx.Args().Slice()...); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, "ok")
+		return nil
+	},
+}
+
+var utilSealerProverInfoCmd = &cli.Command{
+	Name:  "prover-info",
+	Usage: "Show the prover backend in use, warns if it is not the production one",
+	Action: func(cctx *cli.Context) error {
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		info, err := api.Damocles.ProverInfo(ctx)
+		if err != nil {
+			return RPCCallError("ProverInfo", err)
+		}
+
+		backend := info.Backend
+		if !info.Production {
+			backend = color.RedString("%s (NOT for production use)", info.Backend)
+		}
+
+		gpus := "none detected"
+		if len(info.GPUs) > 0 {
+			gpus = strings.Join(info.GPUs, ", ")
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Backend:\t%s\n", backend)
+		_, _ = fmt.Fprintf(os.Stdout, "Version:\t%s\n", info.Version)
+		_, _ = fmt.Fprintf(os.Stdout, "Implementation:\t%s\n", info.Implementation)
+		_, _ = fmt.Fprintf(os.Stdout, "GPUs:\t%s\n", gpus)
+		return nil
 	},
 }
@@ -38,9 +38,8 @@ var utilSealerActorCmd = &cli.Command{
 	Usage: "Manipulate the miner actor",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     "miner",
-			Required: true,
-			Usage:    "miner actor id/address",
+			Name:  "miner",
+			Usage: "miner actor id/address, the default miner will be used if omitted",
 		},
 	},
 	Subcommands: []*cli.Command{
@@ -61,7 +60,7 @@ var utilSealerActorCmd = &cli.Command{
 var utilSealerActorBalanceCmd = &cli.Command{
 	Name: "balance",
 	Action: func(cctx *cli.Context) error {
-		miner, err := ShouldAddress(cctx.String("miner"), false, true)
+		miner, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -87,7 +86,7 @@ var utilSealerActorBalanceCmd = &cli.Command{
 			return RPCCallError("StateGetActor", err)
 		}
 
-		mlog.Infof("balance: %s", modules.FIL(actor.Balance).Short())
+		mlog.Infof("balance: %s", FormatFIL(actor.Balance, 4, true))
 
 		return nil
 	},
@@ -102,7 +101,7 @@ var utilSealerActorAddBalanceCmd = &cli.Command{
 	},
 	ArgsUsage: "<from address> <amount>",
 	Action: func(cctx *cli.Context) error {
-		to, err := ShouldAddress(cctx.String("miner"), false, true)
+		to, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -170,7 +169,7 @@ var utilSealerActorWithdrawCmd = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -197,8 +196,8 @@ var utilSealerActorWithdrawCmd = &cli.Command{
 			if amount.GreaterThan(available) {
 				return fmt.Errorf(
 					"can't withdraw more funds than available; requested: %s; available: %s",
-					types.FIL(amount),
-					types.FIL(available),
+					FormatFIL(amount, 4, true),
+					FormatFIL(available, 4, true),
 				)
 			}
 		}
@@ -255,9 +254,9 @@ var utilSealerActorWithdrawCmd = &cli.Command{
 				return err
 			}
 
-			fmt.Printf("Successfully withdrew %s \n", types.FIL(withdrawn))
+			fmt.Printf("Successfully withdrew %s \n", FormatFIL(withdrawn, 4, true))
 			if withdrawn.LessThan(amount) {
-				fmt.Printf("Note that this is less than the requested amount of %s\n", types.FIL(amount))
+				fmt.Printf("Note that this is less than the requested amount of %s\n", FormatFIL(amount, 4, true))
 			}
 		}
 
@@ -282,7 +281,7 @@ var utilSealerActorRepayDebtCmd = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -383,7 +382,7 @@ var utilSealerActorControlList = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -540,7 +539,7 @@ var utilSealerActorControlSet = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -654,7 +653,7 @@ var utilSealerActorSetOwnerCmd = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -746,7 +745,7 @@ var utilSealerActorProposeChangeWorker = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -857,7 +856,7 @@ var utilSealerActorConfirmChangeWorker = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -964,7 +963,7 @@ var utilSealerActorCompactAllocatedCmd = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -1101,7 +1100,7 @@ var utilSealerActorProposeChangeBeneficiary = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -1227,7 +1226,7 @@ var utilSealerActorConfirmChangeBeneficiary = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), false, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), false, true)
 		if err != nil {
 			return err
 		}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	mbig "math/big"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -31,6 +33,7 @@ import (
 	"github.com/filecoin-project/venus/venus-shared/types"
 
 	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/policy"
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/messager"
@@ -50,8 +53,13 @@ var utilSealerProvingCmd = &cli.Command{
 		utilSealerProvingDeadlinesCmd,
 		utilSealerProvingDeadlineInfoCmd,
 		utilSealerProvingCheckProvableCmd,
+		utilSealerProvingCheckMinersCmd,
+		utilSealerProvingPrePoStCheckCmd,
+		utilSealerProvingReportCmd,
 		utilSealerProvingSimulateWdPoStCmd,
 		utilSealerProvingSectorInfoCmd,
+		utilSealerProvingInspectSectorCmd,
+		utilSealerProvingCheckSectorCmd,
 		utilSealerProvingWinningVanillaCmd,
 		utilSealerProvingCompactPartitionsCmd,
 		utilSealerProvingRecoverFaultsCmd,
@@ -78,6 +86,40 @@ func EpochTime(curr, e abi.ChainEpoch, blockDelay uint64) string {
 	return fmt.Sprintf("%d (now)", e)
 }
 
+var attoPerFIL = mbig.NewInt(1_000_000_000_000_000_000)
+
+// FormatFIL formats the amount in FIL, rounded to the given number of decimal places.
+// A negative precision keeps the full attoFIL value, in attoFIL.
+func FormatFIL(amount abi.TokenAmount, precision int, withUnit bool) string {
+	if amount.Nil() {
+		amount = big.Zero()
+	}
+
+	var s, unit string
+	if precision < 0 {
+		s, unit = amount.String(), "attoFIL"
+	} else {
+		s, unit = new(mbig.Rat).SetFrac(amount.Int, attoPerFIL).FloatString(precision), "FIL"
+	}
+
+	if withUnit {
+		return s + " " + unit
+	}
+
+	return s
+}
+
+// ParseTokenAmount parses strings like `1.5`, `1.5 FIL`, `100 nanoFIL` or `1 attoFIL` into a token amount,
+// values without unit are in FIL.
+func ParseTokenAmount(s string) (abi.TokenAmount, error) {
+	f, err := modules.ParseFIL(s)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+
+	return f.Std(), nil
+}
+
 func HeightToTime(ts *types.TipSet, openHeight abi.ChainEpoch, blockDelay uint64) string {
 	if ts.Len() == 0 {
 		return ""
@@ -96,7 +138,7 @@ var utilSealerProvingInfoCmd = &cli.Command{
 		}
 		defer astop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -210,7 +252,7 @@ var utilSealerProvingFaultsCmd = &cli.Command{
 
 		stor := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(api.Chain)))
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -289,7 +331,7 @@ var utilSealerProvingDeadlinesCmd = &cli.Command{
 			return err
 		}
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -407,13 +449,18 @@ var utilSealerProvingDeadlineInfoCmd = &cli.Command{
 			return fmt.Errorf("could not parse deadline index: %w", err)
 		}
 
+		skipFaulty := cctx.Bool("skip-faulty")
+		if skipFaulty && cctx.Bool("faulty") {
+			return fmt.Errorf("--skip-faulty conflicts with --faulty")
+		}
+
 		api, ctx, stop, err := extractAPI(cctx)
 		if err != nil {
 			return err
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -555,6 +602,11 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 			Usage: "print only bad sectors",
 			Value: false,
 		},
+		&cli.BoolFlag{
+			Name:  "skip-faulty",
+			Usage: "skip the sectors already declared faulty on chain",
+			Value: false,
+		},
 		&cli.BoolFlag{
 			Name:  "slow",
 			Usage: "run slower checks",
@@ -567,6 +619,14 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 			Name:  "faulty",
 			Usage: "only check faulty sectors",
 		},
+		&cli.IntFlag{
+			Name:  "parallel",
+			Usage: "maximum number of sector checks to run in parallel, 0 means using the value in the proving config",
+		},
+		&cli.DurationFlag{
+			Name:  "sector-timeout",
+			Usage: "maximum amount of time the check of a single sector can take, 0 means no limit",
+		},
 		&cli.BoolFlag{
 			Name:  "detail",
 			Usage: "show detail",
@@ -589,7 +649,7 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 		}
 		defer stop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -631,11 +691,7 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 
 		slow := cctx.Bool("slow")
 		stateCheck := cctx.Bool("state-check")
-
-		nv, err := api.Chain.StateNetworkVersion(ctx, types.EmptyTSK)
-		if err != nil {
-			return fmt.Errorf("failed to load network version: %w", err)
-		}
+		parallel := cctx.Int("parallel")
 
 		for parIdx := range partitions {
 			sectors := make(map[abi.SectorNumber]struct{})
@@ -657,6 +713,16 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 					}
 				}
 
+				if skipFaulty {
+					faulty, err := partitions[parIdx].FaultySectors.IsSet(uint64(info.SectorNumber))
+					if err != nil {
+						return err
+					}
+					if faulty {
+						continue
+					}
+				}
+
 				sectors[info.SectorNumber] = struct{}{}
 				tocheck = append(tocheck, util.SectorOnChainInfoToExtended(info))
 			}
@@ -665,12 +731,18 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 				continue
 			}
 
-			postProofType, err := tocheck[0].SealProof.RegisteredWindowPoStProofByNetworkVersion(nv)
-			if err != nil {
-				return fmt.Errorf("invalid seal proof type %d: %w", tocheck[0].SealProof, err)
-			}
-
-			bad, err := api.Damocles.CheckProvable(ctx, abi.ActorID(mid), postProofType, tocheck, slow, stateCheck)
+			bad, err := api.Damocles.CheckProvableMixed(
+				ctx,
+				abi.ActorID(mid),
+				tocheck,
+				slow,
+				stateCheck,
+				core.ProvableOptions{
+					ParallelCheckLimit: parallel,
+					SkipFaulty:         skipFaulty,
+					SectorTimeout:      cctx.Duration("sector-timeout"),
+				},
+			)
 			if err != nil {
 				return err
 			}
@@ -699,6 +771,254 @@ var utilSealerProvingCheckProvableCmd = &cli.Command{
 	},
 }
 
+var utilSealerProvingCheckMinersCmd = &cli.Command{
+	Name:      "check-miners",
+	Usage:     "Check the live sectors in the deadline of multiple miners concurrently",
+	ArgsUsage: "<deadlineIdx>",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:     "miners",
+			Usage:    "miners to be checked, eg. --miners f01000 --miners f01001",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "slow",
+			Usage: "run slower checks",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		dlIdx, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse deadline index: %w", err)
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		nv, err := api.Chain.StateNetworkVersion(ctx, types.EmptyTSK)
+		if err != nil {
+			return fmt.Errorf("failed to load network version: %w", err)
+		}
+
+		var requests []core.ProvableRequest
+		for _, m := range cctx.StringSlice("miners") {
+			mid, err := ShouldActor(m, true)
+			if err != nil {
+				return err
+			}
+
+			maddr, err := address.NewIDAddress(uint64(mid))
+			if err != nil {
+				return err
+			}
+
+			partitions, err := api.Chain.StateMinerPartitions(ctx, maddr, dlIdx, types.EmptyTSK)
+			if err != nil {
+				return fmt.Errorf("get partitions of %s: %w", maddr, err)
+			}
+
+			var tocheck []builtin.ExtendedSectorInfo
+			for parIdx := range partitions {
+				sectorInfos, err := api.Chain.StateMinerSectors(ctx, maddr, &partitions[parIdx].LiveSectors, types.EmptyTSK)
+				if err != nil {
+					return fmt.Errorf("get sectors of %s: %w", maddr, err)
+				}
+
+				for _, info := range sectorInfos {
+					tocheck = append(tocheck, util.SectorOnChainInfoToExtended(info))
+				}
+			}
+
+			if len(tocheck) == 0 {
+				continue
+			}
+
+			postProofType, err := tocheck[0].SealProof.RegisteredWindowPoStProofByNetworkVersion(nv)
+			if err != nil {
+				return fmt.Errorf("invalid seal proof type %d: %w", tocheck[0].SealProof, err)
+			}
+
+			requests = append(requests, core.ProvableRequest{
+				Miner:         mid,
+				PostProofType: postProofType,
+				Sectors:       tocheck,
+				Strict:        cctx.Bool("slow"),
+			})
+		}
+
+		results, err := api.Damocles.CheckProvableMulti(ctx, requests)
+		if err != nil {
+			return RPCCallError("CheckProvableMulti", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "miner\tdeadline\tgood\tbad")
+		for _, req := range requests {
+			res := results[req.Miner]
+			if res.Err != "" {
+				_, _ = fmt.Fprintf(tw, "%d\t%d\t%s\t\n", req.Miner, dlIdx, color.RedString(res.Err))
+				continue
+			}
+
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t%d\t%d\n", req.Miner, dlIdx, len(req.Sectors)-len(res.Bad), len(res.Bad))
+		}
+
+		return tw.Flush()
+	},
+}
+
+var utilSealerProvingPrePoStCheckCmd = &cli.Command{
+	Name:      "pre-check",
+	Usage:     "Check the sectors to be proven in the given deadline, and show the ones would be skipped",
+	ArgsUsage: "<deadlineIdx>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		dlIdx, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse deadline index: %w", err)
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
+		if err != nil {
+			return err
+		}
+
+		mid, err := address.IDFromAddress(maddr)
+		if err != nil {
+			return err
+		}
+
+		minfo, err := api.Chain.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		bad, err := api.Damocles.PrePoStCheck(ctx, abi.ActorID(mid), minfo.WindowPoStProofType, dlIdx)
+		if err != nil {
+			return RPCCallError("PrePoStCheck", err)
+		}
+
+		if len(bad) == 0 {
+			fmt.Printf("deadline %d: no sector would be skipped\n", dlIdx)
+			return nil
+		}
+
+		nums := make([]abi.SectorNumber, 0, len(bad))
+		for num := range bad {
+			nums = append(nums, num)
+		}
+		sort.Slice(nums, func(i, j int) bool {
+			return nums[i] < nums[j]
+		})
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "deadline\tsector\treason")
+		for _, num := range nums {
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t%s\n", dlIdx, num, color.RedString(bad[num]))
+		}
+
+		return tw.Flush()
+	},
+}
+
+var utilSealerProvingReportCmd = &cli.Command{
+	Name:  "report",
+	Usage: "Check the live sectors in all the deadlines, and report the faulty ones grouped by category",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "slow",
+			Usage: "run slower checks",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-faulty",
+			Usage: "skip the sectors already declared faulty on chain",
+		},
+		&cli.DurationFlag{
+			Name:  "sector-timeout",
+			Usage: "maximum amount of time the check of a single sector can take, 0 means no limit",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the report in json format, which can be stored for later comparison",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
+		if err != nil {
+			return err
+		}
+
+		mid, err := address.IDFromAddress(maddr)
+		if err != nil {
+			return err
+		}
+
+		report, err := api.Damocles.ProvabilityReport(ctx, abi.ActorID(mid), cctx.Bool("slow"), core.ProvableOptions{
+			SkipFaulty:    cctx.Bool("skip-faulty"),
+			SectorTimeout: cctx.Duration("sector-timeout"),
+		})
+		if err != nil {
+			return RPCCallError("ProvabilityReport", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, report)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "deadline\tcategory\tsector\treason")
+		for _, dl := range report.Deadlines {
+			categories := make([]string, 0, len(dl.Faults))
+			for category := range dl.Faults {
+				categories = append(categories, category)
+			}
+			sort.Strings(categories)
+
+			for _, category := range categories {
+				for _, fault := range dl.Faults[category] {
+					_, _ = fmt.Fprintf(
+						tw,
+						"%d\t%s\t%d\t%s\n",
+						dl.Deadline,
+						category,
+						fault.Sector,
+						color.RedString(fault.Reason),
+					)
+				}
+			}
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "\nchecked: %d, faulty: %d\n", report.Checked, report.Faulty)
+		return nil
+	},
+}
+
 var utilSealerProvingSimulateWdPoStCmd = &cli.Command{
 	Name:  "simulate-wdpost",
 	Usage: "Do not execute during normal wdPoSt operation, so as not to occupy sectors or gpu",
@@ -735,7 +1055,7 @@ var utilSealerProvingSimulateWdPoStCmd = &cli.Command{
 			return fmt.Errorf("get chain head failed: %w", err)
 		}
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -849,7 +1169,7 @@ var utilSealerProvingSectorInfoCmd = &cli.Command{
 		}
 		defer astop()
 
-		mid, err := ShouldActor(cctx.String("miner"), true)
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
 		if err != nil {
 			return err
 		}
@@ -888,6 +1208,117 @@ var utilSealerProvingSectorInfoCmd = &cli.Command{
 	},
 }
 
+var utilSealerProvingInspectSectorCmd = &cli.Command{
+	Name:      "inspect-sector",
+	Usage:     "Resolve the files required for proving the sector, and show which one is missing",
+	ArgsUsage: "<sector number>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		num, err := ShouldSectorNumber(cctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		res, err := api.Damocles.InspectPrivateSectorInfo(ctx, abi.SectorID{Miner: mid, Number: num})
+		if err != nil {
+			return RPCCallError("InspectPrivateSectorInfo", err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Upgrade: %v\n", res.Upgrade)
+		if res.Private != nil {
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"Sealed: %s (%s)\nCache: %s (%s)\n",
+				res.Private.SealedSectorPath,
+				res.Private.Accesses.SealedFile,
+				res.Private.CacheDirPath,
+				res.Private.Accesses.CacheDir,
+			)
+		}
+
+		if len(res.Artifacts) > 0 {
+			tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "\ntitle\tfile\tsize\tstatus")
+			for _, a := range res.Artifacts {
+				status := color.GreenString("ok")
+				if a.Err != "" {
+					status = color.RedString(a.Err)
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", a.Title, a.URI, a.Size, status)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+
+		if res.Err != "" {
+			return fmt.Errorf("sector can not be proven: %s", res.Err)
+		}
+
+		_, _ = fmt.Fprintln(os.Stdout, "\nall the files required for proving are in place")
+		return nil
+	},
+}
+
+var utilSealerProvingCheckSectorCmd = &cli.Command{
+	Name:      "check-sector",
+	Usage:     "Check the provability of a single sector",
+	ArgsUsage: "<sector number>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "check in strict mode",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		num, err := ShouldSectorNumber(cctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		res, err := api.Damocles.CheckSectorProvable(ctx, abi.SectorID{Miner: mid, Number: num}, cctx.Bool("strict"))
+		if err != nil {
+			return RPCCallError("CheckSectorProvable", err)
+		}
+
+		if !res.Provable {
+			return fmt.Errorf("sector %s is not provable: %s", util.FormatSectorID(res.ID), color.RedString(res.Reason))
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "sector %s is provable\n", util.FormatSectorID(res.ID))
+		return nil
+	},
+}
+
 var utilSealerProvingWinningVanillaCmd = &cli.Command{
 	Name: "winning-vanilla",
 	Flags: []cli.Flag{
@@ -1062,7 +1493,7 @@ var utilSealerProvingCompactPartitionsCmd = &cli.Command{
 		}
 		defer astop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return fmt.Errorf("extract miner address: %w", err)
 		}
@@ -1150,7 +1581,7 @@ var utilSealerProvingRecoverFaultsCmd = &cli.Command{
 		}
 		defer astop()
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return fmt.Errorf("extract miner address: %w", err)
 		}
@@ -14,7 +14,10 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/docker/go-units"
 	"github.com/fatih/color"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -86,20 +89,41 @@ var utilSealerSectorsCmd = &cli.Command{
 	Usage: "Commands for interacting with sectors",
 	Subcommands: []*cli.Command{
 		utilSealerSectorsAbortCmd,
+		utilSealerSectorsAbandonCmd,
 		utilSealerSectorsListCmd,
 		utilSealerSectorsRestoreCmd,
+		utilSealerSectorsRestoreAbortedCmd,
+		utilSealerSectorsReplayCmd,
 		utilSealerSectorsCheckExpireCmd,
+		utilSealerSectorsExpiringCmd,
+		utilSealerSectorsSealedDealsCmd,
+		utilSealerSectorsSealStatsCmd,
+		utilSealerSectorsDealStatsCmd,
+		utilSealerSectorsStuckCmd,
+		utilSealerSectorsReconcileCmd,
+		utilSealerSectorsStateMachineCmd,
 		utilSealerSectorsExpiredCmd,
 		utilSealerSectorsExtendCmd,
 		utilSealerSectorsTerminateCmd,
+		utilSealerSectorsPathsCmd,
+		utilSealerSectorsPiecesCmd,
+		utilSealerSectorsCanRemoveCmd,
 		utilSealerSectorsRemoveCmd,
+		utilSealerSectorsCheckCacheCmd,
+		utilSealerSectorsVerifySealedSizeCmd,
 		utilSealerSectorsFinalizeCmd,
 		utilSealerSectorsStateCmd,
 		utilSealerSectorsFindDealCmd,
 		utilSealerSectorsResendPreCommitCmd,
 		utilSealerSectorsResendProveCommitCmd,
 		utilSealerSectorsImportCmd,
+		utilSealerSectorsImportDirCmd,
 		utilSealerSectorsRebuildCmd,
+		utilSealerSectorsRebuildListCmd,
+		utilSealerSectorsRebuildProgressCmd,
+		utilSealerSectorsValidateUpgradeCmd,
+		utilSealerSectorsRederiveTicketCmd,
+		utilSealerSectorsLabelCmd,
 		utilSealerSectorsExportToLotusCmd,
 		utilSealerSectorsUnsealCmd,
 	},
@@ -116,7 +140,7 @@ func extractListWorkerState(cctx *cli.Context) core.SectorWorkerState {
 var utilSealerSectorsAbortCmd = &cli.Command{
 	Name:      "abort",
 	Usage:     "Abort specified online sector job",
-	ArgsUsage: "<miner actor> <sector number>",
+	ArgsUsage: "[<miner actor>] <sector number>",
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "really-do-it",
@@ -125,19 +149,20 @@ var utilSealerSectorsAbortCmd = &cli.Command{
 		},
 	},
 	Action: func(cctx *cli.Context) error {
-		if count := cctx.Args().Len(); count < 2 {
-			return fmt.Errorf("both miner actor id & sector number are required, only %d args provided", count)
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return IncorrectNumArgs(cctx)
 		}
 		if !cctx.Bool("really-do-it") {
 			fmt.Println("If you know what you're doing, Pass --really-do-it to actually execute this action")
 			return nil
 		}
-		miner, err := ShouldActor(cctx.Args().Get(0), true)
+		miner, err := ShouldActor(minerArg, true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
 
-		sectorNum, err := strconv.ParseUint(cctx.Args().Get(1), 10, 64)
+		sectorNum, err := strconv.ParseUint(args[0], 10, 64)
 		if err != nil {
 			return fmt.Errorf("invalid sector number: %w", err)
 		}
@@ -160,6 +185,42 @@ var utilSealerSectorsAbortCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsAbandonCmd = &cli.Command{
+	Name:      "abandon",
+	Usage:     "Give up an in-progress sector, release the resources held by it and record the reason",
+	ArgsUsage: "[<miner actor>] <sector number> <reason>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 2)
+		if !ok || len(args) != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		err = cli.Damocles.AbandonSector(gctx, abi.SectorID{Miner: miner, Number: num}, args[1])
+		if err != nil {
+			return RPCCallError("AbandonSector", err)
+		}
+
+		return nil
+	},
+}
+
 var utilSealerSectorsListCmd = &cli.Command{
 	Name:  "list",
 	Usage: "Print sector data",
@@ -172,8 +233,46 @@ var utilSealerSectorsListCmd = &cli.Command{
 			Name:  "miner",
 			Usage: "show sectors of the given miner only ",
 		},
+		&cli.Uint64Flag{
+			Name:  "deadline",
+			Usage: "show both online and offline sectors assigned to the given deadline only, requires --miner",
+		},
+		&cli.DurationFlag{
+			Name:  "changed-within",
+			Usage: "show both online and offline sectors whose states changed within the given duration only",
+		},
+		&cli.BoolFlag{
+			Name:  "aborted",
+			Usage: "show sectors with an abort reason only",
+		},
+		&cli.StringFlag{
+			Name:  "abort-reason",
+			Usage: "show sectors whose abort reason contains the given text only, implies --aborted",
+		},
+		&cli.StringSliceFlag{
+			Name:  "label",
+			Usage: "show sectors having the given label only, in the format of key=value, can be repeated",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
+		var minerID *abi.ActorID
+		if m := cctx.String("miner"); m != "" {
+			mid, err := ShouldActor(m, true)
+			if err != nil {
+				return fmt.Errorf("invalid miner actor id: %w", err)
+			}
+
+			minerID = &mid
+		}
+
+		if cctx.IsSet("deadline") && minerID == nil {
+			return fmt.Errorf("--miner is required when --deadline is set")
+		}
+
+		if cctx.IsSet("deadline") && cctx.IsSet("changed-within") {
+			return fmt.Errorf("--deadline and --changed-within can't be used together")
+		}
+
 		cli, gctx, stop, err := extractAPI(cctx)
 		if err != nil {
 			return err
@@ -181,19 +280,48 @@ var utilSealerSectorsListCmd = &cli.Command{
 
 		defer stop()
 
-		states, err := cli.Damocles.ListSectors(gctx, extractListWorkerState(cctx), core.SectorWorkerJobAll)
+		labels, err := parseSectorLabels(cctx.StringSlice("label"))
 		if err != nil {
 			return err
 		}
 
-		var minerID *abi.ActorID
-		if m := cctx.String("miner"); m != "" {
-			mid, err := ShouldActor(m, true)
-			if err != nil {
-				return fmt.Errorf("invalid miner actor id: %w", err)
+		opts := core.ListSectorsOptions{
+			Aborted:             cctx.Bool("aborted"),
+			AbortReasonContains: cctx.String("abort-reason"),
+			Labels:              labels,
+		}
+
+		var states []*core.SectorState
+		if cctx.IsSet("deadline") || cctx.IsSet("changed-within") {
+			if cctx.IsSet("deadline") {
+				states, err = cli.Damocles.ListSectorsByDeadline(gctx, *minerID, cctx.Uint64("deadline"))
+				if err != nil {
+					return RPCCallError("ListSectorsByDeadline", err)
+				}
+			} else {
+				states, err = cli.Damocles.SectorsChangedSince(gctx, time.Now().Add(-cctx.Duration("changed-within")))
+				if err != nil {
+					return RPCCallError("SectorsChangedSince", err)
+				}
 			}
 
-			minerID = &mid
+			matched := states[:0]
+			for _, st := range states {
+				if opts.Match(st) {
+					matched = append(matched, st)
+				}
+			}
+			states = matched
+		} else if opts.IsEmpty() {
+			states, err = cli.Damocles.ListSectors(gctx, extractListWorkerState(cctx), core.SectorWorkerJobAll)
+			if err != nil {
+				return err
+			}
+		} else {
+			states, err = cli.Damocles.ListSectorsEx(gctx, extractListWorkerState(cctx), core.SectorWorkerJobAll, opts)
+			if err != nil {
+				return RPCCallError("ListSectorsEx", err)
+			}
 		}
 
 		selectors := []struct {
@@ -289,6 +417,63 @@ var utilSealerSectorsListCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsReplayCmd = &cli.Command{
+	Name:      "replay",
+	Usage:     "Send the stuck sector back to an earlier stage of the sealing pipeline",
+	ArgsUsage: "[<miner actor id>] <sector number> <stage>",
+	Description: fmt.Sprintf(
+		"The states produced in & after the stage are cleared, so that the worker redoes the stage.\n"+
+			"Available stages: %v",
+		core.SectorReplayStages,
+	),
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "pass this flag if you know what you are doing",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 2)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		if !cctx.Bool("really-do-it") {
+			fmt.Println("If you know what you're doing, Pass --really-do-it to actually execute this action")
+			return nil
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		sectorNum, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		stage := core.SectorReplayStage(args[1])
+		err = cli.Damocles.ReplaySectorStage(gctx, abi.SectorID{
+			Miner:  miner,
+			Number: sectorNum,
+		}, stage)
+		if err != nil {
+			return RPCCallError("ReplaySectorStage", err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "sector %d has been sent back to stage %s\n", sectorNum, stage)
+		return nil
+	},
+}
+
 var utilSealerSectorsRestoreCmd = &cli.Command{
 	Name:  "restore",
 	Usage: "Restore a sector state that may already finalized or aborted",
@@ -298,19 +483,24 @@ var utilSealerSectorsRestoreCmd = &cli.Command{
 			Hidden: true,
 			Value:  false,
 		},
+		&cli.StringFlag{
+			Name:  "target-store",
+			Usage: "the store instance holding the sector files now, replaces the one in the sector index",
+		},
 	},
-	ArgsUsage: "<miner actor id> <sector number>",
+	ArgsUsage: "[<miner actor id>] <sector number>",
 	Action: func(cctx *cli.Context) error {
-		if count := cctx.Args().Len(); count < 2 {
-			return fmt.Errorf("both miner actor id & sector number are required, only %d args provided", count)
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return IncorrectNumArgs(cctx)
 		}
 
-		miner, err := ShouldActor(cctx.Args().Get(0), true)
+		miner, err := ShouldActor(minerArg, true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
 
-		sectorNum, err := strconv.ParseUint(cctx.Args().Get(1), 10, 64)
+		sectorNum, err := strconv.ParseUint(args[0], 10, 64)
 		if err != nil {
 			return fmt.Errorf("invalid sector number: %w", err)
 		}
@@ -322,10 +512,13 @@ var utilSealerSectorsRestoreCmd = &cli.Command{
 
 		defer stop()
 
-		_, err = cli.Damocles.RestoreSector(gctx, abi.SectorID{
+		_, err = cli.Damocles.RestoreSectorEx(gctx, abi.SectorID{
 			Miner:  miner,
 			Number: abi.SectorNumber(sectorNum),
-		}, cctx.Bool("force"))
+		}, core.RestoreSectorOptions{
+			Forced:      cctx.Bool("force"),
+			TargetStore: cctx.String("target-store"),
+		})
 		if err != nil {
 			return fmt.Errorf("restore sector failed: %w", err)
 		}
@@ -334,6 +527,56 @@ var utilSealerSectorsRestoreCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsRestoreAbortedCmd = &cli.Command{
+	Name:  "restore-aborted",
+	Usage: "Restore all of the aborted sectors of the miner, the ones with deals are skipped unless forced",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:   "force",
+			Hidden: true,
+			Value:  false,
+		},
+	},
+	ArgsUsage: "[<miner actor id>]",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 0)
+		if !ok || len(args) != 0 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		results, err := cli.Damocles.RestoreAborted(gctx, miner, cctx.Bool("force"))
+		if err != nil {
+			return RPCCallError("RestoreAborted", err)
+		}
+
+		failed := 0
+		for _, res := range results {
+			if res.Err != "" {
+				failed++
+				_, _ = fmt.Fprintf(os.Stdout, "%s: %s\n", util.FormatSectorID(res.ID), color.RedString(res.Err))
+				continue
+			}
+
+			_, _ = fmt.Fprintf(os.Stdout, "%s: restored\n", util.FormatSectorID(res.ID))
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "%d restored, %d failed\n", len(results)-failed, failed)
+		return nil
+	},
+}
+
 var utilSealerSectorsCheckExpireCmd = &cli.Command{
 	Name:  "check-expire",
 	Usage: "Inspect expiring sectors",
@@ -348,7 +591,7 @@ var utilSealerSectorsCheckExpireCmd = &cli.Command{
 		},
 	},
 	Action: func(cctx *cli.Context) error {
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -382,38 +625,371 @@ var utilSealerSectorsCheckExpireCmd = &cli.Command{
 				n++
 			}
 		}
-		sectors = sectors[:n]
+		sectors = sectors[:n]
+
+		sort.Slice(sectors, func(i, j int) bool {
+			if sectors[i].Expiration == sectors[j].Expiration {
+				return sectors[i].SectorNumber < sectors[j].SectorNumber
+			}
+			return sectors[i].Expiration < sectors[j].Expiration
+		})
+
+		blockDelaySecs := policy.NetParams.BlockDelaySecs
+		_, _ = fmt.Fprintf(os.Stdout, "Sectors(%d):\n", len(sectors))
+		for _, sector := range sectors {
+			maxExpiration := sector.Activation + specpolicy.GetSectorMaxLifetime(sector.SealProof, nv)
+			maxExpirationExtension, err := specpolicy.GetMaxSectorExpirationExtension(nv)
+			if err != nil {
+				return err
+			}
+			maxExtendNow := currEpoch + maxExpirationExtension
+
+			if maxExtendNow > maxExpiration {
+				maxExtendNow = maxExpiration
+			}
+
+			_, _ = fmt.Fprintf(os.Stdout, "\tID: %d\n", sector.SectorNumber)
+			_, _ = fmt.Fprintf(os.Stdout, "\tSealProof: %d\n", sector.SealProof)
+			_, _ = fmt.Fprintf(os.Stdout, "\tInitialPledge: %v\n", FormatFIL(sector.InitialPledge, 4, true))
+			_, _ = fmt.Fprintf(os.Stdout, "\tActivation: %s\n", EpochTime(currEpoch, sector.Activation, blockDelaySecs))
+			_, _ = fmt.Fprintf(os.Stdout, "\tExpiration: %s\n", EpochTime(currEpoch, sector.Expiration, blockDelaySecs))
+			_, _ = fmt.Fprintf(os.Stdout, "\tMaxExpiration: %s\n", EpochTime(currEpoch, maxExpiration, blockDelaySecs))
+			_, _ = fmt.Fprintf(os.Stdout, "\tMaxExtendNow: %s\n", EpochTime(currEpoch, maxExtendNow, blockDelaySecs))
+
+			_, _ = fmt.Fprintln(os.Stdout, "")
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsExpiringCmd = &cli.Command{
+	Name:  "expiring",
+	Usage: "List local sectors which expire before the given epoch",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "miner",
+		},
+		&cli.Int64Flag{
+			Name:  "before",
+			Usage: "list sectors expiring before <before> epochs from now, defaults to 60 days",
+			Value: 172800,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		head, err := api.Chain.ChainHead(ctx)
+		if err != nil {
+			return err
+		}
+		currEpoch := head.Height()
+
+		sectors, err := api.Damocles.SectorsExpiringBefore(ctx, mid, currEpoch+abi.ChainEpoch(cctx.Int64("before")))
+		if err != nil {
+			return RPCCallError("SectorsExpiringBefore", err)
+		}
+
+		blockDelaySecs := policy.NetParams.BlockDelaySecs
+		_, _ = fmt.Fprintf(os.Stdout, "Sectors(%d):\n", len(sectors))
+		for _, sector := range sectors {
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"\t%s: %s\n",
+				util.FormatSectorID(sector.ID),
+				EpochTime(currEpoch, sector.Expiration, blockDelaySecs),
+			)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsSealedDealsCmd = &cli.Command{
+	Name:  "sealed-deals",
+	Usage: "List deals sealed in the local sectors of the given miner",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "miner",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		deals, err := api.Damocles.ListSealedDeals(ctx, mid)
+		if err != nil {
+			return RPCCallError("ListSealedDeals", err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Deals(%d):\n", len(deals))
+		for _, deal := range deals {
+			_, _ = fmt.Fprintf(os.Stdout, "\t%d: sector %d\n", deal.DealID, deal.Sector)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsSealStatsCmd = &cli.Command{
+	Name:  "seal-stats",
+	Usage: "Show the sealing duration statistics of the sectors finalized recently",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "miner",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only count the sectors finalized within the given duration",
+			Value: 7 * 24 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		stats, err := api.Damocles.SealDurationStats(ctx, mid, time.Now().Add(-cctx.Duration("since")))
+		if err != nil {
+			return RPCCallError("SealDurationStats", err)
+		}
+
+		printSummary := func(name string, summary core.SealDurationSummary) {
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"%s: count=%d, min=%s, median=%s, p95=%s, max=%s\n",
+				name,
+				summary.Count,
+				summary.Min,
+				summary.Median,
+				summary.P95,
+				summary.Max,
+			)
+		}
+
+		printSummary("All", stats.SealDurationSummary)
+
+		proofTypes := make([]abi.RegisteredSealProof, 0, len(stats.ByProofType))
+		for proofType := range stats.ByProofType {
+			proofTypes = append(proofTypes, proofType)
+		}
+		sort.Slice(proofTypes, func(i, j int) bool {
+			return proofTypes[i] < proofTypes[j]
+		})
+
+		for _, proofType := range proofTypes {
+			printSummary(fmt.Sprintf("ProofType %d", proofType), stats.ByProofType[proofType])
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsDealStatsCmd = &cli.Command{
+	Name:  "deal-stats",
+	Usage: "Show the count and the size of the deals sealed in the sectors",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "miner",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		stats, err := api.Damocles.DealStats(ctx, mid)
+		if err != nil {
+			return RPCCallError("DealStats", err)
+		}
+
+		printEntry := func(name string, entry core.DealStatsEntry) {
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"%s: deals=%d, bytes=%s\n",
+				name,
+				entry.Deals,
+				units.BytesSize(float64(entry.Bytes)),
+			)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Sectors: %d\n", stats.Sectors)
+		printEntry("All", stats.DealStatsEntry)
+		printEntry("Verified", stats.Verified)
+		printEntry("Unverified", stats.Unverified)
+		return nil
+	},
+}
+
+var utilSealerSectorsStuckCmd = &cli.Command{
+	Name:  "stuck",
+	Usage: "List the sealing sectors staying in the same state for too long",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "older-than",
+			Usage: "the duration a sector has stayed in the current state",
+			Value: 6 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		stuck, err := api.Damocles.StuckSectors(ctx, cctx.Duration("older-than"))
+		if err != nil {
+			return RPCCallError("StuckSectors", err)
+		}
+
+		orNull := func(v string) string {
+			if v == "" {
+				return "NULL"
+			}
+			return v
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+		_, _ = fmt.Fprintln(tw, "Sector\tState\tWorker\tSince\tStuckFor")
+		for _, s := range stuck {
+			_, _ = fmt.Fprintf(
+				tw,
+				"%s\t%s\t%s\t%s\t%s\n",
+				util.FormatSectorID(s.ID),
+				orNull(s.State),
+				orNull(s.Worker),
+				time.Unix(s.EnteredAt, 0).Format(time.RFC3339),
+				s.StuckFor.Truncate(time.Second),
+			)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsStateMachineCmd = &cli.Command{
+	Name:  "state-machine",
+	Usage: "Export the transitions of the sector worker states in graphviz dot format",
+	Action: func(_ *cli.Context) error {
+		return writeSectorStateMachineDot(os.Stdout)
+	},
+}
+
+func writeSectorStateMachineDot(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph sector_states {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tinit [shape=point];\n")
+	fmt.Fprintf(&b, "\t%q [shape=box, label=\"%s\\njobs: %s\"];\n", core.WorkerOnline, core.WorkerOnline,
+		"sealing, snapup, rebuild, unseal")
+	fmt.Fprintf(&b, "\t%q [shape=box];\n", core.WorkerOffline)
+
+	for _, trans := range core.SectorWorkerStateTransitions {
+		from := "init"
+		if trans.From != "" {
+			from = strconv.Quote(string(trans.From))
+		}
+
+		fmt.Fprintf(&b, "\t%s -> %q [label=\"%s\\n(%s)\"];\n", from, trans.To, trans.Name, strings.Join(trans.Via, ", "))
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var utilSealerSectorsReconcileCmd = &cli.Command{
+	Name:      "reconcile",
+	Usage:     "Compare the local sector states of the miner with its on-chain sectors",
+	ArgsUsage: "[<miner actor>]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "detail",
+			Usage: "show the sector numbers out of sync",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 0)
+		if !ok || len(args) != 0 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		mid, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		res, err := api.Damocles.ReconcileMinerSectors(ctx, mid)
+		if err != nil {
+			return RPCCallError("ReconcileMinerSectors", err)
+		}
 
-		sort.Slice(sectors, func(i, j int) bool {
-			if sectors[i].Expiration == sectors[j].Expiration {
-				return sectors[i].SectorNumber < sectors[j].SectorNumber
-			}
-			return sectors[i].Expiration < sectors[j].Expiration
-		})
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
 
-		blockDelaySecs := policy.NetParams.BlockDelaySecs
-		_, _ = fmt.Fprintf(os.Stdout, "Sectors(%d):\n", len(sectors))
-		for _, sector := range sectors {
-			maxExpiration := sector.Activation + specpolicy.GetSectorMaxLifetime(sector.SealProof, nv)
-			maxExpirationExtension, err := specpolicy.GetMaxSectorExpirationExtension(nv)
+		showDetail := cctx.Bool("detail")
+		for _, part := range []struct {
+			title  string
+			bits   bitfield.BitField
+			detail bool
+		}{
+			{"on-chain only", res.OnChainOnly, showDetail},
+			{"local only", res.LocalOnly, showDetail},
+			{"both", res.Both, false},
+		} {
+			nums, err := part.bits.All(math.MaxUint64)
 			if err != nil {
-				return err
+				return fmt.Errorf("get %s sectors: %w", part.title, err)
 			}
-			maxExtendNow := currEpoch + maxExpirationExtension
 
-			if maxExtendNow > maxExpiration {
-				maxExtendNow = maxExpiration
+			_, _ = fmt.Fprintf(os.Stdout, "%s: %d\n", part.title, len(nums))
+			if part.detail && len(nums) > 0 {
+				_, _ = fmt.Fprintf(os.Stdout, "\t%v\n", nums)
 			}
-
-			_, _ = fmt.Fprintf(os.Stdout, "\tID: %d\n", sector.SectorNumber)
-			_, _ = fmt.Fprintf(os.Stdout, "\tSealProof: %d\n", sector.SealProof)
-			_, _ = fmt.Fprintf(os.Stdout, "\tInitialPledge: %v\n", types.FIL(sector.InitialPledge).Short())
-			_, _ = fmt.Fprintf(os.Stdout, "\tActivation: %s\n", EpochTime(currEpoch, sector.Activation, blockDelaySecs))
-			_, _ = fmt.Fprintf(os.Stdout, "\tExpiration: %s\n", EpochTime(currEpoch, sector.Expiration, blockDelaySecs))
-			_, _ = fmt.Fprintf(os.Stdout, "\tMaxExpiration: %s\n", EpochTime(currEpoch, maxExpiration, blockDelaySecs))
-			_, _ = fmt.Fprintf(os.Stdout, "\tMaxExtendNow: %s\n", EpochTime(currEpoch, maxExtendNow, blockDelaySecs))
-
-			_, _ = fmt.Fprintln(os.Stdout, "")
 		}
 
 		return nil
@@ -448,7 +1024,7 @@ var utilSealerSectorsExpiredCmd = &cli.Command{
 		},
 	},
 	Action: func(cctx *cli.Context) error {
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -471,7 +1047,7 @@ var utilSealerSectorsExpiredCmd = &cli.Command{
 				return fmt.Errorf("getting network version: %w", err)
 			}
 
-			lbEpoch = head.Height() - specpolicy.GetWinningPoStSectorSetLookback(nv)
+			lbEpoch = head.Height() - policy.GetWinningPoStSectorSetLookback(nv)
 			if lbEpoch < 0 {
 				return fmt.Errorf("too early to terminate sectors")
 			}
@@ -803,7 +1379,7 @@ var utilSealerSectorsExtendCmd = &cli.Command{
 		}
 		spec := &messager.MsgMeta{MaxFee: abi.TokenAmount(mf)}
 
-		maddr, err := ShouldAddress(cctx.String("miner"), true, true)
+		maddr, err := ShouldAddress(minerOrDefault(cctx), true, true)
 		if err != nil {
 			return err
 		}
@@ -1210,6 +1786,9 @@ var utilSealerSectorsTerminateCmd = &cli.Command{
 	},
 	Subcommands: []*cli.Command{
 		utilSealerSectorsTerminateQueryCmd,
+		utilSealerSectorsTerminatePendingCmd,
+		utilSealerSectorsTerminateCancelCmd,
+		utilSealerSectorsTerminateEstimateCmd,
 	},
 	Action: func(cctx *cli.Context) error {
 		if !cctx.Bool("really-do-it") {
@@ -1287,6 +1866,284 @@ var utilSealerSectorsTerminateQueryCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsTerminatePendingCmd = &cli.Command{
+	Name:  "pending",
+	Usage: "List the terminations of the actor not landed on chain yet",
+	Action: func(cctx *cli.Context) error {
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		pendings, err := cli.Damocles.ListPendingTerminations(gctx, abi.ActorID(cctx.Uint64("actor")))
+		if err != nil {
+			return RPCCallError("ListPendingTerminations", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+
+		_, _ = fmt.Fprintln(tw, "Sector\tAddedHeight\tMessage\tState")
+		for _, p := range pendings {
+			msg, state := "batching", "-"
+			if p.TerminateCid != nil {
+				msg, state = p.TerminateCid.String(), p.MessageState
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", util.FormatSectorID(p.ID), p.AddedHeight, msg, state)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsTerminateCancelCmd = &cli.Command{
+	Name:      "cancel",
+	Usage:     "Cancel the termination of the specified sector, before its message is pushed into the mpool",
+	ArgsUsage: "<sectorNum>",
+	Action: func(cctx *cli.Context) error {
+		num, err := ShouldSectorNumber(cctx.Args().First())
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		sid := abi.SectorID{Miner: abi.ActorID(cctx.Uint64("actor")), Number: num}
+		if err := cli.Damocles.CancelTermination(gctx, sid); err != nil {
+			return RPCCallError("CancelTermination", err)
+		}
+
+		fmt.Printf("termination of %s cancelled\n", util.FormatSectorID(sid))
+		return nil
+	},
+}
+
+var utilSealerSectorsTerminateEstimateCmd = &cli.Command{
+	Name:      "estimate",
+	Usage:     "Estimate the gas of the terminate messages which would be submitted for the sectors, without sending",
+	ArgsUsage: "<sectorNum> ...",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() == 0 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		mid := abi.ActorID(cctx.Uint64("actor"))
+		sids := make([]abi.SectorID, 0, cctx.Args().Len())
+		for _, arg := range cctx.Args().Slice() {
+			num, err := ShouldSectorNumber(arg)
+			if err != nil {
+				return fmt.Errorf("invalid sector number %q: %w", arg, err)
+			}
+
+			sids = append(sids, abi.SectorID{Miner: mid, Number: num})
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		estimates, err := cli.Damocles.EstimateTerminateGas(gctx, sids)
+		if err != nil {
+			return RPCCallError("EstimateTerminateGas", err)
+		}
+
+		if len(estimates) == 0 {
+			fmt.Println("no terminate message would be submitted")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+
+		total, sectors := big.Zero(), 0
+		_, _ = fmt.Fprintln(tw, "#\tBatched\tSectors\tGasLimit\tGasFeeCap\tGasPremium\tMaxFee")
+		for i, est := range estimates {
+			total = big.Add(total, est.MaxFee)
+			sectors += len(est.Sectors)
+			_, _ = fmt.Fprintf(
+				tw,
+				"%d\t%v\t%d\t%d\t%s\t%s\t%s\n",
+				i,
+				est.Batched,
+				len(est.Sectors),
+				est.GasLimit,
+				est.GasFeeCap,
+				est.GasPremium,
+				FormatFIL(est.MaxFee, 6, true),
+			)
+		}
+
+		_, _ = fmt.Fprintf(tw, "total\t\t%d\t\t\t\t%s\n", sectors, FormatFIL(total, 6, true))
+		return nil
+	},
+}
+
+var utilSealerSectorsPiecesCmd = &cli.Command{
+	Name:      "pieces",
+	Usage:     "Print the pieces of the sector along with their offsets & sizes",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		pieces, err := cli.Damocles.SectorPieces(gctx, abi.SectorID{Miner: miner, Number: num})
+		if err != nil {
+			return RPCCallError("SectorPieces", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+		_, _ = fmt.Fprintln(tw, "#\tPiece\tOffset\tSize\tDeal")
+		for i, p := range pieces {
+			deal := "padding"
+			if p.IsDeal {
+				deal = p.Deal
+			}
+
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\n", i, p.Cid, p.Offset, p.Size, deal)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsPathsCmd = &cli.Command{
+	Name:      "paths",
+	Usage:     "Print the locations of the sealed file & cache dir of the sector",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		paths, err := cli.Damocles.SectorPaths(gctx, abi.SectorID{Miner: miner, Number: num})
+		if err != nil {
+			return RPCCallError("SectorPaths", err)
+		}
+
+		printPath := func(name string, p *core.SectorFilePath) {
+			if p == nil {
+				fmt.Printf("%s:\tnot indexed\n", name)
+				return
+			}
+
+			fmt.Printf("%s:\t%s (%s)\n", name, p.Path, p.Instance)
+		}
+
+		printPath("Sealed", paths.Sealed)
+		printPath("Cache", paths.Cache)
+		if paths.Upgraded {
+			printPath("Update", paths.Update)
+			printPath("UpdateCache", paths.UpdateCache)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsCanRemoveCmd = &cli.Command{
+	Name:      "can-remove",
+	Usage:     "Check if the persist stores of sector can be removed now",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		res, err := cli.Damocles.CanRemoveSector(gctx, abi.SectorID{Miner: miner, Number: num})
+		if err != nil {
+			return RPCCallError("CanRemoveSector", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
+
+		switch {
+		case res.Removed:
+			fmt.Println("already removed")
+		case res.Allowed:
+			fmt.Println("allowed")
+		default:
+			fmt.Printf("not allowed until epoch %d, current epoch %d\n", res.AllowedAt, res.Head)
+		}
+
+		return nil
+	},
+}
+
 var utilSealerSectorsRemoveCmd = &cli.Command{
 	Name:      "remove",
 	Usage:     "Forcefully remove persist stores of sector(WARNING: This means losing power and collateral for the removed sector (use 'terminate' for lower penalty))", //revive:disable-line:line-length-limit
@@ -1314,18 +2171,147 @@ var utilSealerSectorsRemoveCmd = &cli.Command{
 
 		defer stop()
 
-		id, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
-		if err != nil {
-			return fmt.Errorf("could not parse sector number: %w", err)
+		id, err := strconv.ParseUint(cctx.Args().Get(0), 10, 64)
+		if err != nil {
+			return fmt.Errorf("could not parse sector number: %w", err)
+		}
+
+		actor := cctx.Uint64("actor")
+		err = cli.Damocles.RemoveSector(gctx, abi.SectorID{Miner: abi.ActorID(actor), Number: abi.SectorNumber(id)})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("remove succeed")
+		return nil
+	},
+}
+
+var utilSealerSectorsVerifySealedSizeCmd = &cli.Command{
+	Name:  "verify-sealed-size",
+	Usage: "Check if the sizes of the local sealed files match the sector size",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "miner",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		mid, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return err
+		}
+
+		api, ctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+
+		mismatches, err := api.Damocles.VerifySealedFileSizes(ctx, mid)
+		if err != nil {
+			return RPCCallError("VerifySealedFileSizes", err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Mismatches(%d):\n", len(mismatches))
+		for _, m := range mismatches {
+			if m.Err != "" {
+				_, _ = fmt.Fprintf(os.Stdout, "\t%s: %s @ %s: %s\n", util.FormatSectorID(m.ID), m.Path, m.Instance, m.Err)
+				continue
+			}
+
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"\t%s: %s @ %s: got %d bytes, expect %d\n",
+				util.FormatSectorID(m.ID),
+				m.Path,
+				m.Instance,
+				m.Actual,
+				m.Expected,
+			)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsCheckCacheCmd = &cli.Command{
+	Name:  "check-cache",
+	Usage: "Check the cache files of the sector whose sealed file is intact",
+	Description: "The missing files are only reported by default, with --repair, the sector is set for rebuild\n" +
+		"to regenerate them, follow it with 'util sealer sectors rebuild-progress'.",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "repair",
+			Usage: "set the sector for rebuild to regenerate the missing cache files",
+		},
+		&cli.BoolFlag{
+			Name:  "pieces-available",
+			Usage: "if all pieces are available in venus-market, this flag is used for imported sectors",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		sid := abi.SectorID{Miner: miner, Number: num}
+		if cctx.Bool("repair") {
+			res, err := cli.Damocles.RepairSectorCache(gctx, sid, core.RebuildOptions{
+				PiecesAvailable: cctx.Bool("pieces-available"),
+			})
+			if err != nil {
+				return RPCCallError("RepairSectorCache", err)
+			}
+
+			if len(res.Regenerating) == 0 {
+				fmt.Println("cache files are intact")
+				return nil
+			}
+
+			fmt.Println("Regenerating:")
+			for _, p := range res.Regenerating {
+				fmt.Printf("\t%s\n", p)
+			}
+
+			fmt.Println("\nthe sector has been set for rebuild, follow it with 'sectors rebuild-progress'")
+			return nil
 		}
 
-		actor := cctx.Uint64("actor")
-		err = cli.Damocles.RemoveSector(gctx, abi.SectorID{Miner: abi.ActorID(actor), Number: abi.SectorNumber(id)})
+		res, err := cli.Damocles.CheckSectorCache(gctx, sid)
 		if err != nil {
-			return err
+			return RPCCallError("CheckSectorCache", err)
 		}
 
-		fmt.Println("remove succeed")
+		if len(res.Missing) == 0 {
+			fmt.Println("cache files are intact")
+			return nil
+		}
+
+		fmt.Println("Missing:")
+		for _, p := range res.Missing {
+			fmt.Printf("\t%s\n", p)
+		}
+
+		fmt.Println("\nrun with --repair to regenerate the missing cache files")
 		return nil
 	},
 }
@@ -1336,10 +2322,9 @@ var utilSealerSectorsFinalizeCmd = &cli.Command{
 	ArgsUsage: "<sectorNum>",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     "miner",
-			Required: true,
-			Aliases:  []string{"actor"},
-			Usage:    "miner id",
+			Name:    "miner",
+			Aliases: []string{"actor"},
+			Usage:   "miner id, the default miner will be used if omitted",
 		},
 		&cli.BoolFlag{
 			Name:  "really-do-it",
@@ -1351,7 +2336,7 @@ var utilSealerSectorsFinalizeCmd = &cli.Command{
 			return fmt.Errorf("pass --really-do-it to confirm this action")
 		}
 
-		minerID, err := ShouldActor(cctx.String("miner"), true)
+		minerID, err := ShouldActor(minerOrDefault(cctx), true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
@@ -1631,9 +2616,14 @@ var utilSealerSectorsImportCmd = &cli.Command{
 			Name:  "numbers",
 			Usage: "import the specified sector numbers only if this flag is set",
 		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "validate the sector infos and report the problems, without importing them",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		override := cctx.Bool("override")
+		dryRun := cctx.Bool("dry-run")
 
 		cli, gctx, stop, err := extractAPI(cctx)
 		if err != nil {
@@ -1693,6 +2683,18 @@ var utilSealerSectorsImportCmd = &cli.Command{
 				continue
 			}
 
+			if dryRun {
+				if errs := state.Validate(); len(errs) > 0 {
+					for _, verr := range errs {
+						slog.Warnf("invalid: %s", verr)
+					}
+					continue
+				}
+
+				slog.Info("valid")
+				continue
+			}
+
 			imported, err := cli.Damocles.ImportSector(gctx, core.WorkerOffline, state, override)
 			if err != nil {
 				slog.Errorf("import failed: %s", err)
@@ -1711,13 +2713,70 @@ var utilSealerSectorsImportCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsImportDirCmd = &cli.Command{
+	Name:      "import-dir",
+	Usage:     "Import the sectors of the miner from the sector files in the dir, e.g. migrated from another system",
+	ArgsUsage: "<dir>",
+	Description: "The chain doesn't keep the tickets of the proven sectors, provide them in the sidecar files\n" +
+		"`<dir>/meta/<sector id>.json`, e.g. {\"Ticket\": {\"Epoch\": 1000}}, the ticket is rederived from the chain\n" +
+		"if only the epoch is given.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "miner",
+			Usage: "miner id, the default miner will be used if omitted",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerOrDefault(cctx), true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		res, err := cli.Damocles.ImportSectorsFromDir(gctx, miner, cctx.Args().First())
+		if err != nil {
+			return RPCCallError("ImportSectorsFromDir", err)
+		}
+
+		if res.Instance != "" {
+			fmt.Printf("dir located at store instance %s, the index of the imported sectors points to it\n", res.Instance)
+		} else {
+			fmt.Println("dir not located at any store instance, move the files into one and reindex it")
+		}
+
+		imported := 0
+		for _, item := range res.Sectors {
+			if item.Imported {
+				imported++
+				fmt.Printf("%s: imported\n", util.FormatSectorID(item.ID))
+				continue
+			}
+
+			fmt.Printf("%s: %s\n", util.FormatSectorID(item.ID), color.RedString(strings.Join(item.Problems, "; ")))
+		}
+
+		fmt.Printf("%d found, %d imported\n", len(res.Sectors), imported)
+		return nil
+	},
+}
+
 var utilSealerSectorsExportToLotusCmd = &cli.Command{
 	Name:  "export-to-lotus",
 	Usage: "Commands for export sector infos to the given lotus-miner instance",
 	Flags: []cli.Flag{
 		&cli.StringFlag{
-			Name:     "miner",
-			Required: true,
+			Name:  "miner",
+			Usage: "miner id, the default miner will be used if omitted",
 		},
 	},
 	Subcommands: []*cli.Command{
@@ -1763,7 +2822,7 @@ var utilSealerSectorsExportMetadataCmd = &cli.Command{
 		ctx := cctx.Context
 
 		if !onlyNextSid {
-			minerID, err := ShouldActor(cctx.String("miner"), true)
+			minerID, err := ShouldActor(minerOrDefault(cctx), true)
 			if err != nil {
 				return fmt.Errorf("invalid miner actor id: %w", err)
 			}
@@ -1867,7 +2926,7 @@ var utilSealerSectorsExportFilesCmd = &cli.Command{
 
 		ctx := cctx.Context
 
-		minerID, err := ShouldActor(cctx.String("miner"), true)
+		minerID, err := ShouldActor(minerOrDefault(cctx), true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
@@ -2311,7 +3370,7 @@ func sectorState2SectorInfo(
 var utilSealerSectorsRebuildCmd = &cli.Command{
 	Name:      "rebuild",
 	Usage:     "Rebuild specified sector",
-	ArgsUsage: "<miner actor> <sector number>",
+	ArgsUsage: "[<miner actor>] <sector number>",
 	Flags: []cli.Flag{
 		&cli.BoolFlag{
 			Name:  "pieces-available",
@@ -2320,16 +3379,17 @@ var utilSealerSectorsRebuildCmd = &cli.Command{
 		},
 	},
 	Action: func(cctx *cli.Context) error {
-		if count := cctx.Args().Len(); count < 2 {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
 			return cli.ShowSubcommandHelp(cctx)
 		}
 
-		miner, err := ShouldActor(cctx.Args().Get(0), true)
+		miner, err := ShouldActor(minerArg, true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
 
-		sectorNum, err := strconv.ParseUint(cctx.Args().Get(1), 10, 64)
+		sectorNum, err := strconv.ParseUint(args[0], 10, 64)
 		if err != nil {
 			return fmt.Errorf("invalid sector number: %w", err)
 		}
@@ -2355,6 +3415,283 @@ var utilSealerSectorsRebuildCmd = &cli.Command{
 	},
 }
 
+var utilSealerSectorsRebuildListCmd = &cli.Command{
+	Name:  "rebuild-list",
+	Usage: "List sectors which are marked for rebuild",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "miner",
+			Usage: "only list sectors of the specified miner",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		var mid *abi.ActorID
+		if cctx.IsSet("miner") {
+			miner, err := ShouldActor(cctx.String("miner"), true)
+			if err != nil {
+				return fmt.Errorf("invalid miner actor id: %w", err)
+			}
+
+			mid = &miner
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		sectors, err := cli.Damocles.ListRebuildSectors(gctx, mid)
+		if err != nil {
+			return RPCCallError("ListRebuildSectors", err)
+		}
+
+		pending := 0
+		for _, sector := range sectors {
+			status := "in progress"
+			if sector.Info != nil {
+				status = "pending"
+				pending++
+			}
+
+			_, _ = fmt.Fprintf(os.Stdout, "%s: %s\n", util.FormatSectorID(sector.ID), status)
+		}
+
+		_, _ = fmt.Fprintf(
+			os.Stdout,
+			"\nTotal: %d, Pending: %d, In Progress: %d\n",
+			len(sectors),
+			pending,
+			len(sectors)-pending,
+		)
+		return nil
+	},
+}
+
+var utilSealerSectorsValidateUpgradeCmd = &cli.Command{
+	Name:      "validate-upgrade",
+	Usage:     "Check the upgrade info of the snapup sector against the sector info on chain",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		sectorNum, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		res, err := cli.Damocles.ValidateUpgradeInfo(gctx, abi.SectorID{
+			Miner:  miner,
+			Number: sectorNum,
+		})
+		if err != nil {
+			return RPCCallError("ValidateUpgradeInfo", err)
+		}
+
+		if len(res.Mismatches) == 0 {
+			_, _ = fmt.Fprintf(os.Stdout, "%s: consistent\n", util.FormatSectorID(res.ID))
+			return nil
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "%s: %s\n", util.FormatSectorID(res.ID), color.RedString("inconsistent"))
+		for _, m := range res.Mismatches {
+			_, _ = fmt.Fprintf(os.Stdout, "\t%s\n", m)
+		}
+
+		return nil
+	},
+}
+
+var utilSealerSectorsRebuildProgressCmd = &cli.Command{
+	Name:      "rebuild-progress",
+	Usage:     "Show the progress of the sector being rebuilt",
+	ArgsUsage: "[<miner actor>] <sector number>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		sectorNum, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		progress, err := cli.Damocles.RebuildProgress(gctx, abi.SectorID{
+			Miner:  miner,
+			Number: sectorNum,
+		})
+		if err != nil {
+			return RPCCallError("RebuildProgress", err)
+		}
+
+		state := progress.State
+		if state == "" {
+			state = "-"
+		}
+
+		_, _ = fmt.Fprintf(
+			os.Stdout,
+			"%s: %s (%d%%), latest state: %s\n",
+			util.FormatSectorID(progress.ID),
+			progress.Phase,
+			progress.Percent,
+			state,
+		)
+		return nil
+	},
+}
+
+var utilSealerSectorsLabelCmd = &cli.Command{
+	Name:      "label",
+	Usage:     "Set the labels of the sector, a label with empty value will be removed, eg. batch=2024-01 node=",
+	ArgsUsage: "[<miner actor>] <sector number> <key=value>...",
+	Action: func(cctx *cli.Context) error {
+		labelArgs := 0
+		for _, arg := range cctx.Args().Slice() {
+			if strings.Contains(arg, "=") {
+				labelArgs++
+			}
+		}
+
+		minerArg, args, ok := minerArgOrDefault(cctx, 1+labelArgs)
+		if !ok || labelArgs == 0 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		labels, err := parseSectorLabels(args[1:])
+		if err != nil {
+			return err
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		merged, err := cli.Damocles.SetSectorLabels(gctx, abi.SectorID{Miner: miner, Number: num}, labels)
+		if err != nil {
+			return RPCCallError("SetSectorLabels", err)
+		}
+
+		keys := make([]string, 0, len(merged))
+		for k := range merged {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			_, _ = fmt.Fprintf(os.Stdout, "%s=%s\n", k, merged[k])
+		}
+
+		return nil
+	},
+}
+
+// parseSectorLabels parses the labels in the format of key=value.
+func parseSectorLabels(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", arg)
+		}
+
+		labels[k] = v
+	}
+
+	return labels, nil
+}
+
+var utilSealerSectorsRederiveTicketCmd = &cli.Command{
+	Name:      "rederive-ticket",
+	Usage:     "Recompute the lost ticket of the sector at the given epoch, so that it can be rebuilt",
+	ArgsUsage: "[<miner actor>] <sector number> <ticket epoch>",
+	Action: func(cctx *cli.Context) error {
+		minerArg, args, ok := minerArgOrDefault(cctx, 2)
+		if !ok {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		miner, err := ShouldActor(minerArg, true)
+		if err != nil {
+			return fmt.Errorf("invalid miner actor id: %w", err)
+		}
+
+		sectorNum, err := ShouldSectorNumber(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid sector number: %w", err)
+		}
+
+		epoch, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid ticket epoch: %w", err)
+		}
+
+		cli, gctx, stop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+
+		defer stop()
+
+		ticket, err := cli.Damocles.RederiveTicket(gctx, abi.SectorID{
+			Miner:  miner,
+			Number: sectorNum,
+		}, abi.ChainEpoch(epoch))
+		if err != nil {
+			return RPCCallError("RederiveTicket", err)
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "Ticket: %x, Epoch: %d\n", ticket.Ticket, ticket.Epoch)
+		return nil
+	},
+}
+
 var utilSealerSectorsUnsealCmd = &cli.Command{
 	Name:      "unseal",
 	Usage:     "unseal specified sector",
@@ -2630,18 +3967,19 @@ var utilSealerSectorsUnsealCmd = &cli.Command{
 var utilSealerSectorsStateExportJSONCmd = &cli.Command{
 	Name:      "export-json",
 	Usage:     "Export sector state in JSON format",
-	ArgsUsage: "<miner actor> <sector number>",
+	ArgsUsage: "[<miner actor>] <sector number>",
 	Action: func(cctx *cli.Context) error {
-		if count := cctx.Args().Len(); count < 2 {
+		minerArg, args, ok := minerArgOrDefault(cctx, 1)
+		if !ok {
 			return cli.ShowSubcommandHelp(cctx)
 		}
 
-		miner, err := ShouldActor(cctx.Args().Get(0), true)
+		miner, err := ShouldActor(minerArg, true)
 		if err != nil {
 			return fmt.Errorf("invalid miner actor id: %w", err)
 		}
 
-		sectorNum, err := strconv.ParseUint(cctx.Args().Get(1), 10, 64)
+		sectorNum, err := strconv.ParseUint(args[0], 10, 64)
 		if err != nil {
 			return fmt.Errorf("invalid sector number: %w", err)
 		}
@@ -2706,6 +4044,12 @@ var utilSealerSectorsStateImportJSONCmd = &cli.Command{
 
 		if !cctx.Bool("really-do-it") {
 			showSectorState(state)
+			if errs := state.Validate(); len(errs) > 0 {
+				_, _ = fmt.Fprintln(os.Stdout, "\nValidation:")
+				for _, verr := range errs {
+					_, _ = fmt.Fprintf(os.Stdout, "\t%s\n", verr)
+				}
+			}
 			return nil
 		}
 
@@ -2770,6 +4114,29 @@ func showSectorState(state *core.SectorState) {
 		})
 	}))
 
+	// Retries
+	_, _ = fmt.Fprintln(os.Stdout, "\nRetries:")
+	if len(state.Retries) == 0 {
+		_, _ = fmt.Fprintln(os.Stdout, "\tNULL")
+	} else {
+		names := make([]string, 0, len(state.Retries))
+		for name := range state.Retries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			retry := state.Retries[name]
+			_, _ = fmt.Fprintf(
+				os.Stdout,
+				"\t%s: %d, last at %s\n",
+				name,
+				retry.Count,
+				time.Unix(retry.LastRetryAt, 0).Format(time.RFC3339),
+			)
+		}
+	}
+
 	// Deals
 	_, _ = fmt.Fprintln(os.Stdout, "\nDeals:")
 	if !state.HasData() {
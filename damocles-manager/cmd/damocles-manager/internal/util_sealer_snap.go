@@ -17,6 +17,8 @@ var utilSealerSnapCmd = &cli.Command{
 		utilSealerSnapFetchCmd,
 		utilSealerSnapCandidatesCmd,
 		utilSealerSnapCancelCommitmentCmd,
+		utilSealerSnapPauseCmd,
+		utilSealerSnapResumeCmd,
 	},
 }
 
@@ -59,6 +61,60 @@ var utilSealerSnapFetchCmd = &cli.Command{
 	},
 }
 
+var utilSealerSnapPauseCmd = &cli.Command{
+	Name:      "pause",
+	Usage:     "Stop fetching & allocating snap candidates for the specified miner",
+	ArgsUsage: "<miner actor id/addr>",
+	Action: func(cctx *cli.Context) error {
+		return snapUpSetPaused(cctx, true)
+	},
+}
+
+var utilSealerSnapResumeCmd = &cli.Command{
+	Name:      "resume",
+	Usage:     "Resume fetching & allocating snap candidates for the specified miner",
+	ArgsUsage: "<miner actor id/addr>",
+	Action: func(cctx *cli.Context) error {
+		return snapUpSetPaused(cctx, false)
+	},
+}
+
+func snapUpSetPaused(cctx *cli.Context, paused bool) error {
+	args := cctx.Args()
+	if args.Len() < 1 {
+		cli.ShowSubcommandHelpAndExit(cctx, 1)
+		return nil
+	}
+
+	mid, err := ShouldActor(args.Get(0), true)
+	if err != nil {
+		return fmt.Errorf("parse miner actor: %w", err)
+	}
+
+	api, gctx, stop, err := extractAPI(cctx)
+	if err != nil {
+		return fmt.Errorf("extract api: %w", err)
+	}
+
+	defer stop()
+
+	if paused {
+		if err := api.Damocles.SnapUpPause(gctx, mid); err != nil {
+			return RPCCallError("SnapUpPause", err)
+		}
+
+		Log.Infow("snapup paused", "miner", mid)
+		return nil
+	}
+
+	if err := api.Damocles.SnapUpResume(gctx, mid); err != nil {
+		return RPCCallError("SnapUpResume", err)
+	}
+
+	Log.Infow("snapup resumed", "miner", mid)
+	return nil
+}
+
 var utilSealerSnapCandidatesCmd = &cli.Command{
 	Name:  "candidates",
 	Usage: "Show fetched cc sectors for specified miner",
@@ -10,11 +10,13 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/docker/go-units"
 	"github.com/dtynn/dix"
+	"github.com/fatih/color"
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/venus/venus-shared/types"
@@ -28,6 +30,7 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore/filestore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/slices"
+	"github.com/ipfs/go-cid"
 	"github.com/urfave/cli/v2"
 )
 
@@ -39,7 +42,17 @@ var utilStorageCmd = &cli.Command{
 		utilStorageAttachCmd,
 		utilStorageFindCmd,
 		utilStorageListCmd,
+		utilStorageRefreshCmd,
+		utilStorageRebalanceCmd,
+		utilStorageSectorsCmd,
+		utilStorageReindexCmd,
+		utilStorageOrphansCmd,
+		utilStorageCapacityCmd,
 		utilStorageReleaseReservedCmd,
+		utilStorageReserveCmd,
+		utilStorageVerifyPieceCmd,
+		utilStorageLocalPiecesCmd,
+		utilStoragePieceBandwidthCmd,
 	},
 }
 
@@ -522,7 +535,16 @@ var utilStorageFindCmd = &cli.Command{
 var utilStorageListCmd = &cli.Command{
 	Name:  "list",
 	Usage: "List local storage paths and capacity",
-	Flags: []cli.Flag{},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sort",
+			Usage: "sort the storages by one of: used-percent, free, name",
+		},
+		&cli.BoolFlag{
+			Name:  "desc",
+			Usage: "sort in descending order",
+		},
+	},
 	Action: func(cctx *cli.Context) error {
 		api, actx, astop, err := extractAPI(cctx)
 		if err != nil {
@@ -530,9 +552,12 @@ var utilStorageListCmd = &cli.Command{
 		}
 		defer astop()
 
-		details, err := api.Damocles.StoreList(actx)
+		details, err := api.Damocles.StoreListEx(actx, core.StoreListOptions{
+			SortBy: core.StoreSortBy(cctx.String("sort")),
+			Desc:   cctx.Bool("desc"),
+		})
 		if err != nil {
-			return RPCCallError("StoreList", err)
+			return RPCCallError("StoreListEx", err)
 		}
 
 		if len(details) == 0 {
@@ -541,36 +566,322 @@ var utilStorageListCmd = &cli.Command{
 		}
 
 		for _, detail := range details {
-			fmt.Printf("%s:\n", detail.Name)
-			fmt.Printf("\tPath: %s\n", detail.Path)
-			fmt.Printf("\tType: %s\n", detail.Type)
-			fmt.Printf("\tReadOnly: %t\n", detail.ReadOnly)
-			fmt.Printf("\tWeight: %d\n", detail.Weight)
-			fmt.Printf("\tTotal: %s\n", units.BytesSize(float64(detail.Total)))
-			fmt.Printf("\tFree: %s\n", units.BytesSize(float64(detail.Free)))
-			fmt.Printf("\tUsed: %s\n", units.BytesSize(float64(detail.Used)))
-			fmt.Printf("\tUsedPercent: %.02f%%\n", detail.UsedPercent)
-			fmt.Printf("\tReserved: %s\n", units.BytesSize(float64(detail.Reserved)))
-			if len(detail.ReservedBy) > 0 {
-				fmt.Println("\tReserved Items:")
-				for i, res := range detail.ReservedBy {
-					fmt.Printf(
-						"\t\t#%d: %s, %s, %s ago\n",
-						i,
-						res.By,
-						units.BytesSize(float64(res.Size)),
-						time.Since(time.Unix(res.At, 0)),
-					)
-				}
+			printStoreDetail(detail)
+		}
+
+		return nil
+	},
+}
+
+var utilStorageRefreshCmd = &cli.Command{
+	Name:      "refresh",
+	Usage:     "Query the latest capacity of the specified storage",
+	ArgsUsage: "<storage name>",
+	Action: func(cctx *cli.Context) error {
+		name := cctx.Args().First()
+		if name == "" {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		detail, err := api.Damocles.StoreRefreshInfo(actx, name)
+		if err != nil {
+			return RPCCallError("StoreRefreshInfo", err)
+		}
+
+		printStoreDetail(*detail)
+		return nil
+	},
+}
+
+var utilStorageRebalanceCmd = &cli.Command{
+	Name:      "rebalance",
+	Usage:     "Move the files of finalized sectors from one storage to another",
+	ArgsUsage: "<from storage name> <to storage name>",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "max",
+			Usage: "max number of sectors to move",
+			Value: 10,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		args := cctx.Args()
+		if args.Len() < 2 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		res, err := api.Damocles.StoreRebalance(actx, args.Get(0), args.Get(1), cctx.Int("max"))
+		if err != nil {
+			return RPCCallError("StoreRebalance", err)
+		}
+
+		for _, sid := range res.Moved {
+			fmt.Printf("%s: moved\n", util.FormatSectorID(sid))
+		}
+
+		for _, failure := range res.Failed {
+			fmt.Printf("%s: failed: %s\n", util.FormatSectorID(failure.ID), failure.Err)
+		}
+
+		return nil
+	},
+}
+
+var utilStorageSectorsCmd = &cli.Command{
+	Name:      "sectors",
+	Usage:     "List the sectors having files on the storage, e.g. before decommissioning it",
+	ArgsUsage: "<storage name>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		args := cctx.Args()
+		if args.Len() < 1 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		res, err := api.Damocles.SectorsOnStore(actx, args.First())
+		if err != nil {
+			return RPCCallError("SectorsOnStore", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
+
+		for _, part := range []struct {
+			title string
+			sids  []abi.SectorID
+		}{
+			{"sealed", res.SealedFile},
+			{"cache", res.CacheDir},
+			{"update", res.UpdateFile},
+			{"update-cache", res.UpdateCache},
+		} {
+			for _, sid := range part.sids {
+				fmt.Printf("%s\t%s\n", util.FormatSectorID(sid), part.title)
 			}
+		}
+
+		return nil
+	},
+}
+
+var utilStorageReindexCmd = &cli.Command{
+	Name:      "reindex",
+	Usage:     "Scan the sector files on the storage, e.g. restored from backups, and rebuild their index entries",
+	ArgsUsage: "<storage name>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "only report the sector files found, without updating the index",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		res, err := api.Damocles.ReindexStore(actx, cctx.Args().First(), cctx.Bool("dry-run"))
+		if err != nil {
+			return RPCCallError("ReindexStore", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
+
+		for _, file := range res.Unrecognized {
+			fmt.Printf("unrecognized: %s\n", file)
+		}
+
+		for _, skipped := range res.Skipped {
+			fmt.Printf("skipped %s of %s: %s\n", skipped.Type, util.FormatSectorID(skipped.ID), skipped.Reason)
+		}
+
+		action := "indexed"
+		if res.DryRun {
+			action = "found (dry run)"
+		}
+
+		fmt.Printf(
+			"%s: sealed %d, cache %d, update %d, update-cache %d, skipped %d, unrecognized files %d\n",
+			action,
+			len(res.Found.SealedFile),
+			len(res.Found.CacheDir),
+			len(res.Found.UpdateFile),
+			len(res.Found.UpdateCache),
+			len(res.Skipped),
+			len(res.Unrecognized),
+		)
+
+		return nil
+	},
+}
+
+var utilStorageOrphansCmd = &cli.Command{
+	Name:      "orphans",
+	Usage:     "List the sector files on the storage not pointed to by the sector index, to reclaim the space",
+	ArgsUsage: "<storage name>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		res, err := api.Damocles.FindOrphanedFiles(actx, cctx.Args().First())
+		if err != nil {
+			return RPCCallError("FindOrphanedFiles", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Sector\tType\tCategory\tPath")
+		for _, f := range res.Files {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", util.FormatSectorID(f.ID), f.Type, f.Category, f.Path)
+		}
+
+		for _, file := range res.Unrecognized {
+			_, _ = fmt.Fprintf(tw, "-\t-\tunrecognized\t%s\n", file)
+		}
+
+		return tw.Flush()
+	},
+}
+
+var utilStorageCapacityCmd = &cli.Command{
+	Name:  "capacity",
+	Usage: "Show the capacity report of all the storages",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "sector-size",
+			Usage: "sector size used to project the number of sectors remaining",
+			Value: "32GiB",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		ssize, err := units.RAMInBytes(cctx.String("sector-size"))
+		if err != nil {
+			return fmt.Errorf("invalid sector size: %w", err)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
 
-			fmt.Println("")
+		report, err := api.Damocles.StoreCapacityReport(actx, abi.SectorSize(ssize))
+		if err != nil {
+			return RPCCallError("StoreCapacityReport", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Name\tReadOnly\tTotal\tFree\tReserved\tAvailable\tUsedPercent\tSectorsRemaining")
+		for _, store := range report.Stores {
+			_, _ = fmt.Fprintf(
+				tw,
+				"%s\t%t\t%s\t%s\t%s\t%s\t%.02f%%\t%d\n",
+				store.Name,
+				store.ReadOnly,
+				units.BytesSize(float64(store.Total)),
+				units.BytesSize(float64(store.Free)),
+				units.BytesSize(float64(store.Reserved)),
+				units.BytesSize(float64(store.Available)),
+				store.UsedPercent,
+				store.SectorsRemaining,
+			)
+		}
+
+		if err := tw.Flush(); err != nil {
+			return err
 		}
 
+		fmt.Printf("\nTotal: %s\n", units.BytesSize(float64(report.Total)))
+		fmt.Printf("Free: %s\n", units.BytesSize(float64(report.Free)))
+		fmt.Printf("Reserved: %s\n", units.BytesSize(float64(report.Reserved)))
+		fmt.Printf("Available: %s\n", units.BytesSize(float64(report.Available)))
+		fmt.Printf(
+			"Sectors remaining (%s): %d\n",
+			units.BytesSize(float64(report.SectorSize)),
+			report.SectorsRemaining,
+		)
 		return nil
 	},
 }
 
+func printStoreDetail(detail core.StoreDetailedInfo) {
+	fmt.Printf("%s:\n", detail.Name)
+	fmt.Printf("\tPath: %s\n", detail.Path)
+	fmt.Printf("\tType: %s\n", detail.Type)
+	fmt.Printf("\tReadOnly: %t\n", detail.ReadOnly)
+	fmt.Printf("\tWeight: %d\n", detail.Weight)
+	fmt.Printf("\tTotal: %s\n", units.BytesSize(float64(detail.Total)))
+	fmt.Printf("\tFree: %s\n", units.BytesSize(float64(detail.Free)))
+	fmt.Printf("\tUsed: %s\n", units.BytesSize(float64(detail.Used)))
+	fmt.Printf("\tUsedPercent: %.02f%%\n", detail.UsedPercent)
+	fmt.Printf("\tReserved: %s\n", units.BytesSize(float64(detail.Reserved)))
+	if len(detail.ReservedBy) > 0 {
+		fmt.Println("\tReserved Items:")
+		for i, res := range detail.ReservedBy {
+			fmt.Printf(
+				"\t\t#%d: %s, %s, %s ago\n",
+				i,
+				res.By,
+				units.BytesSize(float64(res.Size)),
+				time.Since(time.Unix(res.At, 0)),
+			)
+		}
+	}
+
+	fmt.Println("")
+}
+
 var utilStorageReleaseReservedCmd = &cli.Command{
 	Name:      "release-reserved",
 	Usage:     "Manually release the reserved storage space",
@@ -611,3 +922,188 @@ var utilStorageReleaseReservedCmd = &cli.Command{
 		return nil
 	},
 }
+
+var utilStorageReserveCmd = &cli.Command{
+	Name:  "reserve",
+	Usage: "Manually reserve the storage space on the given instance for a sector",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "by",
+			Usage: "tag of the reservation, defaults to the sector id",
+		},
+	},
+	ArgsUsage: "<actor id> <number> <instance> <size>",
+	Action: func(cctx *cli.Context) error {
+		args := cctx.Args()
+		if args.Len() < 4 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		minerID, err := ShouldActor(args.Get(0), true)
+		if err != nil {
+			return fmt.Errorf("extract miner id: %w", err)
+		}
+
+		num, err := ShouldSectorNumber(args.Get(1))
+		if err != nil {
+			return fmt.Errorf("extract sector number: %w", err)
+		}
+
+		size, err := units.RAMInBytes(args.Get(3))
+		if err != nil {
+			return fmt.Errorf("parse size: %w", err)
+		}
+
+		if size <= 0 {
+			return fmt.Errorf("size should be positive")
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		sid := abi.SectorID{
+			Miner:  minerID,
+			Number: num,
+		}
+		reserved, err := api.Damocles.StoreReserve(actx, sid, args.Get(2), uint64(size), cctx.String("by"))
+		if err != nil {
+			return RPCCallError("StoreReserve", err)
+		}
+
+		Log.With("sector", util.FormatSectorID(sid)).Infof(
+			"reserved %s on %s",
+			units.BytesSize(float64(reserved.Size)),
+			args.Get(2),
+		)
+
+		return nil
+	},
+}
+
+var utilStorageLocalPiecesCmd = &cli.Command{
+	Name:  "local-pieces",
+	Usage: "List the pieces in the local piece stores",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "offset",
+			Usage: "number of the pieces to skip",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "max number of the pieces to list, 0 means no limit",
+			Value: 100,
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		pieces, err := api.Damocles.ListLocalPieces(actx, cctx.Int("offset"), cctx.Int("limit"))
+		if err != nil {
+			return RPCCallError("ListLocalPieces", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, pieces)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+
+		_, _ = fmt.Fprintln(tw, "PieceCid\tSize\tStore\tKey")
+		for _, p := range pieces {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", p.PieceCid, units.BytesSize(float64(p.Size)), p.Store, p.Key)
+		}
+
+		return nil
+	},
+}
+
+var utilStoragePieceBandwidthCmd = &cli.Command{
+	Name:  "piece-bandwidth",
+	Usage: "Change the bandwidth limits of the piece transfers served by the piecestore proxy",
+	Description: "The limits are in bytes per second, e.g. 100MiB, 0 means no limit.\n" +
+		"The transfers in progress are affected as well, the changes are lost after restart.",
+	ArgsUsage: "<per transfer> <aggregate>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		perTransfer, err := units.RAMInBytes(cctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("parse per transfer limit: %w", err)
+		}
+
+		aggregate, err := units.RAMInBytes(cctx.Args().Get(1))
+		if err != nil {
+			return fmt.Errorf("parse aggregate limit: %w", err)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		if err := api.Damocles.SetPieceBandwidthLimit(actx, perTransfer, aggregate); err != nil {
+			return RPCCallError("SetPieceBandwidthLimit", err)
+		}
+
+		return nil
+	},
+}
+
+var utilStorageVerifyPieceCmd = &cli.Command{
+	Name:      "verify-piece",
+	Usage:     "Check the piece data in the piece stores against its piece cid, to detect the silent corruptions",
+	ArgsUsage: "<piece cid>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "output the result in json format",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Args().Len() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		pieceCid, err := cid.Decode(cctx.Args().First())
+		if err != nil {
+			return fmt.Errorf("parse piece cid: %w", err)
+		}
+
+		api, actx, astop, err := extractAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer astop()
+
+		res, err := api.Damocles.VerifyPiece(actx, pieceCid)
+		if err != nil {
+			return RPCCallError("VerifyPiece", err)
+		}
+
+		if cctx.Bool("json") {
+			return OutputJSON(os.Stdout, res)
+		}
+
+		if !res.Match {
+			return fmt.Errorf("%s, computed %s, payload size %d", color.RedString("mismatch"), res.Computed, res.PayloadSize)
+		}
+
+		fmt.Printf("%s: ok, payload size %d, piece size %d\n", res.PieceCid, res.PayloadSize, res.PieceSize)
+		return nil
+	},
+}
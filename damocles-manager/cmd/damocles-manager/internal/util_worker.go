@@ -22,7 +22,9 @@ var utilWorkerCmd = &cli.Command{
 	Usage: "Utils for worker management",
 	Subcommands: []*cli.Command{
 		utilWorkerListCmd,
+		utilWorkerFindCmd,
 		utilWorkerRemoveCmd,
+		utilWorkerRegisterCmd,
 		utilWorkerInfoCmd,
 		utilWorkerPauseCmd,
 		utilWorkerResumeCmd,
@@ -35,8 +37,7 @@ var utilWorkerListCmd = &cli.Command{
 	Flags: []cli.Flag{
 		&cli.DurationFlag{
 			Name:  "expiration",
-			Value: 10 * time.Minute,
-			Usage: "timeout for regarding a woker as missing",
+			Usage: "timeout for regarding a woker as missing, the online status reported by the manager is used if not set",
 		},
 	},
 	Action: func(cctx *cli.Context) error {
@@ -62,7 +63,12 @@ var utilWorkerListCmd = &cli.Command{
 		for _, pinfo := range pinfos {
 			lastPing := time.Since(time.Unix(pinfo.LastPing, 0))
 			lastPingWarn := ""
-			if lastPing > expiration {
+			expired := !pinfo.Online
+			if cctx.IsSet("expiration") {
+				expired = lastPing > expiration
+			}
+
+			if expired {
 				lastPingWarn = " (!)"
 			}
 
@@ -86,6 +92,48 @@ var utilWorkerListCmd = &cli.Command{
 	},
 }
 
+var utilWorkerFindCmd = &cli.Command{
+	Name:      "find",
+	Usage:     "Find the workers reporting from the specific host",
+	ArgsUsage: "<host or ip>",
+	Action: func(cctx *cli.Context) error {
+		args := cctx.Args()
+		if args.Len() < 1 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+
+		a, actx, stopper, err := extractAPI(cctx)
+		if err != nil {
+			return fmt.Errorf("get api: %w", err)
+		}
+		defer stopper()
+
+		pinfos, err := a.Damocles.WorkerFindByHost(actx, args.First())
+		if err != nil {
+			return RPCCallError("WorkerFindByHost", err)
+		}
+
+		if len(pinfos) == 0 {
+			return fmt.Errorf("no worker found on host %s", args.First())
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		defer tw.Flush()
+		_, _ = fmt.Fprintln(tw, "Name\tDest\tVersion\tLastPing")
+		for _, pinfo := range pinfos {
+			_, _ = fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\n",
+				pinfo.Info.Name,
+				pinfo.Info.Dest,
+				pinfo.Info.Version,
+				time.Since(time.Unix(pinfo.LastPing, 0)),
+			)
+		}
+
+		return nil
+	},
+}
+
 var utilWorkerRemoveCmd = &cli.Command{
 	Name:      "remove",
 	Usage:     "Remove the specific worker",
@@ -120,6 +168,38 @@ var utilWorkerRemoveCmd = &cli.Command{
 	},
 }
 
+var utilWorkerRegisterCmd = &cli.Command{
+	Name:      "register",
+	Usage:     "Register the specific worker, required when the auto registration is disabled",
+	ArgsUsage: "<worker instance name>",
+	Action: func(cctx *cli.Context) error {
+		args := cctx.Args()
+		if args.Len() < 1 {
+			return cli.ShowSubcommandHelp(cctx)
+		}
+		name := args.First()
+
+		a, actx, stopper, err := extractAPI(cctx)
+		if err != nil {
+			return fmt.Errorf("get api: %w", err)
+		}
+		defer stopper()
+
+		registered, err := a.Damocles.WorkerRegister(actx, name)
+		if err != nil {
+			return RPCCallError("WorkerRegister", err)
+		}
+
+		if !registered {
+			fmt.Printf("'%s' already exists\n", name)
+			return nil
+		}
+
+		fmt.Printf("'%s' registered\n", name)
+		return nil
+	},
+}
+
 var utilWorkerInfoCmd = &cli.Command{
 	Name:      "info",
 	Usage:     "Show details about the specific worker",
@@ -24,6 +24,7 @@ func main() {
 		Flags: []cli.Flag{
 			internal.HomeFlag,
 			internal.NetFlag,
+			internal.DefaultMinerFlag,
 		},
 		Before: func(cctx *cli.Context) error {
 			if cctx.String(internal.NetFlag.Name) != "" {
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -92,8 +93,16 @@ type SealerAPI interface {
 type SealerCliAPI interface {
 	ListSectors(context.Context, SectorWorkerState, SectorWorkerJob) ([]*SectorState, error)
 
+	ListSectorsEx(context.Context, SectorWorkerState, SectorWorkerJob, ListSectorsOptions) ([]*SectorState, error)
+
 	FindSector(ctx context.Context, state SectorWorkerState, sid abi.SectorID) (*SectorState, error)
 
+	ListSectorsByDeadline(ctx context.Context, mid abi.ActorID, deadlineIdx uint64) ([]*SectorState, error)
+
+	SectorsChangedSince(ctx context.Context, since time.Time) ([]*SectorState, error)
+
+	ReconcileMinerSectors(ctx context.Context, mid abi.ActorID) (*MinerSectorsReconciliation, error)
+
 	FindSectorInAllStates(ctx context.Context, sid abi.SectorID) (*SectorState, error)
 
 	FindSectorsWithDeal(ctx context.Context, state SectorWorkerState, dealID abi.DealID) ([]*SectorState, error)
@@ -102,8 +111,22 @@ type SealerCliAPI interface {
 
 	ImportSector(ctx context.Context, ws SectorWorkerState, state *SectorState, override bool) (bool, error)
 
+	ImportSectorsFromDir(ctx context.Context, mid abi.ActorID, storePath string) (*SectorsDirImportResult, error)
+
 	RestoreSector(ctx context.Context, sid abi.SectorID, forced bool) (Meta, error)
 
+	RestoreSectorEx(ctx context.Context, sid abi.SectorID, opts RestoreSectorOptions) (Meta, error)
+
+	AbandonSector(ctx context.Context, sid abi.SectorID, reason string) error
+
+	RestoreAborted(ctx context.Context, mid abi.ActorID, forced bool) ([]SectorRestoreResult, error)
+
+	ReplaySectorStage(ctx context.Context, sid abi.SectorID, stage SectorReplayStage) error
+
+	ProvabilityReport(ctx context.Context, mid abi.ActorID, strict bool, opts ProvableOptions) (*ProvabilityReport, error)
+
+	CheckProvableMulti(ctx context.Context, requests []ProvableRequest) (map[abi.ActorID]ProvableResult, error)
+
 	CheckProvable(
 		ctx context.Context,
 		mid abi.ActorID,
@@ -112,6 +135,30 @@ type SealerCliAPI interface {
 		strict, stateCheck bool,
 	) (map[abi.SectorNumber]string, error)
 
+	CheckProvableEx(
+		ctx context.Context,
+		mid abi.ActorID,
+		postProofType abi.RegisteredPoStProof,
+		sectors []builtin.ExtendedSectorInfo,
+		strict, stateCheck bool,
+		opts ProvableOptions,
+	) (map[abi.SectorNumber]string, error)
+
+	CheckProvableMixed(
+		ctx context.Context,
+		mid abi.ActorID,
+		sectors []builtin.ExtendedSectorInfo,
+		strict, stateCheck bool,
+		opts ProvableOptions,
+	) (map[abi.SectorNumber]string, error)
+
+	PrePoStCheck(
+		ctx context.Context,
+		mid abi.ActorID,
+		postProofType abi.RegisteredPoStProof,
+		deadlineIdx uint64,
+	) (map[abi.SectorNumber]string, error)
+
 	SimulateWdPoSt(
 		ctx context.Context,
 		ddlIndex, partitionIndex uint64,
@@ -125,32 +172,98 @@ type SealerCliAPI interface {
 
 	SnapUpCandidates(ctx context.Context, mid abi.ActorID) ([]*bitfield.BitField, error)
 
+	SnapUpPause(ctx context.Context, mid abi.ActorID) error
+
+	SnapUpResume(ctx context.Context, mid abi.ActorID) error
+
 	SnapUpCancelCommitment(ctx context.Context, sid abi.SectorID) error
 
 	ProvingSectorInfo(ctx context.Context, sid abi.SectorID) (ProvingSectorInfo, error)
 
+	InspectPrivateSectorInfo(ctx context.Context, sid abi.SectorID) (*PrivateSectorInspection, error)
+
+	CheckSectorProvable(ctx context.Context, sid abi.SectorID, strict bool) (*SectorProvability, error)
+
+	SectorsExpiringBefore(ctx context.Context, mid abi.ActorID, epoch abi.ChainEpoch) ([]SectorExpirationInfo, error)
+
+	ListSealedDeals(ctx context.Context, mid abi.ActorID) ([]SealedDealInfo, error)
+
+	DealStats(ctx context.Context, mid abi.ActorID) (*DealStats, error)
+
+	SealDurationStats(ctx context.Context, mid abi.ActorID, since time.Time) (*SealDurationStats, error)
+
+	StuckSectors(ctx context.Context, olderThan time.Duration) ([]StuckSector, error)
+
 	WorkerGetPingInfo(ctx context.Context, name string) (*WorkerPingInfo, error)
 
 	WorkerPingInfoList(ctx context.Context) ([]WorkerPingInfo, error)
 
+	WorkerFindByHost(ctx context.Context, host string) ([]WorkerPingInfo, error)
+
 	WorkerPingInfoRemove(ctx context.Context, name string) error
 
+	WorkerRegister(ctx context.Context, name string) (bool, error)
+
 	SectorIndexerFind(ctx context.Context, indexType SectorIndexType, sid abi.SectorID) (SectorIndexLocation, error)
 
 	TerminateSector(context.Context, abi.SectorID) (SubmitTerminateResp, error)
 
 	PollTerminateSectorState(context.Context, abi.SectorID) (TerminateInfo, error)
 
+	ListPendingTerminations(ctx context.Context, mid abi.ActorID) ([]PendingTermination, error)
+
+	CancelTermination(ctx context.Context, sid abi.SectorID) error
+
+	EstimateTerminateGas(ctx context.Context, sids []abi.SectorID) ([]TerminateGasEstimate, error)
+
+	SectorPaths(ctx context.Context, sid abi.SectorID) (*SectorPaths, error)
+
+	SectorPieces(ctx context.Context, sid abi.SectorID) ([]SectorPieceLocation, error)
+
+	CanRemoveSector(context.Context, abi.SectorID) (*SectorRemovability, error)
+
 	RemoveSector(context.Context, abi.SectorID) error
 
+	VerifySealedFileSizes(ctx context.Context, mid abi.ActorID) ([]SealedFileSizeMismatch, error)
+
+	CheckSectorCache(ctx context.Context, sid abi.SectorID) (*SectorCacheCheckResult, error)
+
+	RepairSectorCache(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (*SectorCacheRepairResult, error)
+
 	FinalizeSector(context.Context, abi.SectorID) error
 
 	StoreReleaseReserved(ctx context.Context, sid abi.SectorID) (bool, error)
 
+	StoreReserve(ctx context.Context, sid abi.SectorID, instanceName string, size uint64, by string) (ReservedItem, error)
+
 	StoreList(ctx context.Context) ([]StoreDetailedInfo, error)
 
+	StoreListEx(ctx context.Context, opts StoreListOptions) ([]StoreDetailedInfo, error)
+
+	StoreRefreshInfo(ctx context.Context, instanceName string) (*StoreDetailedInfo, error)
+
+	StoreCapacityReport(ctx context.Context, sectorSize abi.SectorSize) (*StoreCapacityReport, error)
+
+	StoreRebalance(ctx context.Context, fromInstance, toInstance string, maxSectors int) (*StoreRebalanceResult, error)
+
+	SectorsOnStore(ctx context.Context, instanceName string) (*SectorsOnStore, error)
+
+	ReindexStore(ctx context.Context, instanceName string, dryRun bool) (*StoreReindexResult, error)
+
+	FindOrphanedFiles(ctx context.Context, instanceName string) (*StoreOrphanedFiles, error)
+
 	SectorSetForRebuild(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (bool, error)
 
+	SetSectorLabels(ctx context.Context, sid abi.SectorID, labels map[string]string) (SectorLabels, error)
+
+	RederiveTicket(ctx context.Context, sid abi.SectorID, epoch abi.ChainEpoch) (*Ticket, error)
+
+	ListRebuildSectors(ctx context.Context, mid *abi.ActorID) ([]SectorRebuildStatus, error)
+
+	RebuildProgress(ctx context.Context, sid abi.SectorID) (*SectorRebuildProgress, error)
+
+	ValidateUpgradeInfo(ctx context.Context, sid abi.SectorID) (*SectorUpgradeValidation, error)
+
 	// Unseal Sector
 	UnsealPiece(
 		ctx context.Context,
@@ -161,6 +274,18 @@ type SealerCliAPI interface {
 		dest string,
 	) (<-chan []byte, error)
 
+	VerifyPiece(ctx context.Context, pieceCid cid.Cid) (*PieceVerification, error)
+
+	ListLocalPieces(ctx context.Context, offset, limit int) ([]LocalPiece, error)
+
+	SetPieceBandwidthLimit(ctx context.Context, perTransfer, aggregate int64) error
+
+	HealthCheck(ctx context.Context) (*HealthReport, error)
+
+	APIFingerprint(ctx context.Context) (map[string]string, error)
+
+	ProverInfo(ctx context.Context) (*ProverInfo, error)
+
 	Version(ctx context.Context) (string, error)
 }
 
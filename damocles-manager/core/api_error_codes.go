@@ -4,4 +4,5 @@ import "github.com/filecoin-project/go-jsonrpc"
 
 const (
 	APIErrCodeSectorStateNotFound = jsonrpc.ErrorCode(11001)
+	APIErrCodeSectorBusy          = jsonrpc.ErrorCode(11002)
 )
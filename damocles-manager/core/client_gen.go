@@ -12,6 +12,7 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/extproc/stage"
 	"github.com/ipfs/go-cid"
+	"time"
 )
 
 // SealerAPIClient is generated client for SealerAPI interface.
@@ -113,30 +114,83 @@ var UnavailableSealerAPIClient = SealerAPIClient{
 // SealerCliAPIClient is generated client for SealerCliAPI interface.
 type SealerCliAPIClient struct {
 	ListSectors              func(context.Context, SectorWorkerState, SectorWorkerJob) ([]*SectorState, error)
+	ListSectorsEx            func(context.Context, SectorWorkerState, SectorWorkerJob, ListSectorsOptions) ([]*SectorState, error)
 	FindSector               func(ctx context.Context, state SectorWorkerState, sid abi.SectorID) (*SectorState, error)
+	ListSectorsByDeadline    func(ctx context.Context, mid abi.ActorID, deadlineIdx uint64) ([]*SectorState, error)
+	SectorsChangedSince      func(ctx context.Context, since time.Time) ([]*SectorState, error)
+	ReconcileMinerSectors    func(ctx context.Context, mid abi.ActorID) (*MinerSectorsReconciliation, error)
 	FindSectorInAllStates    func(ctx context.Context, sid abi.SectorID) (*SectorState, error)
 	FindSectorsWithDeal      func(ctx context.Context, state SectorWorkerState, dealID abi.DealID) ([]*SectorState, error)
 	FindSectorWithPiece      func(ctx context.Context, state SectorWorkerState, pieceCid cid.Cid) (*SectorState, error)
 	ImportSector             func(ctx context.Context, ws SectorWorkerState, state *SectorState, override bool) (bool, error)
+	ImportSectorsFromDir     func(ctx context.Context, mid abi.ActorID, storePath string) (*SectorsDirImportResult, error)
 	RestoreSector            func(ctx context.Context, sid abi.SectorID, forced bool) (Meta, error)
+	RestoreSectorEx          func(ctx context.Context, sid abi.SectorID, opts RestoreSectorOptions) (Meta, error)
+	AbandonSector            func(ctx context.Context, sid abi.SectorID, reason string) error
+	RestoreAborted           func(ctx context.Context, mid abi.ActorID, forced bool) ([]SectorRestoreResult, error)
+	ReplaySectorStage        func(ctx context.Context, sid abi.SectorID, stage SectorReplayStage) error
+	ProvabilityReport        func(ctx context.Context, mid abi.ActorID, strict bool, opts ProvableOptions) (*ProvabilityReport, error)
+	CheckProvableMulti       func(ctx context.Context, requests []ProvableRequest) (map[abi.ActorID]ProvableResult, error)
 	CheckProvable            func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool) (map[abi.SectorNumber]string, error)
+	CheckProvableEx          func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool, opts ProvableOptions) (map[abi.SectorNumber]string, error)
+	CheckProvableMixed       func(ctx context.Context, mid abi.ActorID, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool, opts ProvableOptions) (map[abi.SectorNumber]string, error)
+	PrePoStCheck             func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, deadlineIdx uint64) (map[abi.SectorNumber]string, error)
 	SimulateWdPoSt           func(ctx context.Context, ddlIndex, partitionIndex uint64, maddr address.Address, postProofType abi.RegisteredPoStProof, sis []builtin.ExtendedSectorInfo, rand abi.PoStRandomness) error
 	SnapUpPreFetch           func(ctx context.Context, mid abi.ActorID, dlindex *uint64) (*SnapUpFetchResult, error)
 	SnapUpCandidates         func(ctx context.Context, mid abi.ActorID) ([]*bitfield.BitField, error)
+	SnapUpPause              func(ctx context.Context, mid abi.ActorID) error
+	SnapUpResume             func(ctx context.Context, mid abi.ActorID) error
 	SnapUpCancelCommitment   func(ctx context.Context, sid abi.SectorID) error
 	ProvingSectorInfo        func(ctx context.Context, sid abi.SectorID) (ProvingSectorInfo, error)
+	InspectPrivateSectorInfo func(ctx context.Context, sid abi.SectorID) (*PrivateSectorInspection, error)
+	CheckSectorProvable      func(ctx context.Context, sid abi.SectorID, strict bool) (*SectorProvability, error)
+	SectorsExpiringBefore    func(ctx context.Context, mid abi.ActorID, epoch abi.ChainEpoch) ([]SectorExpirationInfo, error)
+	ListSealedDeals          func(ctx context.Context, mid abi.ActorID) ([]SealedDealInfo, error)
+	DealStats                func(ctx context.Context, mid abi.ActorID) (*DealStats, error)
+	SealDurationStats        func(ctx context.Context, mid abi.ActorID, since time.Time) (*SealDurationStats, error)
+	StuckSectors             func(ctx context.Context, olderThan time.Duration) ([]StuckSector, error)
 	WorkerGetPingInfo        func(ctx context.Context, name string) (*WorkerPingInfo, error)
 	WorkerPingInfoList       func(ctx context.Context) ([]WorkerPingInfo, error)
+	WorkerFindByHost         func(ctx context.Context, host string) ([]WorkerPingInfo, error)
 	WorkerPingInfoRemove     func(ctx context.Context, name string) error
+	WorkerRegister           func(ctx context.Context, name string) (bool, error)
 	SectorIndexerFind        func(ctx context.Context, indexType SectorIndexType, sid abi.SectorID) (SectorIndexLocation, error)
 	TerminateSector          func(context.Context, abi.SectorID) (SubmitTerminateResp, error)
 	PollTerminateSectorState func(context.Context, abi.SectorID) (TerminateInfo, error)
+	ListPendingTerminations  func(ctx context.Context, mid abi.ActorID) ([]PendingTermination, error)
+	CancelTermination        func(ctx context.Context, sid abi.SectorID) error
+	EstimateTerminateGas     func(ctx context.Context, sids []abi.SectorID) ([]TerminateGasEstimate, error)
+	SectorPaths              func(ctx context.Context, sid abi.SectorID) (*SectorPaths, error)
+	SectorPieces             func(ctx context.Context, sid abi.SectorID) ([]SectorPieceLocation, error)
+	CanRemoveSector          func(context.Context, abi.SectorID) (*SectorRemovability, error)
 	RemoveSector             func(context.Context, abi.SectorID) error
+	VerifySealedFileSizes    func(ctx context.Context, mid abi.ActorID) ([]SealedFileSizeMismatch, error)
+	CheckSectorCache         func(ctx context.Context, sid abi.SectorID) (*SectorCacheCheckResult, error)
+	RepairSectorCache        func(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (*SectorCacheRepairResult, error)
 	FinalizeSector           func(context.Context, abi.SectorID) error
 	StoreReleaseReserved     func(ctx context.Context, sid abi.SectorID) (bool, error)
+	StoreReserve             func(ctx context.Context, sid abi.SectorID, instanceName string, size uint64, by string) (ReservedItem, error)
 	StoreList                func(ctx context.Context) ([]StoreDetailedInfo, error)
+	StoreListEx              func(ctx context.Context, opts StoreListOptions) ([]StoreDetailedInfo, error)
+	StoreRefreshInfo         func(ctx context.Context, instanceName string) (*StoreDetailedInfo, error)
+	StoreCapacityReport      func(ctx context.Context, sectorSize abi.SectorSize) (*StoreCapacityReport, error)
+	StoreRebalance           func(ctx context.Context, fromInstance, toInstance string, maxSectors int) (*StoreRebalanceResult, error)
+	SectorsOnStore           func(ctx context.Context, instanceName string) (*SectorsOnStore, error)
+	ReindexStore             func(ctx context.Context, instanceName string, dryRun bool) (*StoreReindexResult, error)
+	FindOrphanedFiles        func(ctx context.Context, instanceName string) (*StoreOrphanedFiles, error)
 	SectorSetForRebuild      func(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (bool, error)
+	SetSectorLabels          func(ctx context.Context, sid abi.SectorID, labels map[string]string) (SectorLabels, error)
+	RederiveTicket           func(ctx context.Context, sid abi.SectorID, epoch abi.ChainEpoch) (*Ticket, error)
+	ListRebuildSectors       func(ctx context.Context, mid *abi.ActorID) ([]SectorRebuildStatus, error)
+	RebuildProgress          func(ctx context.Context, sid abi.SectorID) (*SectorRebuildProgress, error)
+	ValidateUpgradeInfo      func(ctx context.Context, sid abi.SectorID) (*SectorUpgradeValidation, error)
 	UnsealPiece              func(ctx context.Context, sid abi.SectorID, pieceCid cid.Cid, offset types.UnpaddedByteIndex, size abi.UnpaddedPieceSize, dest string) (<-chan []byte, error)
+	VerifyPiece              func(ctx context.Context, pieceCid cid.Cid) (*PieceVerification, error)
+	ListLocalPieces          func(ctx context.Context, offset, limit int) ([]LocalPiece, error)
+	SetPieceBandwidthLimit   func(ctx context.Context, perTransfer, aggregate int64) error
+	HealthCheck              func(ctx context.Context) (*HealthReport, error)
+	APIFingerprint           func(ctx context.Context) (map[string]string, error)
+	ProverInfo               func(ctx context.Context) (*ProverInfo, error)
 	Version                  func(ctx context.Context) (string, error)
 }
 
@@ -145,9 +199,21 @@ var UnavailableSealerCliAPIClient = SealerCliAPIClient{
 	ListSectors: func(context.Context, SectorWorkerState, SectorWorkerJob) ([]*SectorState, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	ListSectorsEx: func(context.Context, SectorWorkerState, SectorWorkerJob, ListSectorsOptions) ([]*SectorState, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	FindSector: func(ctx context.Context, state SectorWorkerState, sid abi.SectorID) (*SectorState, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	ListSectorsByDeadline: func(ctx context.Context, mid abi.ActorID, deadlineIdx uint64) ([]*SectorState, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SectorsChangedSince: func(ctx context.Context, since time.Time) ([]*SectorState, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ReconcileMinerSectors: func(ctx context.Context, mid abi.ActorID) (*MinerSectorsReconciliation, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	FindSectorInAllStates: func(ctx context.Context, sid abi.SectorID) (*SectorState, error) {
 		panic("SealerCliAPI client unavailable")
 	},
@@ -160,12 +226,42 @@ var UnavailableSealerCliAPIClient = SealerCliAPIClient{
 	ImportSector: func(ctx context.Context, ws SectorWorkerState, state *SectorState, override bool) (bool, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	ImportSectorsFromDir: func(ctx context.Context, mid abi.ActorID, storePath string) (*SectorsDirImportResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	RestoreSector: func(ctx context.Context, sid abi.SectorID, forced bool) (Meta, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	RestoreSectorEx: func(ctx context.Context, sid abi.SectorID, opts RestoreSectorOptions) (Meta, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	AbandonSector: func(ctx context.Context, sid abi.SectorID, reason string) error {
+		panic("SealerCliAPI client unavailable")
+	},
+	RestoreAborted: func(ctx context.Context, mid abi.ActorID, forced bool) ([]SectorRestoreResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ReplaySectorStage: func(ctx context.Context, sid abi.SectorID, stage SectorReplayStage) error {
+		panic("SealerCliAPI client unavailable")
+	},
+	ProvabilityReport: func(ctx context.Context, mid abi.ActorID, strict bool, opts ProvableOptions) (*ProvabilityReport, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CheckProvableMulti: func(ctx context.Context, requests []ProvableRequest) (map[abi.ActorID]ProvableResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	CheckProvable: func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool) (map[abi.SectorNumber]string, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	CheckProvableEx: func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool, opts ProvableOptions) (map[abi.SectorNumber]string, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CheckProvableMixed: func(ctx context.Context, mid abi.ActorID, sectors []builtin.ExtendedSectorInfo, strict, stateCheck bool, opts ProvableOptions) (map[abi.SectorNumber]string, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	PrePoStCheck: func(ctx context.Context, mid abi.ActorID, postProofType abi.RegisteredPoStProof, deadlineIdx uint64) (map[abi.SectorNumber]string, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	SimulateWdPoSt: func(ctx context.Context, ddlIndex, partitionIndex uint64, maddr address.Address, postProofType abi.RegisteredPoStProof, sis []builtin.ExtendedSectorInfo, rand abi.PoStRandomness) error {
 		panic("SealerCliAPI client unavailable")
 	},
@@ -175,21 +271,54 @@ var UnavailableSealerCliAPIClient = SealerCliAPIClient{
 	SnapUpCandidates: func(ctx context.Context, mid abi.ActorID) ([]*bitfield.BitField, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	SnapUpPause: func(ctx context.Context, mid abi.ActorID) error {
+		panic("SealerCliAPI client unavailable")
+	},
+	SnapUpResume: func(ctx context.Context, mid abi.ActorID) error {
+		panic("SealerCliAPI client unavailable")
+	},
 	SnapUpCancelCommitment: func(ctx context.Context, sid abi.SectorID) error {
 		panic("SealerCliAPI client unavailable")
 	},
 	ProvingSectorInfo: func(ctx context.Context, sid abi.SectorID) (ProvingSectorInfo, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	InspectPrivateSectorInfo: func(ctx context.Context, sid abi.SectorID) (*PrivateSectorInspection, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CheckSectorProvable: func(ctx context.Context, sid abi.SectorID, strict bool) (*SectorProvability, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SectorsExpiringBefore: func(ctx context.Context, mid abi.ActorID, epoch abi.ChainEpoch) ([]SectorExpirationInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ListSealedDeals: func(ctx context.Context, mid abi.ActorID) ([]SealedDealInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	DealStats: func(ctx context.Context, mid abi.ActorID) (*DealStats, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SealDurationStats: func(ctx context.Context, mid abi.ActorID, since time.Time) (*SealDurationStats, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	StuckSectors: func(ctx context.Context, olderThan time.Duration) ([]StuckSector, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	WorkerGetPingInfo: func(ctx context.Context, name string) (*WorkerPingInfo, error) {
 		panic("SealerCliAPI client unavailable")
 	},
 	WorkerPingInfoList: func(ctx context.Context) ([]WorkerPingInfo, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	WorkerFindByHost: func(ctx context.Context, host string) ([]WorkerPingInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	WorkerPingInfoRemove: func(ctx context.Context, name string) error {
 		panic("SealerCliAPI client unavailable")
 	},
+	WorkerRegister: func(ctx context.Context, name string) (bool, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	SectorIndexerFind: func(ctx context.Context, indexType SectorIndexType, sid abi.SectorID) (SectorIndexLocation, error) {
 		panic("SealerCliAPI client unavailable")
 	},
@@ -199,24 +328,108 @@ var UnavailableSealerCliAPIClient = SealerCliAPIClient{
 	PollTerminateSectorState: func(context.Context, abi.SectorID) (TerminateInfo, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	ListPendingTerminations: func(ctx context.Context, mid abi.ActorID) ([]PendingTermination, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CancelTermination: func(ctx context.Context, sid abi.SectorID) error {
+		panic("SealerCliAPI client unavailable")
+	},
+	EstimateTerminateGas: func(ctx context.Context, sids []abi.SectorID) ([]TerminateGasEstimate, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SectorPaths: func(ctx context.Context, sid abi.SectorID) (*SectorPaths, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SectorPieces: func(ctx context.Context, sid abi.SectorID) ([]SectorPieceLocation, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CanRemoveSector: func(context.Context, abi.SectorID) (*SectorRemovability, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	RemoveSector: func(context.Context, abi.SectorID) error {
 		panic("SealerCliAPI client unavailable")
 	},
+	VerifySealedFileSizes: func(ctx context.Context, mid abi.ActorID) ([]SealedFileSizeMismatch, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	CheckSectorCache: func(ctx context.Context, sid abi.SectorID) (*SectorCacheCheckResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	RepairSectorCache: func(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (*SectorCacheRepairResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	FinalizeSector: func(context.Context, abi.SectorID) error {
 		panic("SealerCliAPI client unavailable")
 	},
 	StoreReleaseReserved: func(ctx context.Context, sid abi.SectorID) (bool, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	StoreReserve: func(ctx context.Context, sid abi.SectorID, instanceName string, size uint64, by string) (ReservedItem, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	StoreList: func(ctx context.Context) ([]StoreDetailedInfo, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	StoreListEx: func(ctx context.Context, opts StoreListOptions) ([]StoreDetailedInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	StoreRefreshInfo: func(ctx context.Context, instanceName string) (*StoreDetailedInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	StoreCapacityReport: func(ctx context.Context, sectorSize abi.SectorSize) (*StoreCapacityReport, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	StoreRebalance: func(ctx context.Context, fromInstance, toInstance string, maxSectors int) (*StoreRebalanceResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SectorsOnStore: func(ctx context.Context, instanceName string) (*SectorsOnStore, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ReindexStore: func(ctx context.Context, instanceName string, dryRun bool) (*StoreReindexResult, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	FindOrphanedFiles: func(ctx context.Context, instanceName string) (*StoreOrphanedFiles, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	SectorSetForRebuild: func(ctx context.Context, sid abi.SectorID, opt RebuildOptions) (bool, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	SetSectorLabels: func(ctx context.Context, sid abi.SectorID, labels map[string]string) (SectorLabels, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	RederiveTicket: func(ctx context.Context, sid abi.SectorID, epoch abi.ChainEpoch) (*Ticket, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ListRebuildSectors: func(ctx context.Context, mid *abi.ActorID) ([]SectorRebuildStatus, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	RebuildProgress: func(ctx context.Context, sid abi.SectorID) (*SectorRebuildProgress, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ValidateUpgradeInfo: func(ctx context.Context, sid abi.SectorID) (*SectorUpgradeValidation, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	UnsealPiece: func(ctx context.Context, sid abi.SectorID, pieceCid cid.Cid, offset types.UnpaddedByteIndex, size abi.UnpaddedPieceSize, dest string) (<-chan []byte, error) {
 		panic("SealerCliAPI client unavailable")
 	},
+	VerifyPiece: func(ctx context.Context, pieceCid cid.Cid) (*PieceVerification, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ListLocalPieces: func(ctx context.Context, offset, limit int) ([]LocalPiece, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	SetPieceBandwidthLimit: func(ctx context.Context, perTransfer, aggregate int64) error {
+		panic("SealerCliAPI client unavailable")
+	},
+	HealthCheck: func(ctx context.Context) (*HealthReport, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	APIFingerprint: func(ctx context.Context) (map[string]string, error) {
+		panic("SealerCliAPI client unavailable")
+	},
+	ProverInfo: func(ctx context.Context) (*ProverInfo, error) {
+		panic("SealerCliAPI client unavailable")
+	},
 	Version: func(ctx context.Context) (string, error) {
 		panic("SealerCliAPI client unavailable")
 	},
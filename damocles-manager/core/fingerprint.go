@@ -0,0 +1,60 @@
+package core
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// APIFingerprint returns the md5 checksums of the signatures of the methods in APIFull, keyed by the method names.
+func APIFingerprint() map[string]string {
+	typ := reflect.TypeOf((*APIFull)(nil)).Elem()
+	fp := make(map[string]string, typ.NumMethod())
+	for i := 0; i < typ.NumMethod(); i++ {
+		meth := typ.Method(i)
+		sum := md5.Sum([]byte(meth.Type.String()))
+		fp[meth.Name] = hex.EncodeToString(sum[:])
+	}
+
+	return fp
+}
+
+// CheckAPIFingerprint compares the remote fingerprint with the local one, only the given methods are checked,
+// all of the methods will be checked if none is given.
+func CheckAPIFingerprint(remote map[string]string, methods ...string) error {
+	local := APIFingerprint()
+	if len(methods) == 0 {
+		methods = make([]string, 0, len(local))
+		for name := range local {
+			methods = append(methods, name)
+		}
+	}
+
+	var missing, mismatched []string
+	for _, name := range methods {
+		sum, ok := remote[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+
+		if sum != local[name] {
+			mismatched = append(mismatched, name)
+		}
+	}
+
+	if len(missing) == 0 && len(mismatched) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	sort.Strings(mismatched)
+	return fmt.Errorf(
+		"api mismatched with the server, missing: [%s], mismatched: [%s], please upgrade the client or the server",
+		strings.Join(missing, ", "),
+		strings.Join(mismatched, ", "),
+	)
+}
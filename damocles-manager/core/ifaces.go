@@ -47,6 +47,9 @@ type CommitmentManager interface {
 
 	SubmitTerminate(context.Context, abi.SectorID) (SubmitTerminateResp, error)
 	TerminateState(context.Context, abi.SectorID) (TerminateInfo, error)
+	PendingTerminations(context.Context, abi.ActorID) ([]PendingTermination, error)
+	CancelTerminate(context.Context, abi.SectorID) error
+	EstimateTerminate(context.Context, []abi.SectorID) ([]TerminateGasEstimate, error)
 }
 
 type SectorNumberAllocator interface {
@@ -119,6 +122,22 @@ type SectorProving interface {
 		sectors []builtin.ExtendedSectorInfo,
 		strict, stateCheck bool,
 	) (map[abi.SectorNumber]string, error)
+	ProvableEx(
+		ctx context.Context,
+		mid abi.ActorID,
+		postProofType abi.RegisteredPoStProof,
+		sectors []builtin.ExtendedSectorInfo,
+		strict, stateCheck bool,
+		opts ProvableOptions,
+	) (map[abi.SectorNumber]string, error)
+	ProvableErrs(
+		ctx context.Context,
+		mid abi.ActorID,
+		postProofType abi.RegisteredPoStProof,
+		sectors []builtin.ExtendedSectorInfo,
+		strict, stateCheck bool,
+		opts ProvableOptions,
+	) (map[abi.SectorNumber]error, error)
 	SectorTracker
 }
 
@@ -129,6 +148,9 @@ type SnapUpSectorManager interface {
 	Release(ctx context.Context, candidate *SnapUpCandidate) error
 	Commit(ctx context.Context, sid abi.SectorID) error
 	CancelCommitment(ctx context.Context, sid abi.SectorID)
+	Pause(ctx context.Context, mid abi.ActorID) error
+	Resume(ctx context.Context, mid abi.ActorID) error
+	Paused(ctx context.Context, mid abi.ActorID) (bool, error)
 }
 
 type WorkerManager interface {
@@ -141,6 +163,7 @@ type WorkerManager interface {
 type RebuildSectorManager interface {
 	Set(ctx context.Context, sid abi.SectorID, info SectorRebuildInfo) error
 	Allocate(ctx context.Context, spec AllocateSectorSpec) (*SectorRebuildInfo, error)
+	All(ctx context.Context) ([]SectorRebuildInfo, error)
 }
 
 type UnsealSectorManager interface {
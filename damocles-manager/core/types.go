@@ -2,10 +2,14 @@ package core
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
 	commcid "github.com/filecoin-project/go-fil-commcid"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
 	"github.com/filecoin-project/venus/venus-shared/actors/builtin/miner"
 	verifregtypes "github.com/filecoin-project/venus/venus-shared/actors/builtin/verifreg"
 	vtypes "github.com/filecoin-project/venus/venus-shared/types"
@@ -203,6 +207,20 @@ func (sp SectorPieceV2) EndEpoch() abi.ChainEpoch {
 	return abi.ChainEpoch(0)
 }
 
+// IsVerifiedPiece reports whether the piece is a verified deal, i.e. a legacy deal with a verified proposal,
+// or a deal bound to a verified registry allocation.
+func IsVerifiedPiece(p SectorPiece) bool {
+	if !p.HasDealInfo() {
+		return false
+	}
+
+	if ldi, ok := p.(LegacyDealInfo); ok {
+		return ldi.Proposal != nil && ldi.Proposal.VerifiedDeal
+	}
+
+	return p.AllocationID() != verifregtypes.NoAllocationID
+}
+
 type (
 	Deals        []LegacyDealInfo
 	SectorPieces []SectorPieceV2
@@ -386,6 +404,28 @@ type TerminateInfo struct {
 	AddedHeight  abi.ChainEpoch
 }
 
+// PendingTermination is a termination submitted but not landed on chain yet.
+type PendingTermination struct {
+	ID          abi.SectorID
+	AddedHeight abi.ChainEpoch
+	// TerminateCid is nil if the termination is still waiting in the batch
+	TerminateCid *cid.Cid
+	MessageState string
+}
+
+// TerminateGasEstimate is the gas estimation of one of the terminate messages which would be submitted for the sectors.
+type TerminateGasEstimate struct {
+	Miner abi.ActorID
+	// Sectors are the ones addressed by the message
+	Sectors    []abi.SectorNumber
+	Batched    bool
+	GasLimit   int64
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+	// MaxFee is GasFeeCap * GasLimit, the upper bound of the fee paid for the message
+	MaxFee abi.TokenAmount
+}
+
 type ReportStateReq struct {
 	Worker      WorkerIdentifier
 	StateChange SectorStateChange
@@ -464,6 +504,33 @@ type ProvingSectorInfo struct {
 	Private PrivateSectorInfo
 }
 
+// SectorProvability is the result of checking the provability of a single sector,
+// Reason is empty if the sector is provable.
+type SectorProvability struct {
+	ID       abi.SectorID
+	Provable bool
+	Reason   string
+}
+
+// SectorArtifactCheck is the result of checking a file required for proving,
+// Expected is 0 if the size is not checked.
+type SectorArtifactCheck struct {
+	Title    string
+	URI      string
+	Size     int64
+	Expected int64
+	Err      string `json:",omitempty"`
+}
+
+// PrivateSectorInspection describes every step of resolving the private info of a sector for proving,
+// Err is set for the first step failed, and Private is nil if the location can not be resolved.
+type PrivateSectorInspection struct {
+	Upgrade   bool
+	Private   *PrivateSectorInfo
+	Artifacts []SectorArtifactCheck
+	Err       string `json:",omitempty"`
+}
+
 type SectorIndexType string
 
 const (
@@ -503,6 +570,130 @@ type StoreDetailedInfo struct {
 
 type ReservedItem = objstore.StoreReserved
 
+type StoreSortBy string
+
+const (
+	// StoreSortByNone keeps the order of the store instances as listed
+	StoreSortByNone        StoreSortBy = ""
+	StoreSortByUsedPercent StoreSortBy = "used-percent"
+	StoreSortByFree        StoreSortBy = "free"
+	StoreSortByName        StoreSortBy = "name"
+)
+
+type StoreListOptions struct {
+	SortBy StoreSortBy
+	// Desc sorts in descending order, e.g. to put the fullest stores at the top
+	Desc bool
+}
+
+// StoreCapacityReport aggregates the capacities of all the store instances,
+// SectorsRemaining is the number of sectors of SectorSize the writable stores are still able to hold.
+type StoreCapacityReport struct {
+	SectorSize       abi.SectorSize
+	Total            uint64
+	Free             uint64
+	Reserved         uint64
+	Available        uint64
+	SectorsRemaining uint64
+	Stores           []StoreCapacity
+}
+
+type StoreCapacity struct {
+	Name             string
+	ReadOnly         bool
+	Total            uint64
+	Free             uint64
+	Reserved         uint64
+	Available        uint64
+	UsedPercent      float64
+	SectorsRemaining uint64
+}
+
+type StoreRebalanceResult struct {
+	Moved  []abi.SectorID
+	Failed []StoreRebalanceFailure
+}
+
+// SectorsOnStore holds the sectors having files on the store instance, split by the file types.
+type SectorsOnStore struct {
+	Instance    string
+	SealedFile  []abi.SectorID
+	CacheDir    []abi.SectorID
+	UpdateFile  []abi.SectorID
+	UpdateCache []abi.SectorID
+}
+
+// StoreReindexResult holds the sector files found in the store instance, which have been written into
+// the sector indexer unless DryRun is set. Skipped are the sector files found but not indexed,
+// and Unrecognized are the files not laid out as any sector file.
+type StoreReindexResult struct {
+	Found        SectorsOnStore
+	Skipped      []StoreReindexSkipped
+	Unrecognized []string
+	DryRun       bool
+}
+
+// StoreReindexSkipped is a sector file found by ReindexStore, which is not indexed for the Reason.
+type StoreReindexSkipped struct {
+	ID     abi.SectorID
+	Type   string
+	Reason string
+}
+
+// SectorDirImport is the outcome of a sector discovered in the dir, the ones with Problems are not imported.
+type SectorDirImport struct {
+	ID       abi.SectorID
+	Upgraded bool
+	Imported bool
+	Problems []string
+}
+
+// SectorImportMeta is the sidecar metadata of a sector imported from a dir, stored as `meta/<sector id>.json`.
+// It provides the ticket the chain doesn't keep for the proven sectors, the ticket is rederived from the chain
+// if only the epoch is given.
+type SectorImportMeta struct {
+	Ticket Ticket
+	Seed   *Seed `json:",omitempty"`
+}
+
+// SectorsDirImportResult holds the sectors discovered in the dir. Instance is the store instance located at the dir,
+// if any, the sector indexer entries of the imported sectors are pointed to it.
+type SectorsDirImportResult struct {
+	Instance string
+	Sectors  []SectorDirImport
+}
+
+const (
+	// OrphanedFileInProgress is the file of a sector still being sealed
+	OrphanedFileInProgress = "in-progress"
+	// OrphanedFileIndexedElsewhere is a leftover copy, the index of the sector points to another store instance
+	OrphanedFileIndexedElsewhere = "indexed-elsewhere"
+	// OrphanedFileUnindexed is the file of a sector having no index entry, which could be recovered by reindexing
+	OrphanedFileUnindexed = "unindexed"
+	// OrphanedFileNoSector is the file of a sector with neither sector state nor index entry, likely a dead sector
+	OrphanedFileNoSector = "no-sector"
+)
+
+// OrphanedSectorFile is a sector file not accounted for by the sector indexer.
+type OrphanedSectorFile struct {
+	ID       abi.SectorID
+	Type     string
+	Path     string
+	Category string
+}
+
+type StoreOrphanedFiles struct {
+	Instance string
+	Files    []OrphanedSectorFile
+	// Unrecognized are the files not laid out as any sector file
+	Unrecognized []string
+}
+
+type StoreRebalanceFailure struct {
+	ID  abi.SectorID
+	Err string
+}
+
 type RebuildOptions struct {
 	PiecesAvailable bool
 }
@@ -516,6 +707,374 @@ type SectorRebuildInfo struct {
 	UpgradePublic *SectorUpgradePublic
 }
 
+// ProvableOptions tunes a single run of the provable checks,
+// zero values mean using the ones from the proving config.
+type ProvableOptions struct {
+	// Maximum number of sector checks to run in parallel
+	ParallelCheckLimit int
+	// Skip the sectors already declared faulty on chain, they won't be reported as bad
+	SkipFaulty bool
+	// Maximum amount of time the check of a single sector can take, 0 means no limit.
+	// The sectors not checked in time, e.g. on a stuck mount, are reported as bad with ProvableTimeoutReason
+	SectorTimeout time.Duration
+}
+
+// ProvableTimeoutReason prefixes the reasons of the sectors whose provable checks timed out,
+// telling them from the genuine failures.
+const ProvableTimeoutReason = "check timed out"
+
+// The errors returned by SectorProving.SingleProvable & ProvableErrs are tagged with one of these, so that the callers
+// could tell the kinds of the failures with errors.Is.
+var (
+	ErrProvableNotIndexed    = fmt.Errorf("sector not indexed")
+	ErrProvableMissingFile   = fmt.Errorf("sector file missing")
+	ErrProvableWrongSize     = fmt.Errorf("sector file with wrong size")
+	ErrProvableStateMismatch = fmt.Errorf("sector state inconsistent with chain")
+	ErrProvableProofFailed   = fmt.Errorf("vanilla proof failed")
+	ErrProvableTimeout       = fmt.Errorf("sector check timed out")
+)
+
+const (
+	SectorEventSealed           = "sealed"
+	SectorEventFinalized        = "finalized"
+	SectorEventRemoved          = "removed"
+	SectorEventRebuildScheduled = "rebuild-scheduled"
+	SectorEventTerminated       = "terminated"
+)
+
+// SectorEvent is posted to the sector event webhook on the lifecycle transitions of the sectors.
+type SectorEvent struct {
+	ID        abi.SectorID
+	State     string
+	Timestamp time.Time
+}
+
+// ProvableRequest is a batch of sectors of a miner to be checked in CheckProvableMulti.
+type ProvableRequest struct {
+	Miner         abi.ActorID
+	PostProofType abi.RegisteredPoStProof
+	Sectors       []builtin.ExtendedSectorInfo
+	Strict        bool
+	StateCheck    bool
+	Opts          ProvableOptions
+}
+
+// ProvableResult holds the bad sectors of a miner, Err is set if the check can not be done.
+type ProvableResult struct {
+	Bad map[abi.SectorNumber]string
+	Err string `json:",omitempty"`
+}
+
+// ProvabilityFault is a sector failed in the provable check, Category is derived from the Reason.
+type ProvabilityFault struct {
+	Sector   abi.SectorNumber
+	Category string
+	Reason   string
+}
+
+// DeadlineProvability holds the faults found in the live sectors of a deadline, grouped by category.
+type DeadlineProvability struct {
+	Deadline uint64
+	Checked  int
+	Faults   map[string][]ProvabilityFault
+}
+
+type ProvabilityReport struct {
+	Miner       abi.ActorID
+	GeneratedAt int64
+	Checked     int
+	Faulty      int
+	Deadlines   []DeadlineProvability
+}
+
+// MinerSectorsReconciliation compares the local sector states of a miner with its on-chain sectors.
+type MinerSectorsReconciliation struct {
+	Miner abi.ActorID
+	// OnChainOnly holds the on-chain sectors without local states, or whose local states have been removed
+	OnChainOnly bitfield.BitField
+	// LocalOnly holds the sectors with local states but not on chain
+	LocalOnly bitfield.BitField
+	Both      bitfield.BitField
+}
+
+// ListSectorsOptions filters the sectors while scanning the states, zero value means no filter.
+type ListSectorsOptions struct {
+	// Aborted only keeps the sectors with an abort reason
+	Aborted bool
+	// AbortReasonContains only keeps the sectors whose abort reason contains it, implies Aborted
+	AbortReasonContains string
+	// Labels only keeps the sectors having all the given labels
+	Labels map[string]string
+}
+
+// IsEmpty tells if no filter is set.
+func (opts ListSectorsOptions) IsEmpty() bool {
+	return !opts.Aborted && opts.AbortReasonContains == "" && len(opts.Labels) == 0
+}
+
+func (opts ListSectorsOptions) Match(st *SectorState) bool {
+	if opts.Aborted || opts.AbortReasonContains != "" {
+		if st.AbortReason == "" || !strings.Contains(st.AbortReason, opts.AbortReasonContains) {
+			return false
+		}
+	}
+
+	for k, v := range opts.Labels {
+		if val, ok := st.Labels[k]; !ok || val != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SealedFileSizeMismatch describes a sealed file whose size differs from the expected one,
+// Err is set if the file can not be stat-ed.
+type SealedFileSizeMismatch struct {
+	ID       abi.SectorID
+	Instance string
+	Path     string
+	Expected int64
+	Actual   int64
+	Err      string
+}
+
+// StuckSector is a sector staying in the same sealing state for too long.
+type StuckSector struct {
+	ID        abi.SectorID
+	State     string
+	Worker    string
+	EnteredAt int64
+	StuckFor  time.Duration
+}
+
+// HealthStatus is the result of checking a dependency, Err is set if unhealthy.
+type HealthStatus struct {
+	Name    string
+	Healthy bool
+	Err     string `json:",omitempty"`
+	Elapsed time.Duration
+}
+
+// HealthReport holds the status of each dependency of the sealer,
+// Healthy is set only if all of them are healthy.
+type HealthReport struct {
+	Healthy    bool
+	Chain      HealthStatus
+	Messager   HealthStatus
+	StateStore HealthStatus
+	Stores     []HealthStatus
+}
+
+// SectorFilePath is the full path of a sector file in the store instance.
+type SectorFilePath struct {
+	Instance string
+	Path     string
+}
+
+// PieceVerification is the result of checking the piece data in the piece stores against its piece cid.
+type PieceVerification struct {
+	PieceCid cid.Cid
+	Match    bool
+	// Computed is the piece cid computed from the stored data, only set on mismatch
+	Computed    *cid.Cid
+	PayloadSize int64
+	PieceSize   abi.PaddedPieceSize
+}
+
+// LocalPiece is a piece found in the local piece stores.
+type LocalPiece struct {
+	PieceCid cid.Cid
+	Size     int64
+	Store    string
+	Key      string
+}
+
+// ProverInfo describes the prover backend in use.
+type ProverInfo struct {
+	// `prod` or `fake`, fixed at build time
+	Backend    string
+	Production bool
+	Version    string
+	// the type of the prover implementation, e.g. the ext or the worker prover
+	Implementation string
+	// the gpu devices found on the host of damocles-manager, empty if none is detected
+	GPUs []string
+}
+
+type RestoreSectorOptions struct {
+	Forced bool
+	// TargetStore, if set, is the store instance holding the files of the restored sector,
+	// which replaces the one in the sector index. Used when recovering the sector onto another disk.
+	TargetStore string
+}
+
+// SectorRestoreResult is the result of restoring a sector in RestoreAborted, Err is empty on success.
+type SectorRestoreResult struct {
+	ID  abi.SectorID
+	Err string
+}
+
+// SectorPieceLocation tells where a piece sits within the sector, the padding pieces come without deals.
+type SectorPieceLocation struct {
+	Cid    cid.Cid
+	Size   abi.PaddedPieceSize
+	Offset abi.PaddedPieceSize
+	IsDeal bool
+	// display form of the deal id or allocation id, empty for the padding pieces
+	Deal string
+}
+
+// SectorPaths holds the paths of the sector files, the ones not indexed are nil.
+// Update & UpdateCache are only resolved for the upgraded sectors.
+type SectorPaths struct {
+	ID          abi.SectorID
+	Upgraded    bool
+	Sealed      *SectorFilePath
+	Cache       *SectorFilePath
+	Update      *SectorFilePath
+	UpdateCache *SectorFilePath
+}
+
+// SectorRemovability tells if the files of a sector can be removed at the current Head,
+// AllowedAt is the epoch since which the removal is allowed, 0 for the sectors not terminated.
+type SectorRemovability struct {
+	Allowed   bool
+	Removed   bool
+	Head      abi.ChainEpoch
+	AllowedAt abi.ChainEpoch
+}
+
+type SectorCacheCheckResult struct {
+	// Missing lists the cache artifacts which are missing or empty
+	Missing []string
+}
+
+type SectorCacheRepairResult struct {
+	// Regenerating lists the missing or empty cache artifacts, which are being regenerated by the rebuild
+	// scheduled for the sector, the progress can be followed with RebuildProgress.
+	// Empty if the cache artifacts are intact, and no rebuild is scheduled in that case.
+	Regenerating []string
+}
+
+type SectorExpirationInfo struct {
+	ID         abi.SectorID
+	Expiration abi.ChainEpoch
+}
+
+type SealedDealInfo struct {
+	DealID abi.DealID
+	Sector abi.SectorNumber
+}
+
+type DealStatsEntry struct {
+	Deals int
+	Bytes abi.PaddedPieceSize
+}
+
+// DealStats aggregates the deals sealed in the sectors which are not removed.
+type DealStats struct {
+	DealStatsEntry
+	Sectors    int
+	Verified   DealStatsEntry
+	Unverified DealStatsEntry
+}
+
+type SealDurationSummary struct {
+	Count  int
+	Min    time.Duration
+	Max    time.Duration
+	Median time.Duration
+	P95    time.Duration
+}
+
+// SealDurationStats summarizes the durations from initialization to finalization of the sealed sectors.
+type SealDurationStats struct {
+	SealDurationSummary
+	ByProofType map[abi.RegisteredSealProof]SealDurationSummary
+}
+
+type SectorRebuildStatus struct {
+	ID abi.SectorID
+	// Info will be nil if the rebuild task has already been allocated
+	Info *SectorRebuildInfo
+}
+
+type RebuildPhase string
+
+const (
+	RebuildPhasePending   RebuildPhase = "Pending"
+	RebuildPhasePiece     RebuildPhase = "Piece"
+	RebuildPhaseTreeD     RebuildPhase = "TreeD"
+	RebuildPhasePC1       RebuildPhase = "PC1"
+	RebuildPhaseTreeC     RebuildPhase = "TreeC"
+	RebuildPhaseSealed    RebuildPhase = "Sealed"
+	RebuildPhaseSnapUp    RebuildPhase = "SnapUp"
+	RebuildPhasePersisted RebuildPhase = "Persisted"
+	RebuildPhaseFinalized RebuildPhase = "Finalized"
+)
+
+type rebuildStage struct {
+	phase   RebuildPhase
+	percent int
+}
+
+// rebuildStages maps the states reported by the rebuild planner of damocles-worker to the rebuild progress.
+var rebuildStages = map[string]rebuildStage{
+	"Empty":                {RebuildPhasePending, 0},
+	"Allocated":            {RebuildPhasePiece, 5},
+	"PieceAdded":           {RebuildPhaseTreeD, 10},
+	"TreeDBuilt":           {RebuildPhasePC1, 15},
+	"PC1Done":              {RebuildPhaseTreeC, 55},
+	"SyntheticPoRepNeeded": {RebuildPhaseTreeC, 60},
+	"PC2Done":              {RebuildPhaseSealed, 70},
+	"SealedChecked":        {RebuildPhaseSnapUp, 75},
+	"SnapPieceAdded":       {RebuildPhaseSnapUp, 78},
+	"SnapTreeDBuilt":       {RebuildPhaseSnapUp, 80},
+	"SnapEncoded":          {RebuildPhaseSnapUp, 85},
+	"SnapDone":             {RebuildPhasePersisted, 90},
+	"Persisted":            {RebuildPhaseFinalized, 95},
+	"Finished":             {RebuildPhaseFinalized, 100},
+}
+
+// SectorUpgradeValidation is the result of cross-checking the upgrade info of a snapup sector against the chain,
+// Mismatches is empty if the stored info is consistent.
+type SectorUpgradeValidation struct {
+	ID           abi.SectorID
+	SectorKeyCID *cid.Cid
+	SealedCID    cid.Cid
+	Mismatches   []string
+}
+
+// SectorRebuildProgress describes the progress of a sector rebuild task,
+// Phase is the one being executed, State is the latest state reported by the worker.
+type SectorRebuildProgress struct {
+	ID      abi.SectorID
+	Phase   RebuildPhase
+	State   string
+	Percent int
+}
+
+// RebuildProgressOf derives the rebuild progress from the latest state reported by the worker.
+func RebuildProgressOf(sid abi.SectorID, latest *ReportStateReq) SectorRebuildProgress {
+	progress := SectorRebuildProgress{
+		ID:    sid,
+		Phase: RebuildPhasePending,
+	}
+
+	if latest == nil {
+		return progress
+	}
+
+	progress.State = latest.StateChange.Next
+	if stage, ok := rebuildStages[progress.State]; ok {
+		progress.Phase = stage.phase
+		progress.Percent = stage.percent
+	}
+
+	return progress
+}
+
 type UnsealTaskIdentifier struct {
 	PieceCid     cid.Cid
 	Actor        abi.ActorID
@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/ipfs/go-cid"
@@ -24,8 +26,21 @@ type (
 	SectorUpgradePublic      SectorPublicInfo
 	SectorNeedRebuild        bool
 	SectorUnsealing          bool
+	SectorCreatedAt          int64
+	SectorFinalizedAt        int64
+	SectorStateEnteredAt     int64
+	SectorUpdatedAt          int64
+	SectorLabels             map[string]string
+	SectorRetries            map[string]SectorRetry
 )
 
+// SectorRetry counts the failures reported in a state, each of them leads to a retry of the state by the worker.
+type SectorRetry struct {
+	Count int
+	// unix timestamp of the latest failure
+	LastRetryAt int64
+}
+
 type SectorUpgradedInfo struct {
 	AccessInstance string
 	SealedCID      cid.Cid
@@ -69,6 +84,21 @@ type SectorState struct {
 
 	// Unseal
 	Unsealing SectorUnsealing
+
+	// unix timestamps in seconds of the initialization & the first finalization, 0 if unknown
+	CreatedAt   SectorCreatedAt   `json:",omitempty"`
+	FinalizedAt SectorFinalizedAt `json:",omitempty"`
+	// unix timestamp in seconds of entering the state in LatestState, 0 if unknown
+	StateEnteredAt SectorStateEnteredAt `json:",omitempty"`
+
+	// operational labels set by the users, e.g. batch=2024-01
+	Labels SectorLabels `json:",omitempty"`
+
+	// retries of the sealing states, keyed by the state name
+	Retries SectorRetries `json:",omitempty"`
+
+	// unix timestamp in seconds of the latest change of the state, 0 if unknown
+	UpdatedAt SectorUpdatedAt `json:",omitempty"`
 }
 
 // TODO: we need iter
@@ -171,6 +201,55 @@ func (s *SectorState) MatchWorkerJob(jtyp SectorWorkerJob) bool {
 	}
 }
 
+// Validate checks the fields required by the downstream operations, like proving & rebuilding,
+// and returns all the problems found. It is meant for the sector states from external sources, e.g. migrations.
+func (s *SectorState) Validate() []error {
+	var errs []error
+	if s.ID.Miner == 0 {
+		errs = append(errs, fmt.Errorf("miner actor id is not set"))
+	}
+
+	if _, err := s.SectorType.SectorSize(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid sector type %d: %w", s.SectorType, err))
+	}
+
+	if !s.Finalized {
+		return errs
+	}
+
+	if s.Ticket == nil || len(s.Ticket.Ticket) == 0 {
+		errs = append(errs, fmt.Errorf("ticket is required for sealed sectors"))
+	}
+
+	if s.Pre == nil {
+		errs = append(errs, fmt.Errorf("pre-commit info is required for sealed sectors"))
+	} else {
+		if !s.Pre.CommR.Defined() {
+			errs = append(errs, fmt.Errorf("comm_r is not set in pre-commit info"))
+		}
+
+		if s.Ticket != nil && len(s.Pre.Ticket.Ticket) > 0 && s.Pre.Ticket.Epoch != s.Ticket.Epoch {
+			errs = append(errs, fmt.Errorf(
+				"ticket epoch %d differs from the one %d in pre-commit info",
+				s.Ticket.Epoch,
+				s.Pre.Ticket.Epoch,
+			))
+		}
+	}
+
+	if s.Upgraded {
+		if s.UpgradedInfo == nil {
+			errs = append(errs, fmt.Errorf("upgraded info is required for upgraded sectors"))
+		} else if !s.UpgradedInfo.SealedCID.Defined() {
+			errs = append(errs, fmt.Errorf("sealed cid is not set in upgraded info"))
+		}
+	} else if s.UpgradedInfo != nil || s.UpgradeLandedEpoch != nil {
+		errs = append(errs, fmt.Errorf("upgrade info is set for a sector not marked as upgraded"))
+	}
+
+	return errs
+}
+
 type SectorWorkerState string
 
 const (
@@ -178,6 +257,68 @@ const (
 	WorkerOffline SectorWorkerState = "offline"
 )
 
+// SectorWorkerStateTransition is a move of the sector states between the worker states.
+// The From of the initial transitions is empty.
+type SectorWorkerStateTransition struct {
+	Name string
+	From SectorWorkerState
+	To   SectorWorkerState
+	// the apis triggering the transition
+	Via []string
+}
+
+// SectorWorkerStateTransitions documents the moves made by the SectorStateManager, it is used for
+// rendering the state graph only and is not consulted by the state manager itself,
+// so it should be kept in sync by hand when the apis change.
+var SectorWorkerStateTransitions = []SectorWorkerStateTransition{
+	{
+		Name: "allocate",
+		To:   WorkerOnline,
+		Via:  []string{"AllocateSector", "AllocateSanpUpSector"},
+	},
+	// sectors could also be imported into the online store on demand
+	{
+		Name: "import",
+		To:   WorkerOffline,
+		Via:  []string{"ImportSector"},
+	},
+	{
+		Name: "finalize",
+		From: WorkerOnline,
+		To:   WorkerOffline,
+		Via:  []string{"ReportFinalized", "ReportAborted", "FinalizeSector", "AbandonSector", "SectorSetForRebuild"},
+	},
+	{
+		Name: "restore",
+		From: WorkerOffline,
+		To:   WorkerOnline,
+		Via: []string{
+			"RestoreSector", "RestoreSectorEx", "RestoreAborted", "SectorSetForRebuild",
+			"AllocateSanpUpSector", "AllocateUnsealSector",
+		},
+	},
+}
+
+// SectorReplayStage is a stage of the sealing pipeline which a stuck sector could be sent back to,
+// the states produced in & after the stage are cleared, so that the worker redoes the stage.
+type SectorReplayStage string
+
+const (
+	// the pre commit info & everything after are cleared
+	SectorReplayPreCommit SectorReplayStage = "pre-commit"
+	// the seed & everything after are cleared
+	SectorReplaySeed SectorReplayStage = "seed"
+	// the proof & the commit message are cleared
+	SectorReplayProveCommit SectorReplayStage = "prove-commit"
+)
+
+// SectorReplayStages are the only stages allowed to be replayed.
+var SectorReplayStages = []SectorReplayStage{
+	SectorReplayPreCommit,
+	SectorReplaySeed,
+	SectorReplayProveCommit,
+}
+
 type SectorWorkerJob int
 
 const (
@@ -187,3 +328,63 @@ const (
 	SectorWorkerJobRebuild SectorWorkerJob = 3
 	SectorWorkerJobUnseal  SectorWorkerJob = 4
 )
+
+// SectorStateFieldDiff describes a changed field of the sector state, Field is the dotted path, e.g. `Ticket.Epoch`.
+type SectorStateFieldDiff struct {
+	Field string
+	Old   any
+	New   any
+}
+
+// DiffSectorState returns the fields changed from before to after, in the order of declaration.
+// Nested structs defined in this package, like Ticket, Seed and UpgradedInfo, are compared field by field.
+func DiffSectorState(before, after *SectorState) []SectorStateFieldDiff {
+	if before == nil {
+		before = &SectorState{}
+	}
+
+	if after == nil {
+		after = &SectorState{}
+	}
+
+	var diffs []SectorStateFieldDiff
+	diffValue("", reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem(), &diffs)
+	return diffs
+}
+
+var corePkgPath = reflect.TypeOf(SectorState{}).PkgPath()
+
+func diffValue(path string, before, after reflect.Value, diffs *[]SectorStateFieldDiff) {
+	typ := before.Type()
+	switch {
+	case typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct && typ.Elem().PkgPath() == corePkgPath:
+		if before.IsNil() || after.IsNil() {
+			if before.IsNil() != after.IsNil() {
+				*diffs = append(*diffs, SectorStateFieldDiff{Field: path, Old: before.Interface(), New: after.Interface()})
+			}
+			return
+		}
+
+		diffValue(path, before.Elem(), after.Elem(), diffs)
+
+	case typ.Kind() == reflect.Struct && typ.PkgPath() == corePkgPath:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+
+			diffValue(fieldPath, before.Field(i), after.Field(i), diffs)
+		}
+
+	default:
+		if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+			*diffs = append(*diffs, SectorStateFieldDiff{Field: path, Old: before.Interface(), New: after.Interface()})
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSectorState(t *testing.T) {
+	before := &SectorState{
+		ID:     abi.SectorID{Miner: 1000, Number: 1},
+		Ticket: &Ticket{Ticket: abi.Randomness{1, 2, 3}, Epoch: 100},
+	}
+
+	require.Empty(t, DiffSectorState(before, before))
+
+	after := *before
+	after.Ticket = &Ticket{Ticket: abi.Randomness{1, 2, 3}, Epoch: 200}
+	after.Seed = &Seed{Seed: abi.Randomness{4, 5, 6}, Epoch: 300}
+	after.Finalized = true
+	after.UpgradedInfo = &SectorUpgradedInfo{AccessInstance: "store"}
+
+	diffs := DiffSectorState(before, &after)
+	fields := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		fields = append(fields, d.Field)
+	}
+
+	require.Equal(t, []string{"Ticket.Epoch", "Seed", "Finalized", "UpgradedInfo"}, fields)
+	require.Equal(t, abi.ChainEpoch(100), diffs[0].Old)
+	require.Equal(t, abi.ChainEpoch(200), diffs[0].New)
+	require.Nil(t, diffs[1].Old.(*Seed))
+	require.Equal(t, SectorFinalized(true), diffs[2].New)
+}
+
+func TestSectorStateValidate(t *testing.T) {
+	state := &SectorState{
+		ID:         abi.SectorID{Miner: 1000, Number: 1},
+		SectorType: abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+	}
+	require.Empty(t, state.Validate(), "sectors still sealing")
+
+	state.Finalized = true
+	require.Len(t, state.Validate(), 2, "ticket & pre-commit info missing")
+
+	ticket := Ticket{Ticket: abi.Randomness{1, 2, 3}, Epoch: 100}
+	state.Ticket = &ticket
+	state.Pre = &PreCommitInfo{CommR: cid.NewCidV1(cid.Raw, []byte{1}), Ticket: ticket}
+	require.Empty(t, state.Validate())
+
+	state.Upgraded = true
+	require.Len(t, state.Validate(), 1, "upgraded info missing")
+
+	state.SectorType = abi.RegisteredSealProof(-1)
+	state.ID.Miner = 0
+	require.Len(t, state.Validate(), 3)
+}
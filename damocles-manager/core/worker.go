@@ -61,6 +61,13 @@ func (s WorkerInfoSummary) Map() map[string]uint {
 type WorkerPingInfo struct {
 	Info     WorkerInfo
 	LastPing int64
+	// Online is computed from LastPing when listing the workers
+	Online bool
+}
+
+// IsOnline tells if the worker has pinged within the ping interval plus the grace period.
+func (w WorkerPingInfo) IsOnline(now time.Time, pingInterval, grace time.Duration) bool {
+	return now.Sub(time.Unix(w.LastPing, 0)) <= pingInterval+grace
 }
 
 type WorkerInfo struct {
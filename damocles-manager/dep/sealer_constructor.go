@@ -23,6 +23,7 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/impl/sectors"
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/impl/worker"
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/policy"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/sealer"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/chain"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/confmgr"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/homedir"
@@ -597,7 +598,7 @@ func BuildSectorProving(
 	state core.SectorStateManager,
 	storeMgr PersistedObjectStoreManager,
 	prover core.Prover,
-	capi chain.API,
+	capi sealer.LimitedChainAPI,
 	scfg *modules.SafeConfig,
 ) (core.SectorProving, error) {
 	return sectors.NewProving(tracker, state, storeMgr, prover, capi, scfg.MustCommonConfig().Proving)
@@ -670,6 +671,7 @@ func BuildMarketAPIRelated(
 
 	scfg.Lock()
 	pieceStoreCfg := scfg.Common.PieceStores
+	proxyCfg := pieceStoreProxyConfig(scfg.Common.PieceStoreProxy)
 	scfg.Unlock()
 
 	stores := make([]objstore.Store, 0, len(pieceStoreCfg))
@@ -693,10 +695,31 @@ func BuildMarketAPIRelated(
 		stores = append(stores, st)
 	}
 
-	proxy := piecestore.NewProxy(stores, mapi)
+	if (proxyCfg.TLSCertFile == "") != (proxyCfg.TLSKeyFile == "") {
+		return MarketAPIRelatedComponents{}, fmt.Errorf(
+			"both or neither of the tls cert & key files of the piecestore proxy should be set",
+		)
+	}
+
+	proxy := piecestore.NewProxy(stores, mapi, proxyCfg)
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			proxy.Close()
+			return nil
+		},
+	})
+
 	http.DefaultServeMux.Handle(HTTPEndpointPiecestore, http.StripPrefix(HTTPEndpointPiecestore, proxy))
 	log.Info("piecestore proxy has been registered into default mux")
 
+	if proxyCfg.Listen != "" {
+		go func() {
+			if err := proxy.Serve(gctx); err != nil {
+				log.Errorf("standalone piecestore proxy: %s", err)
+			}
+		}()
+	}
+
 	return MarketAPIRelatedComponents{
 		DealManager: dealmgr.New(mapi, minerAPI, scfg),
 		MarketAPI:   mapi,
@@ -704,6 +727,34 @@ func BuildMarketAPIRelated(
 	}, nil
 }
 
+func pieceStoreProxyConfig(cfg modules.PieceStoreProxyConfig) piecestore.ProxyConfig {
+	return piecestore.ProxyConfig{
+		PublicBaseURL:             cfg.PublicBaseURL,
+		TrustForwardedHeaders:     cfg.TrustForwardedHeaders,
+		Replicas:                  cfg.Replicas,
+		ReplicaConcurrency:        cfg.ReplicaConcurrency,
+		RequiredReplicas:          cfg.RequiredReplicas,
+		ReadTimeout:               cfg.ReadTimeout.Std(),
+		AccessLog:                 cfg.AccessLog,
+		PieceIndex:                cfg.PieceIndex,
+		PieceIndexRefreshInterval: cfg.PieceIndexRefreshInterval.Std(),
+		RedirectStatus:            cfg.RedirectStatus,
+		UploadDir:                 cfg.UploadDir,
+		SelfURLs:                  cfg.SelfURLs,
+		DetectSelfByRequestHost:   cfg.DetectSelfByRequestHost,
+		ShardChars:                cfg.ShardChars,
+		TransferRateLimit:         cfg.TransferRateLimit,
+		AggregateRateLimit:        cfg.AggregateRateLimit,
+		AuthToken:                 cfg.AuthToken,
+		AuthReads:                 cfg.AuthReads,
+		Listen:                    cfg.Listen,
+		TLSCertFile:               cfg.TLSCertFile,
+		TLSKeyFile:                cfg.TLSKeyFile,
+		UploadSessionTimeout:      cfg.UploadSessionTimeout.Std(),
+		MaxUploadSize:             cfg.MaxUploadSize,
+	}
+}
+
 func BuildChainEventBus(
 	gctx GlobalContext,
 	lc fx.Lifecycle,
@@ -3,6 +3,7 @@ package modules
 import (
 	"bytes"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/messager"
 	"github.com/samber/lo"
 
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/confmgr"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/logging"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
@@ -184,15 +186,23 @@ type ProvingConfig struct {
 	// related to this partition are blocked or slow
 	PartitionCheckTimeout Duration
 
+	// Maximum number of simulated WindowPoSt runs at the same time, excess requests will be rejected. (0 = unlimited)
+	SimulateWdPoStLimit int
+
+	// Maximum number of miners checked at the same time in CheckProvableMulti. (0 = unlimited)
+	ParallelMinerCheckLimit int
+
 	WorkerProver *WorkerProverConfig
 }
 
 func defaultProvingConfig() ProvingConfig {
 	cfg := ProvingConfig{
-		ParallelCheckLimit:    128,
-		PartitionCheckTimeout: Duration(20 * time.Minute),
-		SingleCheckTimeout:    Duration(10 * time.Minute),
-		WorkerProver:          DefaultWorkerProverConfig(),
+		ParallelCheckLimit:      128,
+		PartitionCheckTimeout:   Duration(20 * time.Minute),
+		SingleCheckTimeout:      Duration(10 * time.Minute),
+		SimulateWdPoStLimit:     1,
+		ParallelMinerCheckLimit: 2,
+		WorkerProver:            DefaultWorkerProverConfig(),
 	}
 	return cfg
 }
@@ -216,10 +226,166 @@ func DefaultWorkerProverConfig() *WorkerProverConfig {
 	}
 }
 
+type WorkerRegistryConfig struct {
+	// Whether to accept the pings from unknown workers and register them automatically.
+	// If disabled, workers should be registered manually before they are able to ping.
+	AutoRegister bool
+	// The interval the workers ping at, should be in accordance with `worker.ping_interval` of the workers.
+	PingInterval Duration
+	// A worker is regarded as offline only after missing its pings for longer than PingInterval plus the grace period,
+	// to avoid flapping on the transient network failures.
+	OfflineGracePeriod Duration
+}
+
+func defaultWorkerRegistryConfig() WorkerRegistryConfig {
+	return WorkerRegistryConfig{
+		AutoRegister:       true,
+		PingInterval:       Duration(30 * time.Second),
+		OfflineGracePeriod: Duration(60 * time.Second),
+	}
+}
+
+type SectorEventWebhookConfig struct {
+	// The url to POST the sector lifecycle events to, the webhook is disabled if empty
+	URL string
+	// Timeout of each delivery attempt
+	Timeout Duration
+	// Maximum number of retries after a failed delivery, the event will be dropped after that
+	MaxRetry int
+}
+
+func defaultSectorEventWebhookConfig() SectorEventWebhookConfig {
+	return SectorEventWebhookConfig{
+		Timeout:  Duration(10 * time.Second),
+		MaxRetry: 3,
+	}
+}
+
+type ChainCallRateLimitConfig struct {
+	// Maximum number of the chain-heavy calls per second, e.g. listing the sectors or partitions of a miner,
+	// the limit is disabled if not positive
+	Rate float64
+	// Maximum number of calls could be made in a burst
+	Burst int
+	// Limit the calls of each miner separately instead of globally
+	PerMiner bool
+}
+
+func defaultChainCallRateLimitConfig() ChainCallRateLimitConfig {
+	return ChainCallRateLimitConfig{
+		Burst: 10,
+	}
+}
+
+// PieceStoreProxyConfig controls the behaviours of the piece store proxy.
+type PieceStoreProxyConfig struct {
+	// PublicBaseURL, if set, replaces the scheme & host of the redirect targets,
+	// e.g. `https://pieces.example.com`.
+	PublicBaseURL string
+
+	// TrustForwardedHeaders makes the proxy rewrite the redirect targets based on the
+	// `X-Forwarded-Proto` & `X-Forwarded-Host` headers when PublicBaseURL is not set.
+	// Only enable this when the proxy sits behind a trusted reverse proxy.
+	TrustForwardedHeaders bool
+
+	// Replicas is the number of writable stores each piece will be written into, at least 1.
+	// The PUT request succeeds as long as any of the writes succeeds.
+	Replicas int
+
+	// ReplicaConcurrency is the max number of the stores written at the same time, to avoid saturating the disk io.
+	// The uploaded data is teed into that many stores, the rest are copied from a store holding the piece afterwards.
+	// 1 will be used if not set.
+	ReplicaConcurrency int
+
+	// RequiredReplicas is the number of the successful writes the PUT request waits for, capped to Replicas,
+	// the other writes keep running in the background. 1 will be used if not set.
+	RequiredReplicas int
+
+	// ReadTimeout is the timeout of opening the piece data in each local store for the GET requests,
+	// a store that exceeds it will be skipped. It applies to each read of the opened piece data as well,
+	// a stalled read fails the request. 0 means no timeout.
+	ReadTimeout Duration
+
+	// AccessLog enables the access log of the GET requests, emitted after each request completes,
+	// with the client address, the source which served the piece, and the bytes transferred.
+	AccessLog bool
+
+	// PieceIndex enables the in-memory index of the pieces in the local stores, built by listing the stores
+	// at startup, so that the GET requests don't have to probe every store, and the misses are redirected
+	// without touching the disks.
+	PieceIndex bool
+
+	// PieceIndexRefreshInterval is the interval of rebuilding the piece index, to catch up with the changes
+	// made out of the proxy. 0 means never.
+	PieceIndexRefreshInterval Duration
+
+	// RedirectStatus is the status code of the redirect responses for the pieces not found locally,
+	// one of 301, 302, 303, 307 and 308, 302 will be used if not set.
+	RedirectStatus int
+
+	// UploadDir is the directory holding the partial data of the resumable uploads,
+	// the default temp dir will be used if empty.
+	UploadDir string
+
+	// SelfURLs are the base urls through which the proxy itself is reachable, e.g. `http://10.0.0.1:9999`,
+	// the redirects targeting any of them, or the Listen address, are refused with 502 to break the loops
+	// formed by misconfigured proxies redirecting to each other.
+	SelfURLs []string
+
+	// DetectSelfByRequestHost makes the redirects targeting the host of the request regarded as loops as well.
+	// Don't enable it if the market service is reachable through the same host, e.g. behind a shared gateway.
+	DetectSelfByRequestHost bool
+
+	// ShardChars, if positive, is the number of the trailing chars of the piece cid used as the name of the sub dir
+	// the piece is stored in, to keep the number of files in each dir manageable. The pieces stored in the root dir
+	// before enabling it are still readable.
+	ShardChars int
+
+	// TransferRateLimit is the bandwidth limit of each download or upload, in bytes per second, 0 means no limit.
+	TransferRateLimit int64
+
+	// AggregateRateLimit is the bandwidth limit of all the downloads and uploads in total, in bytes per second,
+	// 0 means no limit.
+	AggregateRateLimit int64
+
+	// AuthToken, if set, is the bearer token required by the upload requests, via the `Authorization` header.
+	AuthToken string
+
+	// AuthReads makes the download requests require the AuthToken as well.
+	AuthReads bool
+
+	// Listen, if set, is the address of a standalone http server serving the proxy only,
+	// in addition to the one mounted on the api server of damocles-manager.
+	Listen string
+
+	// TLSCertFile & TLSKeyFile enable tls on the standalone http server, both or neither should be set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// UploadSessionTimeout is the duration after which an inactive resumable upload session will be
+	// cleaned up along with its partial data, 1h will be used if not set.
+	UploadSessionTimeout Duration
+
+	// MaxUploadSize is the max total size declared by a resumable upload, in bytes,
+	// 64GiB will be used if not set.
+	MaxUploadSize int64
+}
+
+func defaultPieceStoreProxyConfig() PieceStoreProxyConfig {
+	return PieceStoreProxyConfig{
+		Replicas:           1,
+		ReplicaConcurrency: 2,
+		RequiredReplicas:   1,
+		RedirectStatus:     http.StatusFound,
+	}
+}
+
 type CommonConfig struct {
 	API         CommonAPIConfig
 	Plugins     *PluginConfig
 	PieceStores []PieceStoreConfig
+	// PieceStoreProxy configures the http proxy in front of the piece stores
+	PieceStoreProxy PieceStoreProxyConfig
 
 	// PersistStores should not be used directly, use GetPersistStores instead
 	PersistStores []PersistStoreConfig
@@ -227,9 +393,20 @@ type CommonConfig struct {
 	// supporting `glob` mode.
 	ScanPersistStores []string
 
+	// AllowDestructiveOpsWithFakeProver forces the destructive operations, e.g. terminating or removing sectors,
+	// to run on the builds with the fake prover, which are refused by default.
+	AllowDestructiveOpsWithFakeProver bool
+
 	MongoKVStore *KVStoreMongoDBConfig // For compatibility with v0.5
 	DB           *DBConfig
 	Proving      ProvingConfig
+
+	WorkerRegistry WorkerRegistryConfig
+
+	SectorEventWebhook SectorEventWebhookConfig
+
+	// ChainCallRateLimit throttles the chain-heavy calls made by the sealer apis and the provable checks
+	ChainCallRateLimit ChainCallRateLimitConfig
 }
 
 func (c CommonConfig) GetPersistStores() (cfgs []PersistStoreConfig, err error) {
@@ -254,6 +431,10 @@ func (c CommonConfig) GetPersistStores() (cfgs []PersistStoreConfig, err error)
 			return nil, fmt.Errorf("duplicate persist store name %s", cfgs[i].Name)
 		}
 		checkName[cfgs[i].Name] = struct{}{}
+
+		if _, err := util.ParseSectorPathLayout(cfgs[i].Meta); err != nil {
+			return nil, fmt.Errorf("invalid sector path templates of persist store %s: %w", cfgs[i].Name, err)
+		}
 	}
 
 	return
@@ -280,11 +461,16 @@ func defaultCommonConfig(example bool) CommonConfig {
 	cfg := CommonConfig{
 		API:               defaultCommonAPIConfig(example),
 		PieceStores:       []PieceStoreConfig{},
+		PieceStoreProxy:   defaultPieceStoreProxyConfig(),
 		PersistStores:     []PersistStoreConfig{},
 		ScanPersistStores: []string{},
 		MongoKVStore:      nil,
 		DB:                DefaultDBConfig(),
 		Proving:           defaultProvingConfig(),
+		WorkerRegistry:    defaultWorkerRegistryConfig(),
+
+		SectorEventWebhook: defaultSectorEventWebhookConfig(),
+		ChainCallRateLimit: defaultChainCallRateLimitConfig(),
 	}
 
 	if example {
@@ -512,6 +698,9 @@ type MinerCommitmentBatchPolicyConfig struct {
 	Threshold               int
 	MaxWait                 Duration
 	CheckInterval           Duration
+	// MaxSectorsPerMessage limits the sectors addressed by a single batched message, only used by Terminate for now,
+	// 0 means the network limit
+	MaxSectorsPerMessage uint64
 	FeeConfig
 }
 
@@ -521,6 +710,7 @@ func defaultMinerCommitmentBatchPolicyConfig() MinerCommitmentBatchPolicyConfig
 		Threshold:               16,
 		MaxWait:                 Duration(time.Hour),
 		CheckInterval:           Duration(time.Minute),
+		MaxSectorsPerMessage:    0,
 		FeeConfig:               defaultFeeConfig(),
 	}
 
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/filecoin-project/go-address"
@@ -114,6 +115,26 @@ func TestTomlUnmarshalForFIL(t *testing.T) {
 	})
 }
 
+func TestTomlUnmarshalPieceStoreProxyConfig(t *testing.T) {
+	type A struct {
+		PieceStoreProxy modules.PieceStoreProxyConfig
+	}
+
+	a := A{}
+	buf := bytes.Buffer{}
+	buf.WriteString(`[PieceStoreProxy]
+ReadTimeout = "30s"
+PieceIndexRefreshInterval = "10m"
+UploadSessionTimeout = "1h"
+`)
+	dec := toml.NewDecoder(&buf)
+	_, err := dec.Decode(&a)
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, a.PieceStoreProxy.ReadTimeout.Std())
+	require.Equal(t, 10*time.Minute, a.PieceStoreProxy.PieceIndexRefreshInterval.Std())
+	require.Equal(t, time.Hour, a.PieceStoreProxy.UploadSessionTimeout.Std())
+}
+
 func TestParseFIL(t *testing.T) {
 	t.Run("valid FIL string", func(t *testing.T) {
 		testCases := []struct {
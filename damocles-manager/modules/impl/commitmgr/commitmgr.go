@@ -60,6 +60,9 @@ type CommitmentMgrImpl struct {
 	verif  core.Verifier
 	prover core.Prover
 
+	// serializes the cancellations of the terminations with the pushing of the terminate messages
+	terminateMu sync.Mutex
+
 	stopOnce sync.Once
 	stop     chan struct{}
 }
@@ -128,40 +131,56 @@ func updateSector(
 	plog.Infof("Process sectors %v finished", sectorID)
 }
 
-//revive:disable-next-line:argument-limit
-func pushMessage(
-	ctx context.Context,
+// newMessage constructs the message to the miner actor, with the gas fee cap set as configured.
+func newMessage(
 	from address.Address,
 	mid abi.ActorID,
 	value abi.TokenAmount,
 	method abi.MethodNum,
-	msgClient messager.API,
 	feeCfg *modules.FeeConfig,
 	params []byte,
-	mlog *logging.ZapLogger,
-) (cid.Cid, error) {
+) (types.Message, error) {
 	to, err := address.NewIDAddress(uint64(mid))
 	if err != nil {
-		return cid.Undef, err
+		return types.Message{}, err
 	}
 
-	spec := feeCfg.GetSendSpec()
-	msg := types.Message{
+	return types.Message{
 		To:        to,
 		From:      from,
 		Value:     value,
 		Method:    method,
 		Params:    params,
 		GasFeeCap: feeCfg.GetGasFeeCap().Std(),
+	}, nil
+}
+
+//revive:disable-next-line:argument-limit
+func pushMessage(
+	ctx context.Context,
+	from address.Address,
+	mid abi.ActorID,
+	value abi.TokenAmount,
+	method abi.MethodNum,
+	msgClient messager.API,
+	feeCfg *modules.FeeConfig,
+	params []byte,
+	mlog *logging.ZapLogger,
+) (cid.Cid, error) {
+	msg, err := newMessage(from, mid, value, method, feeCfg, params)
+	if err != nil {
+		return cid.Undef, err
 	}
 
+	spec := feeCfg.GetSendSpec()
+
 	bk, err := msg.ToStorageBlock()
 	if err != nil {
 		return cid.Undef, err
 	}
 	mb := bk.RawData()
 
-	mlog = mlog.With("from", from.String(), "to", to.String(), "method", method, "raw-mcid", bk.Cid())
+	mlog = mlog.With("from", from.String(), "to", msg.To.String(), "method", method, "raw-mcid", bk.Cid())
 
 	var mcid cid.Cid
 
@@ -371,6 +390,12 @@ func (c *CommitmentMgrImpl) pollTerminateState(ctx context.Context, sector *core
 			break
 		}
 
+		// the termination has been cancelled
+		if !sector.PendingForTerminateCommitment() {
+			mlog.Debug("poll finished, not pending for termination")
+			break
+		}
+
 		if sector.TerminateInfo.TerminateCid == nil {
 			continue
 		}
@@ -422,13 +447,7 @@ func (c *CommitmentMgrImpl) startTerminateLoop(ctx context.Context) {
 				continue
 			}
 
-			c.terminateBatcher[miner] = NewBatcher(c.ctx, miner, sender, TerminateProcessor{
-				api:       c.stateMgr,
-				msgClient: c.msgClient,
-				smgr:      c.smgr,
-				config:    c.cfg,
-				prover:    c.prover,
-			}, llog)
+			c.terminateBatcher[miner] = NewBatcher(c.ctx, miner, sender, c.terminateProcessor(), llog)
 		}
 
 		go c.pollTerminateState(ctx, &s)
@@ -831,6 +850,126 @@ func (c *CommitmentMgrImpl) TerminateState(ctx context.Context, sid abi.SectorID
 	return sector.TerminateInfo, nil
 }
 
+func (c *CommitmentMgrImpl) PendingTerminations(
+	ctx context.Context,
+	mid abi.ActorID,
+) ([]core.PendingTermination, error) {
+	sectors, err := c.smgr.All(ctx, core.WorkerOffline, core.SectorWorkerJobAll)
+	if err != nil {
+		return nil, fmt.Errorf("load offline sectors: %w", err)
+	}
+
+	pendings := make([]core.PendingTermination, 0)
+	for _, sector := range sectors {
+		if sector.ID.Miner != mid || !sector.PendingForTerminateCommitment() {
+			continue
+		}
+
+		pending := core.PendingTermination{
+			ID:           sector.ID,
+			AddedHeight:  sector.TerminateInfo.AddedHeight,
+			TerminateCid: sector.TerminateInfo.TerminateCid,
+		}
+
+		if pending.TerminateCid != nil {
+			msg, err := c.msgClient.GetMessageByUid(ctx, pending.TerminateCid.String())
+			if err != nil {
+				return nil, fmt.Errorf("get terminate message of sector %d: %w", sector.ID.Number, err)
+			}
+
+			pending.MessageState = messager.MessageStateToString(msg.State)
+		}
+
+		pendings = append(pendings, pending)
+	}
+
+	return pendings, nil
+}
+
+// CancelTerminate withdraws the termination of the sector, it is only possible before the terminate message
+// is signed and pushed into the mpool. A batched message carrying other sectors as well can not be cancelled.
+func (c *CommitmentMgrImpl) CancelTerminate(ctx context.Context, sid abi.SectorID) error {
+	c.terminateMu.Lock()
+	defer c.terminateMu.Unlock()
+
+	sector, err := c.smgr.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return fmt.Errorf("load sector: %w", err)
+	}
+
+	if sector.TerminateInfo.TerminatedAt > 0 {
+		return fmt.Errorf("termination already on chain at %d", sector.TerminateInfo.TerminatedAt)
+	}
+
+	if !sector.PendingForTerminateCommitment() {
+		return fmt.Errorf("no pending termination")
+	}
+
+	if mcid := sector.TerminateInfo.TerminateCid; mcid != nil {
+		msg, err := c.msgClient.GetMessageByUid(ctx, mcid.String())
+		if err != nil {
+			return fmt.Errorf("get terminate message %s: %w", mcid, err)
+		}
+
+		switch {
+		case msg.State == messager.MessageState.UnFillMsg:
+			others, err := c.sectorsInTerminateMessage(ctx, sid, *mcid)
+			if err != nil {
+				return err
+			}
+
+			if len(others) > 0 {
+				return fmt.Errorf("terminate message %s carries other sectors %v as well", mcid, others)
+			}
+
+			if err := c.msgClient.MarkBadMessage(ctx, mcid.String()); err != nil {
+				return fmt.Errorf("mark terminate message %s as bad: %w", mcid, err)
+			}
+
+		case messager.MessageFailed(msg.State):
+
+		case msg.State == messager.MessageState.OnChainMsg:
+			return fmt.Errorf("termination already on chain, msg %s", mcid)
+
+		default:
+			return fmt.Errorf(
+				"terminate message %s already pushed, state %s",
+				mcid,
+				messager.MessageStateToString(msg.State),
+			)
+		}
+	}
+
+	// the sector left in the batch will be skipped by the processor
+	return c.smgr.Update(ctx, sid, core.WorkerOffline, core.TerminateInfo{})
+}
+
+// sectorsInTerminateMessage returns the numbers of the sectors other than the given one,
+// which are still waiting for the same terminate message.
+func (c *CommitmentMgrImpl) sectorsInTerminateMessage(
+	ctx context.Context,
+	sid abi.SectorID,
+	mcid cid.Cid,
+) ([]abi.SectorNumber, error) {
+	sectors, err := c.smgr.All(ctx, core.WorkerOffline, core.SectorWorkerJobAll)
+	if err != nil {
+		return nil, fmt.Errorf("load offline sectors: %w", err)
+	}
+
+	var others []abi.SectorNumber
+	for _, sector := range sectors {
+		if sector.ID.Miner != sid.Miner || sector.ID == sid || !sector.PendingForTerminateCommitment() {
+			continue
+		}
+
+		if tcid := sector.TerminateInfo.TerminateCid; tcid != nil && tcid.Equals(mcid) {
+			others = append(others, sector.ID.Number)
+		}
+	}
+
+	return others, nil
+}
+
 func (c *CommitmentMgrImpl) handleMessage(
 	_ context.Context,
 	mid abi.ActorID,
@@ -0,0 +1,178 @@
+package commitmgr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	stbuiltin "github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
+)
+
+func (c *CommitmentMgrImpl) terminateProcessor() TerminateProcessor {
+	return TerminateProcessor{
+		api:       c.stateMgr,
+		msgClient: c.msgClient,
+		smgr:      c.smgr,
+		config:    c.cfg,
+		prover:    c.prover,
+		cancelMu:  &c.terminateMu,
+	}
+}
+
+// EstimateTerminate builds the terminate messages the same way as the terminate processor would do for the sectors,
+// and estimates the gas of each of them, without sending anything.
+func (c *CommitmentMgrImpl) EstimateTerminate(
+	ctx context.Context,
+	sids []abi.SectorID,
+) ([]core.TerminateGasEstimate, error) {
+	byMiner := map[abi.ActorID][]abi.SectorNumber{}
+	for _, sid := range sids {
+		byMiner[sid.Miner] = append(byMiner[sid.Miner], sid.Number)
+	}
+
+	mids := make([]abi.ActorID, 0, len(byMiner))
+	for mid := range byMiner {
+		mids = append(mids, mid)
+	}
+	sort.Slice(mids, func(i, j int) bool { return mids[i] < mids[j] })
+
+	var estimates []core.TerminateGasEstimate
+	for _, mid := range mids {
+		ests, err := c.estimateTerminate(ctx, mid, byMiner[mid])
+		if err != nil {
+			return nil, fmt.Errorf("estimate terminate for miner %d: %w", mid, err)
+		}
+
+		estimates = append(estimates, ests...)
+	}
+
+	return estimates, nil
+}
+
+func (c *CommitmentMgrImpl) estimateTerminate(
+	ctx context.Context,
+	mid abi.ActorID,
+	nums []abi.SectorNumber,
+) ([]core.TerminateGasEstimate, error) {
+	from, err := c.terminateSender(ctx, mid)
+	if err != nil {
+		return nil, err
+	}
+
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("actor id: %w", err)
+	}
+
+	mcfg, err := c.cfg.MinerConfig(mid)
+	if err != nil {
+		return nil, fmt.Errorf("get miner config: %w", err)
+	}
+
+	plog := log.With("proc", "terminate-estimate", "miner", mid)
+	tp := c.terminateProcessor()
+	batched := tp.ShouldBatch(mid)
+
+	var packed []core.TerminateSectorsParams
+	feeCfg := &mcfg.Commitment.Terminate.FeeConfig
+	if batched {
+		feeCfg = &mcfg.Commitment.Terminate.Batch.FeeConfig
+
+		declMax, err := tp.declarationsMax(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		decls, err := tp.terminateDeclarations(ctx, maddr, nums, plog)
+		if err != nil {
+			return nil, fmt.Errorf("build terminate declarations: %w", err)
+		}
+
+		packed, err = packTerminations(decls, tp.maxSectorsPerMessage(mid), declMax)
+		if err != nil {
+			return nil, fmt.Errorf("pack terminate declarations: %w", err)
+		}
+	} else {
+		for _, num := range nums {
+			decls, err := tp.terminateDeclarations(ctx, maddr, []abi.SectorNumber{num}, plog)
+			if err != nil {
+				return nil, fmt.Errorf("build terminate declarations for sector %d: %w", num, err)
+			}
+
+			if len(decls) > 0 {
+				packed = append(packed, core.TerminateSectorsParams{Terminations: decls})
+			}
+		}
+	}
+
+	estimates := make([]core.TerminateGasEstimate, 0, len(packed))
+	for i := range packed {
+		est, err := c.estimateTerminateMessage(ctx, from, mid, feeCfg, packed[i])
+		if err != nil {
+			return nil, fmt.Errorf("estimate message #%d: %w", i, err)
+		}
+
+		est.Batched = batched
+		estimates = append(estimates, est)
+	}
+
+	return estimates, nil
+}
+
+func (c *CommitmentMgrImpl) estimateTerminateMessage(
+	ctx context.Context,
+	from address.Address,
+	mid abi.ActorID,
+	feeCfg *modules.FeeConfig,
+	params core.TerminateSectorsParams,
+) (core.TerminateGasEstimate, error) {
+	est := core.TerminateGasEstimate{
+		Miner: mid,
+	}
+
+	for _, t := range params.Terminations {
+		err := t.Sectors.ForEach(func(sn uint64) error {
+			est.Sectors = append(est.Sectors, abi.SectorNumber(sn))
+			return nil
+		})
+		if err != nil {
+			return est, fmt.Errorf("sectors foreach: %w", err)
+		}
+	}
+
+	enc := new(bytes.Buffer)
+	if err := params.MarshalCBOR(enc); err != nil {
+		return est, fmt.Errorf("couldn't serialize TerminateSectorsParams: %w", err)
+	}
+
+	msg, err := newMessage(from, mid, big.Zero(), stbuiltin.MethodsMiner.TerminateSectors, feeCfg, enc.Bytes())
+	if err != nil {
+		return est, err
+	}
+
+	meta := feeCfg.GetSendSpec()
+	estimated, err := c.chain.GasEstimateMessageGas(ctx, &msg, &types.MessageSendSpec{
+		MaxFee:            big.Zero(),
+		GasOverEstimation: meta.GasOverEstimation,
+		GasOverPremium:    meta.GasOverPremium,
+	}, types.EmptyTSK)
+	if err != nil {
+		return est, fmt.Errorf("estimate message gas: %w", err)
+	}
+
+	est.GasLimit = estimated.GasLimit
+	est.GasFeeCap = estimated.GasFeeCap
+	est.GasPremium = estimated.GasPremium
+	est.MaxFee = big.Mul(estimated.GasFeeCap, big.NewInt(estimated.GasLimit))
+
+	return est, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -33,6 +34,10 @@ type TerminateProcessor struct {
 	config *modules.SafeConfig
 
 	prover core.Prover
+
+	// held from the check of the cancellations to the record of the message cids,
+	// so that a termination cancelled in between won't be sent
+	cancelMu *sync.Mutex
 }
 
 func (tp TerminateProcessor) processIndividually(
@@ -48,11 +53,6 @@ func (tp TerminateProcessor) processIndividually(
 		return
 	}
 
-	dl, err := tp.api.StateMinerProvingDeadline(ctx, maddr, nil)
-	if err != nil {
-		plog.Error("getting proving deadline info: ", err)
-	}
-
 	mcfg := tp.config.MustMinerConfig(mid)
 	wg := sync.WaitGroup{}
 	wg.Add(len(sectors))
@@ -61,65 +61,17 @@ func (tp TerminateProcessor) processIndividually(
 			slog := plog.With("sector", sectors[idx].ID.Number)
 			defer wg.Done()
 
-			loc, err := tp.api.StateSectorPartition(ctx, maddr, sectors[idx].ID.Number, nil)
+			decls, err := tp.terminateDeclarations(ctx, maddr, []abi.SectorNumber{sectors[idx].ID.Number}, slog)
 			if err != nil {
-				slog.Errorf("getting location: %s", sectors[idx].ID.Number, err)
-				return
-			}
-			if loc == nil {
-				slog.Errorf("sector %d location not found", sectors[idx].ID.Number)
-				return
-			}
-
-			// don't send terminations for currently challenged sectors
-			//revive:disable-next-line:line-length-limit
-			if loc.Deadline == (dl.Index+1)%stminer.WPoStPeriodDeadlines || // not in next (in case the terminate message takes a while to get on chain)
-				loc.Deadline == dl.Index || // not in current
-				(loc.Deadline+1)%stminer.WPoStPeriodDeadlines == dl.Index { // not in previous
+				slog.Errorf("build terminate declarations: %s", err)
 				return
 			}
 
-			ps, err := tp.api.StateMinerPartitions(ctx, maddr, loc.Deadline, nil)
-			if err != nil {
-				slog.Warn(
-					"getting miner partitions",
-					"deadline",
-					loc.Deadline,
-					"partition",
-					loc.Partition,
-					"error",
-					err,
-				)
-				return
-			}
-
-			toTerminate := bitfield.New()
-			toTerminate.Set(uint64(sectors[idx].ID.Number))
-			toTerminate, err = bitfield.IntersectBitField(ps[loc.Partition].LiveSectors, toTerminate)
-			if err != nil {
-				slog.Warn(
-					"intersecting liveSectors and toTerminate bitfields",
-					"deadline",
-					loc.Deadline,
-					"partition",
-					loc.Partition,
-					"error",
-					err,
-				)
-				return
-			}
-
-			params := core.TerminateSectorsParams{}
-			params.Terminations = append(params.Terminations, core.TerminationDeclaration{
-				Deadline:  loc.Deadline,
-				Partition: loc.Partition,
-				Sectors:   toTerminate,
-			})
-
-			if len(params.Terminations) == 0 {
+			if len(decls) == 0 {
 				return // nothing to do
 			}
 
+			params := core.TerminateSectorsParams{Terminations: decls}
 			enc := new(bytes.Buffer)
 			if err := params.MarshalCBOR(enc); err != nil {
 				slog.Error("couldn't serialize TerminateSectors params: ", err)
@@ -163,6 +115,14 @@ func (tp TerminateProcessor) Process(
 	start := time.Now()
 	defer plog.Infof("finished process, elapsed %s", time.Since(start))
 
+	tp.cancelMu.Lock()
+	defer tp.cancelMu.Unlock()
+
+	sectors = tp.skipCancelled(ctx, sectors, plog)
+	if len(sectors) == 0 {
+		return nil
+	}
+
 	defer func() {
 		for i := range sectors {
 			if sectors[i].TerminateInfo.TerminateCid != nil {
@@ -179,38 +139,130 @@ func (tp TerminateProcessor) Process(
 		return nil
 	}
 
+	declMax, err := tp.declarationsMax(ctx)
+	if err != nil {
+		return err
+	}
+
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return fmt.Errorf("actor id: %w", err)
+	}
+
+	nums := make([]abi.SectorNumber, 0, len(sectors))
+	for i := range sectors {
+		nums = append(nums, sectors[i].ID.Number)
+	}
+
+	decls, err := tp.terminateDeclarations(ctx, maddr, nums, plog)
+	if err != nil {
+		return fmt.Errorf("build terminate declarations: %w", err)
+	}
+
+	packed, err := packTerminations(decls, tp.maxSectorsPerMessage(mid), declMax)
+	if err != nil {
+		return fmt.Errorf("pack terminate declarations: %w", err)
+	}
+
+	if len(packed) == 0 {
+		return nil // nothing to do
+	}
+
+	mcfg := tp.config.MustMinerConfig(mid)
+	for i := range packed {
+		mlog := plog.With("msg-idx", i, "msg-total", len(packed))
+		enc := new(bytes.Buffer)
+		if err := packed[i].MarshalCBOR(enc); err != nil {
+			return fmt.Errorf("couldn't serialize TerminateSectorsParams: %w", err)
+		}
+
+		mcid, err := pushMessage(
+			ctx,
+			ctrlAddr,
+			mid,
+			big.Zero(),
+			stbuiltin.MethodsMiner.TerminateSectors,
+			tp.msgClient,
+			&mcfg.Commitment.Terminate.Batch.FeeConfig,
+			enc.Bytes(),
+			mlog,
+		)
+		if err != nil {
+			return fmt.Errorf("push aggregate terminate message #%d failed: %w", i, err)
+		}
+
+		mlog.Info("push terminate success, cid: ", mcid)
+
+		for _, t := range packed[i].Terminations {
+			err := t.Sectors.ForEach(func(sn uint64) error {
+				for idx := range sectors {
+					if sectors[idx].ID.Number == abi.SectorNumber(sn) {
+						sectors[idx].TerminateInfo.TerminateCid = &mcid
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("sectors foreach: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// maxSectorsPerMessage returns the max number of the sectors addressed by a single batched message,
+// the network limit is used if not configured or configured beyond it.
+func (tp TerminateProcessor) maxSectorsPerMessage(mid abi.ActorID) uint64 {
+	limit := uint64(stminer.AddressedSectorsMax)
+	configured := tp.config.MustMinerConfig(mid).Commitment.Terminate.Batch.MaxSectorsPerMessage
+	if configured > 0 && configured < limit {
+		return configured
+	}
+
+	return limit
+}
+
+// declarationsMax returns the max number of the declarations in a single message under current network version.
+func (tp TerminateProcessor) declarationsMax(ctx context.Context) (int, error) {
 	tok, _, err := tp.api.ChainHead(ctx)
 	if err != nil {
-		return fmt.Errorf("get chain head: %w", err)
+		return 0, fmt.Errorf("get chain head: %w", err)
 	}
 	nv, err := tp.api.StateNetworkVersion(ctx, tok)
 	if err != nil {
-		return fmt.Errorf("get network version : %w", err)
+		return 0, fmt.Errorf("get network version : %w", err)
 	}
 	declMax, err := specpolicy.GetDeclarationsMax(nv)
 	if err != nil {
-		return fmt.Errorf("get max declarations: %w", err)
+		return 0, fmt.Errorf("get max declarations: %w", err)
 	}
 
-	maddr, err := address.NewIDAddress(uint64(mid))
-	if err != nil {
-		return fmt.Errorf("actor id: %w", err)
-	}
+	return declMax, nil
+}
 
+// terminateDeclarations groups the live ones of the given sectors into declarations by their locations,
+// the sectors in the deadlines being challenged are skipped.
+func (tp TerminateProcessor) terminateDeclarations(
+	ctx context.Context,
+	maddr address.Address,
+	sectors []abi.SectorNumber,
+	plog *logging.ZapLogger,
+) ([]core.TerminationDeclaration, error) {
 	dl, err := tp.api.StateMinerProvingDeadline(ctx, maddr, nil)
 	if err != nil {
-		return fmt.Errorf("getting proving deadline info: %w", err)
+		return nil, fmt.Errorf("getting proving deadline info: %w", err)
 	}
 
 	todo := map[miner.SectorLocation]*bitfield.BitField{}
-	for _, sector := range sectors {
-		loc, err := tp.api.StateSectorPartition(ctx, maddr, sector.ID.Number, nil)
+	for _, num := range sectors {
+		loc, err := tp.api.StateSectorPartition(ctx, maddr, num, nil)
 		if err != nil {
-			plog.Errorf("getting sector %d location: %s", sector.ID.Number, err)
+			plog.Errorf("getting sector %d location: %s", num, err)
 			continue
 		}
 		if loc == nil {
-			plog.Errorf("sector %d location not found", sector.ID.Number)
+			plog.Errorf("sector %d location not found", num)
 			continue
 		}
 
@@ -220,26 +272,11 @@ func (tp TerminateProcessor) Process(
 			bf = &n
 			todo[*loc] = bf
 		}
-		bf.Set(uint64(sector.ID.Number))
+		bf.Set(uint64(num))
 	}
 
-	params := core.TerminateSectorsParams{}
-	var total uint64
+	decls := make([]core.TerminationDeclaration, 0, len(todo))
 	for loc, bfSectors := range todo {
-		n, err := bfSectors.Count()
-		if err != nil {
-			plog.Error(
-				"failed to count sectors to terminate",
-				"deadline",
-				loc.Deadline,
-				"partition",
-				loc.Partition,
-				"error",
-				err,
-			)
-			continue
-		}
-
 		// don't send terminations for currently challenged sectors
 		//revive:disable-next-line:line-length-limit
 		if loc.Deadline == (dl.Index+1)%stminer.WPoStPeriodDeadlines || // not in next (in case the terminate message takes a while to get on chain)
@@ -248,24 +285,13 @@ func (tp TerminateProcessor) Process(
 			continue
 		}
 
-		if n < 1 {
-			plog.Warn("zero sectors in bucket", "deadline", loc.Deadline, "partition", loc.Partition)
-			continue
-		}
-
-		toTerminate, err := bfSectors.Copy()
-		if err != nil {
-			plog.Warn("copy sectors bitfield", "deadline", loc.Deadline, "partition", loc.Partition, "error", err)
-			continue
-		}
-
 		ps, err := tp.api.StateMinerPartitions(ctx, maddr, loc.Deadline, nil)
 		if err != nil {
 			plog.Warn("getting miner partitions", "deadline", loc.Deadline, "partition", loc.Partition, "error", err)
 			continue
 		}
 
-		toTerminate, err = bitfield.IntersectBitField(ps[loc.Partition].LiveSectors, toTerminate)
+		toTerminate, err := bitfield.IntersectBitField(ps[loc.Partition].LiveSectors, *bfSectors)
 		if err != nil {
 			plog.Warn(
 				"intersecting liveSectors and toTerminate bitfields",
@@ -279,95 +305,134 @@ func (tp TerminateProcessor) Process(
 			continue
 		}
 
-		if total+n > uint64(stminer.AddressedSectorsMax) {
-			n = uint64(stminer.AddressedSectorsMax) - total
-
-			toTerminate, err = toTerminate.Slice(0, n)
-			if err != nil {
-				plog.Warn(
-					"slice toTerminate bitfield",
-					"deadline",
-					loc.Deadline,
-					"partition",
-					loc.Partition,
-					"error",
-					err,
-				)
-				continue
-			}
-
-			s, err := bitfield.SubtractBitField(*bfSectors, toTerminate)
-			if err != nil {
-				plog.Warn("sectors-toTerminate", "deadline", loc.Deadline, "partition", loc.Partition, "error", err)
-				continue
-			}
-			*bfSectors = s
+		n, err := toTerminate.Count()
+		if err != nil {
+			plog.Warn("count sectors to terminate", "deadline", loc.Deadline, "partition", loc.Partition, "error", err)
+			continue
 		}
 
-		total += n
+		if n < 1 {
+			plog.Warn("zero live sectors in bucket", "deadline", loc.Deadline, "partition", loc.Partition)
+			continue
+		}
 
-		params.Terminations = append(params.Terminations, core.TerminationDeclaration{
+		decls = append(decls, core.TerminationDeclaration{
 			Deadline:  loc.Deadline,
 			Partition: loc.Partition,
 			Sectors:   toTerminate,
 		})
+	}
 
-		if total >= uint64(stminer.AddressedSectorsMax) {
-			break
+	sort.Slice(decls, func(i, j int) bool {
+		if decls[i].Deadline != decls[j].Deadline {
+			return decls[i].Deadline < decls[j].Deadline
 		}
+		return decls[i].Partition < decls[j].Partition
+	})
 
-		if len(params.Terminations) >= declMax {
-			break
-		}
-	}
+	return decls, nil
+}
 
-	if len(params.Terminations) == 0 {
-		return nil // nothing to do
+// packTerminations packs the declarations into the params of the messages, each of which addresses
+// at most maxSectors sectors in at most maxDecls declarations, a declaration could be split across the messages.
+// The declarations are expected to be sorted by deadline, so that the sectors of a deadline are kept
+// in the adjacent messages.
+func packTerminations(
+	decls []core.TerminationDeclaration,
+	maxSectors uint64,
+	maxDecls int,
+) ([]core.TerminateSectorsParams, error) {
+	if maxSectors == 0 || maxDecls <= 0 {
+		return nil, fmt.Errorf("invalid limits, max sectors %d, max declarations %d", maxSectors, maxDecls)
 	}
 
-	enc := new(bytes.Buffer)
-	if err := params.MarshalCBOR(enc); err != nil {
-		return fmt.Errorf("couldn't serialize TerminateSectorsParams: %w", err)
-	}
+	var packed []core.TerminateSectorsParams
+	var cur core.TerminateSectorsParams
+	var total uint64
 
-	mcfg := tp.config.MustMinerConfig(mid)
-	mcid, err := pushMessage(
-		ctx,
-		ctrlAddr,
-		mid,
-		big.Zero(),
-		stbuiltin.MethodsMiner.TerminateSectors,
-		tp.msgClient,
-		&mcfg.Commitment.Terminate.Batch.FeeConfig,
-		enc.Bytes(),
-		plog,
-	)
-	if err != nil {
-		return fmt.Errorf("push aggregate terminate message failed: %w", err)
+	flush := func() {
+		if len(cur.Terminations) > 0 {
+			packed = append(packed, cur)
+		}
+		cur = core.TerminateSectorsParams{}
+		total = 0
 	}
 
-	plog.Info("push terminate success, cid: ", mcid)
+	for _, decl := range decls {
+		remain := decl.Sectors
+		for {
+			n, err := remain.Count()
+			if err != nil {
+				return nil, fmt.Errorf("count sectors of deadline %d partition %d: %w", decl.Deadline, decl.Partition, err)
+			}
 
-	for _, t := range params.Terminations {
-		delete(todo, miner.SectorLocation{
-			Deadline:  t.Deadline,
-			Partition: t.Partition,
-		})
+			if n == 0 {
+				break
+			}
 
-		err := t.Sectors.ForEach(func(sn uint64) error {
-			for idx := range sectors {
-				if sectors[idx].ID.Number == abi.SectorNumber(sn) {
-					sectors[idx].TerminateInfo.TerminateCid = &mcid
-				}
+			room := maxSectors - total
+			if n <= room {
+				cur.Terminations = append(cur.Terminations, core.TerminationDeclaration{
+					Deadline:  decl.Deadline,
+					Partition: decl.Partition,
+					Sectors:   remain,
+				})
+				total += n
+				break
 			}
-			return nil
-		})
+
+			part, err := remain.Slice(0, room)
+			if err != nil {
+				return nil, fmt.Errorf("slice sectors of deadline %d partition %d: %w", decl.Deadline, decl.Partition, err)
+			}
+
+			remain, err = bitfield.SubtractBitField(remain, part)
+			if err != nil {
+				return nil, fmt.Errorf("subtract sectors of deadline %d partition %d: %w", decl.Deadline, decl.Partition, err)
+			}
+
+			cur.Terminations = append(cur.Terminations, core.TerminationDeclaration{
+				Deadline:  decl.Deadline,
+				Partition: decl.Partition,
+				Sectors:   part,
+			})
+			flush()
+		}
+
+		if total >= maxSectors || len(cur.Terminations) >= maxDecls {
+			flush()
+		}
+	}
+
+	flush()
+
+	return packed, nil
+}
+
+// skipCancelled drops the sectors whose terminations have been cancelled after being added into the batch.
+func (tp TerminateProcessor) skipCancelled(
+	ctx context.Context,
+	sectors []core.SectorState,
+	plog *logging.ZapLogger,
+) []core.SectorState {
+	remain := sectors[:0]
+	for i := range sectors {
+		state, err := tp.smgr.Load(ctx, sectors[i].ID, core.WorkerOffline)
 		if err != nil {
-			return fmt.Errorf("sectors foreach: %w", err)
+			plog.With("sector", sectors[i].ID.Number).Warnf("load sector state: %s", err)
+			remain = append(remain, sectors[i])
+			continue
 		}
+
+		if !state.PendingForTerminateCommitment() || state.TerminateInfo.TerminateCid != nil {
+			plog.With("sector", sectors[i].ID.Number).Info("termination cancelled or already submitted, skip")
+			continue
+		}
+
+		remain = append(remain, sectors[i])
 	}
 
-	return nil
+	return remain
 }
 
 func (tp TerminateProcessor) Expire(
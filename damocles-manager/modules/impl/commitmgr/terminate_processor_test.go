@@ -0,0 +1,104 @@
+package commitmgr
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+)
+
+func TestPackTerminations(t *testing.T) {
+	type decl struct {
+		deadline  uint64
+		partition uint64
+		sectors   []uint64
+	}
+
+	toDecls := func(ds []decl) []core.TerminationDeclaration {
+		decls := make([]core.TerminationDeclaration, 0, len(ds))
+		for _, d := range ds {
+			decls = append(decls, core.TerminationDeclaration{
+				Deadline:  d.deadline,
+				Partition: d.partition,
+				Sectors:   bitfield.NewFromSet(d.sectors),
+			})
+		}
+		return decls
+	}
+
+	testCases := []struct {
+		name       string
+		decls      []decl
+		maxSectors uint64
+		maxDecls   int
+		expected   [][]decl
+	}{
+		{
+			name:       "empty",
+			maxSectors: 4,
+			maxDecls:   2,
+		},
+		{
+			name:       "exact fit",
+			decls:      []decl{{0, 0, []uint64{1, 2}}, {1, 0, []uint64{3, 4}}},
+			maxSectors: 4,
+			maxDecls:   2,
+			expected: [][]decl{
+				{{0, 0, []uint64{1, 2}}, {1, 0, []uint64{3, 4}}},
+			},
+		},
+		{
+			name:       "split declaration",
+			decls:      []decl{{0, 0, []uint64{1, 2}}, {0, 1, []uint64{3, 4, 5, 6, 7}}},
+			maxSectors: 3,
+			maxDecls:   4,
+			expected: [][]decl{
+				{{0, 0, []uint64{1, 2}}, {0, 1, []uint64{3}}},
+				{{0, 1, []uint64{4, 5, 6}}},
+				{{0, 1, []uint64{7}}},
+			},
+		},
+		{
+			name:       "max declarations",
+			decls:      []decl{{0, 0, []uint64{1}}, {0, 1, []uint64{2}}, {1, 0, []uint64{3}}},
+			maxSectors: 10,
+			maxDecls:   2,
+			expected: [][]decl{
+				{{0, 0, []uint64{1}}, {0, 1, []uint64{2}}},
+				{{1, 0, []uint64{3}}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			packed, err := packTerminations(toDecls(tc.decls), tc.maxSectors, tc.maxDecls)
+			require.NoError(t, err)
+			require.Len(t, packed, len(tc.expected))
+
+			for i, params := range packed {
+				expected := tc.expected[i]
+				require.Len(t, params.Terminations, len(expected), "message #%d", i)
+
+				for j, term := range params.Terminations {
+					require.Equal(t, expected[j].deadline, term.Deadline, "message #%d decl #%d", i, j)
+					require.Equal(t, expected[j].partition, term.Partition, "message #%d decl #%d", i, j)
+
+					sectors, err := term.Sectors.All(tc.maxSectors)
+					require.NoError(t, err)
+					require.Equal(t, expected[j].sectors, sectors, "message #%d decl #%d", i, j)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid limits", func(t *testing.T) {
+		_, err := packTerminations(nil, 0, 1)
+		require.Error(t, err)
+
+		_, err = packTerminations(nil, 1, 0)
+		require.Error(t, err)
+	})
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/filecoin-project/go-state-types/abi"
@@ -16,6 +17,7 @@ func NewCommitManager() core.CommitmentManager {
 
 	cmgr.pres.commits = map[abi.SectorID]core.PreCommitInfo{}
 	cmgr.proofs.proofs = map[abi.SectorID]core.ProofInfo{}
+	cmgr.terminates.terminates = map[abi.SectorID]struct{}{}
 	return cmgr
 }
 
@@ -144,3 +146,33 @@ func (c *commitMgr) SubmitTerminate(_ context.Context, sid abi.SectorID) (core.S
 func (*commitMgr) TerminateState(context.Context, abi.SectorID) (core.TerminateInfo, error) {
 	return core.TerminateInfo{}, nil
 }
+
+func (c *commitMgr) PendingTerminations(_ context.Context, mid abi.ActorID) ([]core.PendingTermination, error) {
+	c.terminates.RLock()
+	defer c.terminates.RUnlock()
+
+	pendings := make([]core.PendingTermination, 0)
+	for sid := range c.terminates.terminates {
+		if sid.Miner == mid {
+			pendings = append(pendings, core.PendingTermination{ID: sid})
+		}
+	}
+
+	return pendings, nil
+}
+
+func (c *commitMgr) CancelTerminate(_ context.Context, sid abi.SectorID) error {
+	c.terminates.Lock()
+	defer c.terminates.Unlock()
+
+	if _, ok := c.terminates.terminates[sid]; !ok {
+		return fmt.Errorf("no pending termination")
+	}
+
+	delete(c.terminates.terminates, sid)
+	return nil
+}
+
+func (*commitMgr) EstimateTerminate(context.Context, []abi.SectorID) ([]core.TerminateGasEstimate, error) {
+	return nil, nil
+}
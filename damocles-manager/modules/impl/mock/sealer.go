@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-bitfield"
@@ -157,10 +158,15 @@ func (s *Sealer) PollProofState(ctx context.Context, sid abi.SectorID) (core.Pol
 	return s.commit.ProofState(ctx, sid)
 }
 
-func (*Sealer) ListSectors(
+func (*Sealer) ListSectors(context.Context, core.SectorWorkerState, core.SectorWorkerJob) ([]*core.SectorState, error) {
+	return nil, nil
+}
+
+func (*Sealer) ListSectorsEx(
 	context.Context,
 	core.SectorWorkerState,
 	core.SectorWorkerJob,
+	core.ListSectorsOptions,
 ) ([]*core.SectorState, error) {
 	return nil, nil
 }
@@ -169,6 +175,14 @@ func (*Sealer) FindSector(context.Context, core.SectorWorkerState, abi.SectorID)
 	return nil, nil
 }
 
+func (*Sealer) ListSectorsByDeadline(context.Context, abi.ActorID, uint64) ([]*core.SectorState, error) {
+	return nil, nil
+}
+
+func (*Sealer) SectorsChangedSince(context.Context, time.Time) ([]*core.SectorState, error) {
+	return nil, nil
+}
+
 func (*Sealer) FindSectorInAllStates(context.Context, abi.SectorID) (*core.SectorState, error) {
 	return nil, nil
 }
@@ -185,6 +199,10 @@ func (*Sealer) ImportSector(context.Context, core.SectorWorkerState, *core.Secto
 	return false, nil
 }
 
+func (*Sealer) ImportSectorsFromDir(context.Context, abi.ActorID, string) (*core.SectorsDirImportResult, error) {
+	return nil, nil
+}
+
 func (*Sealer) RestoreSector(context.Context, abi.SectorID, bool) (core.Meta, error) {
 	return core.Empty, nil
 }
@@ -208,6 +226,28 @@ func (*Sealer) ReportAborted(_ context.Context, sid abi.SectorID, reason string)
 	return core.Empty, nil
 }
 
+func (*Sealer) CheckProvableMulti(
+	context.Context,
+	[]core.ProvableRequest,
+) (map[abi.ActorID]core.ProvableResult, error) {
+	return nil, nil
+}
+
+func (*Sealer) CheckProvableMixed(
+	context.Context,
+	abi.ActorID,
+	[]builtin.ExtendedSectorInfo,
+	bool,
+	bool,
+	core.ProvableOptions,
+) (map[abi.SectorNumber]string, error) {
+	return nil, nil
+}
+
+func (*Sealer) ProvabilityReport(context.Context, abi.ActorID, bool, core.ProvableOptions) (*core.ProvabilityReport, error) {
+	return nil, nil
+}
+
 func (*Sealer) CheckProvable(
 	context.Context,
 	abi.ActorID,
@@ -219,6 +259,27 @@ func (*Sealer) CheckProvable(
 	return nil, nil
 }
 
+func (*Sealer) CheckProvableEx(
+	context.Context,
+	abi.ActorID,
+	abi.RegisteredPoStProof,
+	[]builtin.ExtendedSectorInfo,
+	bool,
+	bool,
+	core.ProvableOptions,
+) (map[abi.SectorNumber]string, error) {
+	return nil, nil
+}
+
+func (*Sealer) PrePoStCheck(
+	context.Context,
+	abi.ActorID,
+	abi.RegisteredPoStProof,
+	uint64,
+) (map[abi.SectorNumber]string, error) {
+	return nil, nil
+}
+
 func (*Sealer) SimulateWdPoSt(
 	context.Context,
 	address.Address,
@@ -243,6 +304,14 @@ func (*Sealer) SubmitSnapUpProof(
 	return core.SubmitSnapUpProofResp{Res: core.SubmitAccepted}, nil
 }
 
+func (*Sealer) SnapUpPause(context.Context, abi.ActorID) error {
+	return nil
+}
+
+func (*Sealer) SnapUpResume(context.Context, abi.ActorID) error {
+	return nil
+}
+
 func (*Sealer) SnapUpPreFetch(context.Context, abi.ActorID, *uint64) (*core.SnapUpFetchResult, error) {
 	return &core.SnapUpFetchResult{}, nil
 }
@@ -259,6 +328,34 @@ func (*Sealer) ProvingSectorInfo(context.Context, abi.SectorID) (core.ProvingSec
 	return core.ProvingSectorInfo{}, nil
 }
 
+func (*Sealer) SectorsExpiringBefore(
+	context.Context,
+	abi.ActorID,
+	abi.ChainEpoch,
+) ([]core.SectorExpirationInfo, error) {
+	return nil, nil
+}
+
+func (*Sealer) StuckSectors(context.Context, time.Duration) ([]core.StuckSector, error) {
+	return nil, nil
+}
+
+func (*Sealer) InspectPrivateSectorInfo(context.Context, abi.SectorID) (*core.PrivateSectorInspection, error) {
+	return nil, nil
+}
+
+func (*Sealer) DealStats(context.Context, abi.ActorID) (*core.DealStats, error) {
+	return &core.DealStats{}, nil
+}
+
+func (*Sealer) SealDurationStats(context.Context, abi.ActorID, time.Time) (*core.SealDurationStats, error) {
+	return nil, nil
+}
+
+func (*Sealer) ListSealedDeals(context.Context, abi.ActorID) ([]core.SealedDealInfo, error) {
+	return nil, nil
+}
+
 func (*Sealer) WorkerPing(_ context.Context, winfo core.WorkerInfo) (core.Meta, error) {
 	var buf bytes.Buffer
 	enc := json.NewEncoder(&buf)
@@ -284,6 +381,10 @@ func (*Sealer) WorkerPingInfoRemove(context.Context, string) error {
 	return nil
 }
 
+func (*Sealer) WorkerRegister(context.Context, string) (bool, error) {
+	return false, nil
+}
+
 func (*Sealer) SectorIndexerFind(
 	context.Context,
 	core.SectorIndexType,
@@ -303,6 +404,14 @@ func (s *Sealer) PollTerminateSectorState(ctx context.Context, sid abi.SectorID)
 	return s.commit.TerminateState(ctx, sid)
 }
 
+func (*Sealer) SectorPaths(context.Context, abi.SectorID) (*core.SectorPaths, error) {
+	return nil, nil
+}
+
+func (*Sealer) CanRemoveSector(context.Context, abi.SectorID) (*core.SectorRemovability, error) {
+	return nil, nil
+}
+
 func (*Sealer) RemoveSector(context.Context, abi.SectorID) error {
 	return nil
 }
@@ -337,10 +446,26 @@ func (*Sealer) StoreReleaseReserved(context.Context, abi.SectorID) (bool, error)
 	return true, nil
 }
 
+func (*Sealer) StoreReserve(context.Context, abi.SectorID, string, uint64, string) (core.ReservedItem, error) {
+	return core.ReservedItem{}, nil
+}
+
 func (*Sealer) StoreList(context.Context) ([]core.StoreDetailedInfo, error) {
 	return nil, nil
 }
 
+func (*Sealer) StoreRebalance(context.Context, string, string, int) (*core.StoreRebalanceResult, error) {
+	return nil, nil
+}
+
+func (*Sealer) StoreCapacityReport(context.Context, abi.SectorSize) (*core.StoreCapacityReport, error) {
+	return nil, nil
+}
+
+func (*Sealer) StoreRefreshInfo(context.Context, string) (*core.StoreDetailedInfo, error) {
+	return nil, nil
+}
+
 func (*Sealer) StoreBasicInfo(_ context.Context, instanceName string) (*core.StoreBasicInfo, error) {
 	log.Warnw("get store basic info", "instance", instanceName)
 	return &core.StoreBasicInfo{
@@ -350,10 +475,42 @@ func (*Sealer) StoreBasicInfo(_ context.Context, instanceName string) (*core.Sto
 	}, nil
 }
 
+func (*Sealer) SetSectorLabels(context.Context, abi.SectorID, map[string]string) (core.SectorLabels, error) {
+	return nil, nil
+}
+
+func (*Sealer) RederiveTicket(context.Context, abi.SectorID, abi.ChainEpoch) (*core.Ticket, error) {
+	return nil, nil
+}
+
 func (*Sealer) SectorSetForRebuild(context.Context, abi.SectorID, core.RebuildOptions) (bool, error) {
 	return false, nil
 }
 
+func (*Sealer) RebuildProgress(context.Context, abi.SectorID) (*core.SectorRebuildProgress, error) {
+	return nil, nil
+}
+
+func (*Sealer) ListRebuildSectors(context.Context, *abi.ActorID) ([]core.SectorRebuildStatus, error) {
+	return nil, nil
+}
+
+func (*Sealer) VerifySealedFileSizes(context.Context, abi.ActorID) ([]core.SealedFileSizeMismatch, error) {
+	return nil, nil
+}
+
+func (*Sealer) CheckSectorCache(context.Context, abi.SectorID) (*core.SectorCacheCheckResult, error) {
+	return nil, nil
+}
+
+func (*Sealer) RepairSectorCache(
+	context.Context,
+	abi.SectorID,
+	core.RebuildOptions,
+) (*core.SectorCacheRepairResult, error) {
+	return nil, nil
+}
+
 func (*Sealer) AllocateRebuildSector(context.Context, core.AllocateSectorSpec) (*core.SectorRebuildInfo, error) {
 	return nil, nil
 }
@@ -381,6 +538,94 @@ func (*Sealer) AcquireUnsealDest(context.Context, abi.SectorID, cid.Cid) ([]stri
 	return nil, nil
 }
 
+func (*Sealer) HealthCheck(context.Context) (*core.HealthReport, error) {
+	return nil, nil
+}
+
+func (*Sealer) APIFingerprint(context.Context) (map[string]string, error) {
+	return core.APIFingerprint(), nil
+}
+
+func (*Sealer) WorkerFindByHost(context.Context, string) ([]core.WorkerPingInfo, error) {
+	return nil, nil
+}
+
+func (*Sealer) ReconcileMinerSectors(context.Context, abi.ActorID) (*core.MinerSectorsReconciliation, error) {
+	return nil, nil
+}
+
+func (*Sealer) AbandonSector(context.Context, abi.SectorID, string) error {
+	return nil
+}
+
+func (*Sealer) SectorPieces(context.Context, abi.SectorID) ([]core.SectorPieceLocation, error) {
+	return nil, nil
+}
+
+func (*Sealer) RestoreAborted(context.Context, abi.ActorID, bool) ([]core.SectorRestoreResult, error) {
+	return nil, nil
+}
+
+func (*Sealer) ReplaySectorStage(context.Context, abi.SectorID, core.SectorReplayStage) error {
+	return nil
+}
+
+func (*Sealer) RestoreSectorEx(context.Context, abi.SectorID, core.RestoreSectorOptions) (core.Meta, error) {
+	return core.Empty, nil
+}
+
+func (*Sealer) ProverInfo(context.Context) (*core.ProverInfo, error) {
+	return nil, nil
+}
+
+func (*Sealer) SectorsOnStore(context.Context, string) (*core.SectorsOnStore, error) {
+	return nil, nil
+}
+
+func (*Sealer) SetPieceBandwidthLimit(context.Context, int64, int64) error {
+	return nil
+}
+
+func (*Sealer) ListLocalPieces(context.Context, int, int) ([]core.LocalPiece, error) {
+	return nil, nil
+}
+
+func (*Sealer) VerifyPiece(context.Context, cid.Cid) (*core.PieceVerification, error) {
+	return nil, nil
+}
+
+func (s *Sealer) ListPendingTerminations(ctx context.Context, mid abi.ActorID) ([]core.PendingTermination, error) {
+	return s.commit.PendingTerminations(ctx, mid)
+}
+
+func (s *Sealer) CancelTermination(ctx context.Context, sid abi.SectorID) error {
+	return s.commit.CancelTerminate(ctx, sid)
+}
+
+func (*Sealer) EstimateTerminateGas(context.Context, []abi.SectorID) ([]core.TerminateGasEstimate, error) {
+	return nil, nil
+}
+
+func (*Sealer) StoreListEx(context.Context, core.StoreListOptions) ([]core.StoreDetailedInfo, error) {
+	return nil, nil
+}
+
+func (*Sealer) CheckSectorProvable(context.Context, abi.SectorID, bool) (*core.SectorProvability, error) {
+	return nil, nil
+}
+
+func (*Sealer) ReindexStore(context.Context, string, bool) (*core.StoreReindexResult, error) {
+	return nil, nil
+}
+
+func (*Sealer) FindOrphanedFiles(context.Context, string) (*core.StoreOrphanedFiles, error) {
+	return nil, nil
+}
+
+func (*Sealer) ValidateUpgradeInfo(context.Context, abi.SectorID) (*core.SectorUpgradeValidation, error) {
+	return nil, nil
+}
+
 func (*Sealer) Version(context.Context) (string, error) {
 	return ver.VersionStr(), nil
 }
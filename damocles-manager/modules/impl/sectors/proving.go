@@ -58,6 +58,19 @@ func (p *Proving) SingleProvable(
 	upgrade bool,
 	locator core.SectorLocator,
 	strict, stateCheck bool,
+) error {
+	return p.singleProvable(ctx, postProofType, sref, upgrade, locator, strict, stateCheck, nil)
+}
+
+// singleProvable is SingleProvable with the on-chain sector infos loaded from the given cache, if any.
+func (p *Proving) singleProvable(
+	ctx context.Context,
+	postProofType abi.RegisteredPoStProof,
+	sref core.SectorRef,
+	upgrade bool,
+	locator core.SectorLocator,
+	strict, stateCheck bool,
+	sinfos *chainapi.SectorInfoCache,
 ) error {
 	ssize, err := sref.ProofType.SectorSize()
 	if err != nil {
@@ -66,7 +79,7 @@ func (p *Proving) SingleProvable(
 
 	privateInfo, err := p.SectorTracker.SinglePrivateInfo(ctx, sref, upgrade, locator)
 	if err != nil {
-		return fmt.Errorf("get private info: %w", err)
+		return provableErr(core.ErrProvableNotIndexed, "get private info: %w", err)
 	}
 	sealedFileIns, err := p.storeMgr.GetInstance(ctx, privateInfo.Accesses.SealedFile)
 	if err != nil {
@@ -110,12 +123,13 @@ func (p *Proving) SingleProvable(
 		for p, sz := range check.targets {
 			st, err := check.store.Stat(ctx, p)
 			if err != nil {
-				return fmt.Errorf("stat object %s for %s: %w", p, check.title, err)
+				return provableErr(core.ErrProvableMissingFile, "stat object %s for %s: %w", p, check.title, err)
 			}
 
 			if sz != 0 && strict {
 				if st.Size != int64(ssize)*sz {
-					return fmt.Errorf(
+					return provableErr(
+						core.ErrProvableWrongSize,
 						"%s for %s with wrong size (got %d, expect %d)",
 						p,
 						check.title,
@@ -135,11 +149,21 @@ func (p *Proving) SingleProvable(
 	if err != nil {
 		return err
 	}
-	sinfo, err := p.capi.StateSectorGetInfo(ctx, addr, sref.ID.Number, types.EmptyTSK)
+
+	var sinfo *types.SectorOnChainInfo
+	if sinfos != nil {
+		sinfo, err = sinfos.Get(ctx, sref.ID)
+	} else {
+		sinfo, err = p.capi.StateSectorGetInfo(ctx, addr, sref.ID.Number, types.EmptyTSK)
+	}
 	if err != nil {
 		return err
 	}
 
+	if sinfo == nil {
+		return provableErr(core.ErrProvableStateMismatch, "sector %s not found on chain", sref.ID)
+	}
+
 	if stateCheck {
 		// local and chain consistency check
 		ss, err := p.state.Load(ctx, sref.ID, core.WorkerOffline)
@@ -150,17 +174,17 @@ func (p *Proving) SingleProvable(
 		// for snap: onChain.SealedCID == local.UpgradedInfo.SealedCID, onChain.SectorKeyCID == ss.Pre.CommR, for other(CC/DC): onChain.SealedCID == onChain.SealedCID
 		if !upgrade {
 			if !ss.Pre.CommR.Equals(sinfo.SealedCID) {
-				return fmt.Errorf("the SealedCID on the local and the chain is inconsistent")
+				return provableErr(core.ErrProvableStateMismatch, "the SealedCID on the local and the chain is inconsistent")
 			}
 		} else {
 			if !sinfo.SectorKeyCID.Equals(ss.Pre.CommR) {
-				return fmt.Errorf("the SectorKeyCID on the local and the chain is inconsistent")
+				return provableErr(core.ErrProvableStateMismatch, "the SectorKeyCID on the local and the chain is inconsistent")
 			}
 
 			//revive:disable-line:line-length-limit
 			// 从 lotus 导入的扇区 UpgradedInfo 是空值,见代码: damocles-manager/cmd/damocles-manager/internal/util_sealer_sectors.go#L1735
 			if ss.UpgradedInfo.SealedCID != cid.Undef && !sinfo.SealedCID.Equals(ss.UpgradedInfo.SealedCID) {
-				return fmt.Errorf("the SealedCID on the local and the chain is inconsistent")
+				return provableErr(core.ErrProvableStateMismatch, "the SealedCID on the local and the chain is inconsistent")
 			}
 		}
 	}
@@ -175,12 +199,65 @@ func (p *Proving) SingleProvable(
 	_, err = p.prover.GenerateSingleVanillaProof(ctx, replica, []uint64{rand.Uint64() % (uint64(ssize) / 32)})
 
 	if err != nil {
-		return fmt.Errorf("generate vanilla proof of %s failed: %w", sref.ID, err)
+		return provableErr(core.ErrProvableProofFailed, "generate vanilla proof of %s failed: %w", sref.ID, err)
 	}
 
 	return nil
 }
 
+// provableError tags the error of the provable check with one of the core.ErrProvable* errors,
+// the message is kept as it is.
+type provableError struct {
+	kind error
+	err  error
+}
+
+func (e *provableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *provableError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+func provableErr(kind error, format string, args ...any) error {
+	return &provableError{
+		kind: kind,
+		err:  fmt.Errorf(format, args...),
+	}
+}
+
+// skipFaulty drops the sectors which have been declared faulty on chain.
+func (p *Proving) skipFaulty(
+	ctx context.Context,
+	mid abi.ActorID,
+	sectors []builtin.ExtendedSectorInfo,
+) ([]builtin.ExtendedSectorInfo, error) {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id %d: %w", mid, err)
+	}
+
+	faults, err := p.capi.StateMinerFaults(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get faulty sectors: %w", err)
+	}
+
+	healthy := make([]builtin.ExtendedSectorInfo, 0, len(sectors))
+	for _, sector := range sectors {
+		faulty, err := faults.IsSet(uint64(sector.SectorNumber))
+		if err != nil {
+			return nil, fmt.Errorf("check if sector %d faulty: %w", sector.SectorNumber, err)
+		}
+
+		if !faulty {
+			healthy = append(healthy, sector)
+		}
+	}
+
+	return healthy, nil
+}
+
 func (p *Proving) Provable(
 	ctx context.Context,
 	mid abi.ActorID,
@@ -188,7 +265,54 @@ func (p *Proving) Provable(
 	sectors []builtin.ExtendedSectorInfo,
 	strict, stateCheck bool,
 ) (map[abi.SectorNumber]string, error) {
+	return p.ProvableEx(ctx, mid, postProofType, sectors, strict, stateCheck, core.ProvableOptions{})
+}
+
+// ProvableEx is Provable with the options overriding the proving config for this run.
+func (p *Proving) ProvableEx(
+	ctx context.Context,
+	mid abi.ActorID,
+	postProofType abi.RegisteredPoStProof,
+	sectors []builtin.ExtendedSectorInfo,
+	strict, stateCheck bool,
+	opts core.ProvableOptions,
+) (map[abi.SectorNumber]string, error) {
+	bad, err := p.ProvableErrs(ctx, mid, postProofType, sectors, strict, stateCheck, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons := make(map[abi.SectorNumber]string, len(bad))
+	for num, berr := range bad {
+		reasons[num] = berr.Error()
+	}
+
+	return reasons, nil
+}
+
+// ProvableErrs is ProvableEx with the errors of the bad sectors, tagged with the core.ErrProvable* errors.
+func (p *Proving) ProvableErrs(
+	ctx context.Context,
+	mid abi.ActorID,
+	postProofType abi.RegisteredPoStProof,
+	sectors []builtin.ExtendedSectorInfo,
+	strict, stateCheck bool,
+	opts core.ProvableOptions,
+) (map[abi.SectorNumber]error, error) {
+	if opts.SkipFaulty {
+		healthy, err := p.skipFaulty(ctx, mid, sectors)
+		if err != nil {
+			return nil, err
+		}
+
+		sectors = healthy
+	}
+
 	limit := p.parallelCheckLimit
+	if opts.ParallelCheckLimit > 0 {
+		limit = opts.ParallelCheckLimit
+	}
+
 	if limit <= 0 {
 		limit = len(sectors)
 	}
@@ -200,7 +324,22 @@ func (p *Proving) Provable(
 		defer pcCancel()
 	}
 
-	results := make([]string, len(sectors))
+	// the on-chain infos are only required by the strict checks, load them with one chain call
+	var sinfos *chainapi.SectorInfoCache
+	if strict && len(sectors) > 0 {
+		sinfos = chainapi.NewSectorInfoCache(p.capi, types.EmptyTSK)
+		sids := make([]abi.SectorID, 0, len(sectors))
+		for _, sector := range sectors {
+			sids = append(sids, abi.SectorID{Miner: mid, Number: sector.SectorNumber})
+		}
+
+		if err := sinfos.Prefetch(ctx, sids); err != nil {
+			log.Warnw("prefetch sector infos, fallback to the per sector queries", "miner", mid, "err", err)
+			sinfos = nil
+		}
+	}
+
+	results := make([]error, len(sectors))
 	var wg sync.WaitGroup
 	wg.Add(len(sectors))
 
@@ -209,16 +348,17 @@ func (p *Proving) Provable(
 		case throttle <- struct{}{}:
 		case <-ctx.Done():
 			// After the overtime, walk through the cycle and do not turn on the thread check.
-			results[ti] = fmt.Sprintf("waiting for check worker: %s", ctx.Err())
+			results[ti] = fmt.Errorf("waiting for check worker: %w", ctx.Err())
 			wg.Done()
 			continue
 		}
 
 		go func(i int) {
 			defer wg.Done()
-			defer func() {
+
+			release := func() {
 				<-throttle
-			}()
+			}
 
 			ctx, cancel := context.WithCancel(ctx)
 			defer cancel()
@@ -229,20 +369,50 @@ func (p *Proving) Provable(
 				ID:        abi.SectorID{Miner: mid, Number: sector.SectorNumber},
 				ProofType: sector.SealProof,
 			}
-			err := p.SingleProvable(ctx, postProofType, sref, sector.SectorKey != nil, nil, strict, stateCheck)
-			if err == nil {
+			check := func() error {
+				return p.singleProvable(ctx, postProofType, sref, sector.SectorKey != nil, nil, strict, stateCheck, sinfos)
+			}
+
+			if opts.SectorTimeout <= 0 {
+				defer release()
+				results[i] = check()
 				return
 			}
 
-			results[i] = err.Error()
+			// the check runs in its own goroutine, so that a read blocked in the storage, which won't be interrupted
+			// by the context, doesn't hold the result of the sector.
+			// The slot is released only after the check returns, the timed out checks are still counted in
+			// the limit, the sectors left will wait for the partition check timeout, if any, when all the slots are stuck.
+			errCh := make(chan error, 1)
+			go func() {
+				defer release()
+				errCh <- check()
+			}()
+
+			timer := time.NewTimer(opts.SectorTimeout)
+			defer timer.Stop()
+
+			select {
+			case err := <-errCh:
+				results[i] = err
+
+			case <-timer.C:
+				cancel()
+				results[i] = provableErr(
+					core.ErrProvableTimeout,
+					"%s after %s",
+					core.ProvableTimeoutReason,
+					opts.SectorTimeout,
+				)
+			}
 		}(ti)
 	}
 
 	wg.Wait()
 
-	bad := map[abi.SectorNumber]string{}
+	bad := map[abi.SectorNumber]error{}
 	for ri := range results {
-		if results[ri] != "" {
+		if results[ri] != nil {
 			bad[sectors[ri].SectorNumber] = results[ri]
 		}
 	}
@@ -120,6 +120,24 @@ func (rm *RebuildManager) Allocate(ctx context.Context, spec core.AllocateSector
 	return allocated, nil
 }
 
+func (rm *RebuildManager) All(ctx context.Context) ([]core.SectorRebuildInfo, error) {
+	var all []core.SectorRebuildInfo
+	err := rm.loadAndUpdate(ctx, func(infos *RebuildInfos) bool {
+		for ai := range infos.Actors {
+			for num := range infos.Actors[ai].Infos {
+				all = append(all, infos.Actors[ai].Infos[num])
+			}
+		}
+
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load rebuild infos: %w", err)
+	}
+
+	return all, nil
+}
+
 func (rm *RebuildManager) loadAndUpdate(ctx context.Context, modify func(infos *RebuildInfos) bool) error {
 	rm.kvMu.Lock()
 	defer rm.kvMu.Unlock()
@@ -46,6 +46,8 @@ func NewSnapUpAllocator(
 		kv: allocStore,
 
 		indexer: indexer,
+
+		paused: map[abi.ActorID]bool{},
 	}
 
 	return allocator, nil
@@ -60,6 +62,9 @@ type SnapUpAllocator struct {
 	kv   kvstore.KVStore
 
 	indexer core.SectorIndexer
+
+	pausedMu sync.RWMutex
+	paused   map[abi.ActorID]bool
 }
 
 func (s *SnapUpAllocator) PreFetch(
@@ -67,6 +72,16 @@ func (s *SnapUpAllocator) PreFetch(
 	mid abi.ActorID,
 	dlindex *uint64,
 ) (count uint64, diff uint64, err error) {
+	paused, err := s.Paused(ctx, mid)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if paused {
+		log.Debugw("snapup paused, skip prefetching", "mid", mid)
+		return 0, 0, nil
+	}
+
 	maddr, err := address.NewIDAddress(uint64(mid))
 	if err != nil {
 		return 0, 0, fmt.Errorf("invalid miner actor id %d: %w", mid, err)
@@ -148,7 +163,17 @@ func (s *SnapUpAllocator) Allocate(ctx context.Context, spec core.AllocateSector
 		spec.AllowedMiners,
 		spec.AllowedProofTypes,
 		func(mcfg modules.MinerConfig) bool {
-			return mcfg.SnapUp.Enabled
+			if !mcfg.SnapUp.Enabled {
+				return false
+			}
+
+			paused, err := s.Paused(ctx, mcfg.Actor)
+			if err != nil {
+				log.Warnw("check if snapup paused", "mid", mcfg.Actor, "err", err)
+				return false
+			}
+
+			return !paused
 		},
 		"snapup",
 	)
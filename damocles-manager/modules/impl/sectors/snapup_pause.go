@@ -0,0 +1,68 @@
+package sectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/kvstore"
+)
+
+func kvKeyForPausedMiner(mid abi.ActorID) kvstore.Key {
+	return kvstore.Key("paused/" + strconv.FormatUint(uint64(mid), 10))
+}
+
+// Pause stops the snapup allocator from fetching & allocating candidates for the miner,
+// the flag is persisted in the alloc kv store so that it survives restarts.
+func (s *SnapUpAllocator) Pause(ctx context.Context, mid abi.ActorID) error {
+	if err := s.kv.Put(ctx, kvKeyForPausedMiner(mid), []byte{1}); err != nil {
+		return fmt.Errorf("save paused flag: %w", err)
+	}
+
+	s.pausedMu.Lock()
+	s.paused[mid] = true
+	s.pausedMu.Unlock()
+
+	log.Infow("snapup paused", "mid", mid)
+	return nil
+}
+
+// Resume undoes Pause.
+func (s *SnapUpAllocator) Resume(ctx context.Context, mid abi.ActorID) error {
+	err := s.kv.Del(ctx, kvKeyForPausedMiner(mid))
+	if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return fmt.Errorf("remove paused flag: %w", err)
+	}
+
+	s.pausedMu.Lock()
+	s.paused[mid] = false
+	s.pausedMu.Unlock()
+
+	log.Infow("snapup resumed", "mid", mid)
+	return nil
+}
+
+// Paused tells if the snapup for the miner has been paused.
+func (s *SnapUpAllocator) Paused(ctx context.Context, mid abi.ActorID) (bool, error) {
+	s.pausedMu.RLock()
+	paused, ok := s.paused[mid]
+	s.pausedMu.RUnlock()
+	if ok {
+		return paused, nil
+	}
+
+	err := s.kv.Peek(ctx, kvKeyForPausedMiner(mid), kvstore.NilF)
+	if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return false, fmt.Errorf("load paused flag: %w", err)
+	}
+
+	paused = err == nil
+	s.pausedMu.Lock()
+	s.paused[mid] = paused
+	s.pausedMu.Unlock()
+
+	return paused, nil
+}
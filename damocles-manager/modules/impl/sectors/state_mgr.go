@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/filecoin-project/go-state-types/abi"
 	managerplugin "github.com/ipfs-force-community/damocles/manager-plugin"
@@ -96,6 +97,7 @@ func (sm *StateManager) save(
 		return fmt.Errorf("save: %w", err)
 	}
 
+	state.UpdatedAt = core.SectorUpdatedAt(time.Now().Unix())
 	b, err := json.Marshal(state)
 	if err != nil {
 		return fmt.Errorf("marshal state: %w", err)
@@ -249,6 +251,7 @@ func (sm *StateManager) InitWith(
 				state := core.SectorState{
 					ID:         sector.ID,
 					SectorType: sector.ProofType,
+					CreatedAt:  core.SectorCreatedAt(time.Now().Unix()),
 				}
 				key := makeSectorKey(sector.ID)
 				err = kv.Peek(ctx, key, func([]byte) error { return nil })
@@ -365,6 +368,10 @@ func (sm *StateManager) Finalize(ctx context.Context, sid abi.SectorID, onFinali
 	}
 
 	state.Finalized = true
+	if state.FinalizedAt == 0 {
+		state.FinalizedAt = core.SectorFinalizedAt(time.Now().Unix())
+	}
+
 	if err := sm.save(ctx, key, state, core.WorkerOffline); err != nil {
 		return fmt.Errorf("save info into offline store: %w", err)
 	}
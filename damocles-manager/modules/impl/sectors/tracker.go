@@ -56,16 +56,15 @@ func (t *Tracker) getPrivateInfo(
 		return nil, core.PrivateSectorInfo{}, fmt.Errorf("get location for %s: %w", util.FormatSectorID(sref.ID), err)
 	}
 
-	var cache string
-	var sealed string
+	cacheType, sealedType := util.SectorPathTypeCache, util.SectorPathTypeSealed
 	if upgrade {
-		cache = util.SectorPath(util.SectorPathTypeUpdateCache, sref.ID)
-		sealed = util.SectorPath(util.SectorPathTypeUpdate, sref.ID)
-	} else {
-		cache = util.SectorPath(util.SectorPathTypeCache, sref.ID)
-		sealed = util.SectorPath(util.SectorPathTypeSealed, sref.ID)
+		cacheType, sealedType = util.SectorPathTypeUpdateCache, util.SectorPathTypeUpdate
 	}
 
+	// the files are laid out by the stores holding them
+	cache := util.StoreSectorPath(objins.cacheDir.InstanceConfig(ctx).Meta, cacheType, sref.ID)
+	sealed := util.StoreSectorPath(objins.sealedFile.InstanceConfig(ctx).Meta, sealedType, sref.ID)
+
 	return objins, core.PrivateSectorInfo{
 		Accesses:         objins.info,
 		CacheDirURI:      cache,
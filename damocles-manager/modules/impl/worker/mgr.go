@@ -45,10 +45,16 @@ func (m *Manager) Load(ctx context.Context, name string) (core.WorkerPingInfo, e
 		return winfo, fmt.Errorf("load worker info: %w", err)
 	}
 
+	sanitizePingInfo(&winfo)
 	return winfo, nil
 }
 
 func (m *Manager) Update(ctx context.Context, winfo core.WorkerPingInfo) error {
+	if winfo.Info.Name == "" {
+		return fmt.Errorf("worker name is required")
+	}
+
+	sanitizePingInfo(&winfo)
 	b, err := json.Marshal(winfo)
 	if err != nil {
 		return fmt.Errorf("marshal worker info: %w", err)
@@ -76,6 +82,7 @@ func (m *Manager) All(ctx context.Context, filter func(*core.WorkerPingInfo) boo
 			return nil, fmt.Errorf("scan state item of key %s: %w", string(iter.Key()), err)
 		}
 
+		sanitizePingInfo(&winfo)
 		if filter == nil || filter(&winfo) {
 			infos = append(infos, winfo)
 		}
@@ -92,6 +99,13 @@ func (m *Manager) Remove(ctx context.Context, name string) error {
 	return nil
 }
 
+// sanitizePingInfo clamps the ping timestamps in the future, which may be caused by clock skew
+func sanitizePingInfo(winfo *core.WorkerPingInfo) {
+	if now := time.Now().Unix(); winfo.LastPing > now {
+		winfo.LastPing = now
+	}
+}
+
 func (m *Manager) doMetrics(ctx context.Context) {
 	ticker := time.NewTicker(time.Second * 60)
 	go func() {
@@ -3,6 +3,7 @@ package policy
 import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/network"
 	"github.com/filecoin-project/venus/venus-shared/actors/policy"
 )
 
@@ -18,9 +19,19 @@ var (
 
 func GetPreCommitChallengeDelay() abi.ChainEpoch {
 	// TODO: remove the guard code here
-	if NetParams.PreCommitChallengeDelay > 0 {
+	if NetParams != nil && NetParams.PreCommitChallengeDelay > 0 {
 		return NetParams.PreCommitChallengeDelay
 	}
 
 	return policy.GetPreCommitChallengeDelay()
 }
+
+// GetWinningPoStSectorSetLookback is resolved here together with the other network dependent parameters,
+// so that customized networks could be handled in one place.
+func GetWinningPoStSectorSetLookback(nv network.Version) abi.ChainEpoch {
+	if nv <= network.Version3 {
+		return 10
+	}
+
+	return ChainFinality
+}
@@ -0,0 +1,176 @@
+package sealer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/miner"
+	"github.com/filecoin-project/venus/venus-shared/types"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/chain"
+)
+
+// rateLimiter is a token bucket, the parameters could be changed on the fly.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+func (l *rateLimiter) wait(ctx context.Context, rate float64, burst int) error {
+	if burst < 1 {
+		burst = 1
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.last.IsZero() || l.rate != rate || l.burst != burst {
+		l.rate = rate
+		l.burst = burst
+		l.tokens = float64(burst)
+		l.last = now
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * rate
+	if limit := float64(burst); l.tokens > limit {
+		l.tokens = limit
+	}
+	l.last = now
+
+	// take the token in advance, the tokens could go negative to queue up the waiters
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limitedChainAPI throttles the chain-heavy calls issued by the sealer,
+// so that the audits of many miners won't overwhelm the shared chain node.
+type limitedChainAPI struct {
+	chain.API
+
+	scfg *modules.SafeConfig
+
+	mu       sync.Mutex
+	limiters map[abi.ActorID]*rateLimiter
+}
+
+// LimitedChainAPI is the chain api with the chain-heavy calls throttled by ChainCallRateLimit in the common config,
+// shared by the sealer and the components it calls, e.g. the sector proving.
+type LimitedChainAPI chain.API
+
+// NewLimitedChainAPI wraps the chain api with the limits of ChainCallRateLimit.
+func NewLimitedChainAPI(scfg *modules.SafeConfig, capi chain.API) LimitedChainAPI {
+	return newLimitedChainAPI(scfg, capi)
+}
+
+func newLimitedChainAPI(scfg *modules.SafeConfig, capi chain.API) *limitedChainAPI {
+	return &limitedChainAPI{
+		API:      capi,
+		scfg:     scfg,
+		limiters: map[abi.ActorID]*rateLimiter{},
+	}
+}
+
+func (l *limitedChainAPI) wait(ctx context.Context, maddr address.Address) error {
+	cfg := l.scfg.MustCommonConfig().ChainCallRateLimit
+	if cfg.Rate <= 0 {
+		return nil
+	}
+
+	// all of the calls share the limiter keyed by 0 if not limited per miner
+	var key abi.ActorID
+	if cfg.PerMiner {
+		if mid, err := address.IDFromAddress(maddr); err == nil {
+			key = abi.ActorID(mid)
+		}
+	}
+
+	l.mu.Lock()
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = &rateLimiter{}
+		l.limiters[key] = limiter
+	}
+	l.mu.Unlock()
+
+	return limiter.wait(ctx, cfg.Rate, cfg.Burst)
+}
+
+func (l *limitedChainAPI) StateMinerAllocated(
+	ctx context.Context,
+	maddr address.Address,
+	tsk types.TipSetKey,
+) (*bitfield.BitField, error) {
+	if err := l.wait(ctx, maddr); err != nil {
+		return nil, err
+	}
+
+	return l.API.StateMinerAllocated(ctx, maddr, tsk)
+}
+
+func (l *limitedChainAPI) StateMinerSectors(
+	ctx context.Context,
+	maddr address.Address,
+	sectorNos *bitfield.BitField,
+	tsk types.TipSetKey,
+) ([]*miner.SectorOnChainInfo, error) {
+	if err := l.wait(ctx, maddr); err != nil {
+		return nil, err
+	}
+
+	return l.API.StateMinerSectors(ctx, maddr, sectorNos, tsk)
+}
+
+func (l *limitedChainAPI) StateMinerPartitions(
+	ctx context.Context,
+	maddr address.Address,
+	dlIdx uint64,
+	tsk types.TipSetKey,
+) ([]types.Partition, error) {
+	if err := l.wait(ctx, maddr); err != nil {
+		return nil, err
+	}
+
+	return l.API.StateMinerPartitions(ctx, maddr, dlIdx, tsk)
+}
+
+func (l *limitedChainAPI) StateSectorGetInfo(
+	ctx context.Context,
+	maddr address.Address,
+	n abi.SectorNumber,
+	tsk types.TipSetKey,
+) (*miner.SectorOnChainInfo, error) {
+	if err := l.wait(ctx, maddr); err != nil {
+		return nil, err
+	}
+
+	return l.API.StateSectorGetInfo(ctx, maddr, n, tsk)
+}
@@ -0,0 +1,84 @@
+package sealer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
+)
+
+// emitSectorEvent posts the event to the configured webhook in the background,
+// the delivery is best-effort and never blocks the caller.
+func (s *Sealer) emitSectorEvent(sid abi.SectorID, state string) {
+	cfg := s.scfg.MustCommonConfig().SectorEventWebhook
+	if cfg.URL == "" {
+		return
+	}
+
+	event := core.SectorEvent{
+		ID:        sid,
+		State:     state,
+		Timestamp: time.Now(),
+	}
+
+	go deliverSectorEvent(cfg, event)
+}
+
+func deliverSectorEvent(cfg modules.SectorEventWebhookConfig, event core.SectorEvent) {
+	elog := log.With("sector", util.FormatSectorID(event.ID), "event", event.State)
+	body, err := json.Marshal(event)
+	if err != nil {
+		elog.Errorf("marshal sector event: %s", err)
+		return
+	}
+
+	for attempt := 0; ; attempt++ {
+		err = postSectorEvent(cfg, body)
+		if err == nil {
+			return
+		}
+
+		if attempt >= cfg.MaxRetry {
+			elog.Warnf("deliver sector event, dropped after %d attempts: %s", attempt+1, err)
+			return
+		}
+
+		elog.Debugf("deliver sector event, will retry: %s", err)
+		time.Sleep(time.Duration(attempt+1) * time.Second)
+	}
+}
+
+func postSectorEvent(cfg modules.SectorEventWebhookConfig, body []byte) error {
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.Timeout))
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("construct request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package sealer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/google/uuid"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/kvstore"
+)
+
+const (
+	healthCheckTimeout = 5 * time.Second
+	healthProbePrefix  = ".damocles-health-check-"
+)
+
+// HealthCheck checks the dependencies of the sealer concurrently, each check is bounded by a short timeout,
+// so that it can be used by the liveness & readiness probes.
+func (s *Sealer) HealthCheck(ctx context.Context) (*core.HealthReport, error) {
+	stores, err := s.scfg.MustCommonConfig().GetPersistStores()
+	if err != nil {
+		return nil, fmt.Errorf("get persist stores: %w", err)
+	}
+
+	report := &core.HealthReport{
+		Stores: make([]core.HealthStatus, len(stores)),
+	}
+
+	var wg sync.WaitGroup
+	check := func(status *core.HealthStatus, name string, fn func(context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			*status = runHealthCheck(ctx, name, fn)
+		}()
+	}
+
+	check(&report.Chain, "chain", func(cctx context.Context) error {
+		_, err := s.capi.ChainHead(cctx)
+		return err
+	})
+
+	check(&report.Messager, "messager", func(cctx context.Context) error {
+		_, err := s.msgClient.Version(cctx)
+		return err
+	})
+
+	check(&report.StateStore, "state store", func(cctx context.Context) error {
+		// the zero sector id is never allocated, a not-found error still means the store is readable
+		_, err := s.state.Load(cctx, abi.SectorID{}, core.WorkerOnline)
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil
+		}
+		return err
+	})
+
+	for i := range stores {
+		name := stores[i].Name
+		check(&report.Stores[i], name, func(cctx context.Context) error {
+			return s.probeStore(cctx, name)
+		})
+	}
+
+	wg.Wait()
+
+	report.Healthy = report.Chain.Healthy && report.Messager.Healthy && report.StateStore.Healthy
+	for _, st := range report.Stores {
+		report.Healthy = report.Healthy && st.Healthy
+	}
+
+	return report, nil
+}
+
+// probeStore makes sure the store instance is writable by putting & deleting a small object,
+// the read-only ones are only required to be reachable.
+func (s *Sealer) probeStore(ctx context.Context, name string) error {
+	store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, name)
+	if err != nil {
+		return fmt.Errorf("get instance: %w", err)
+	}
+
+	info, err := store.InstanceInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("get instance info: %w", err)
+	}
+
+	if info.Config.ReadOnly {
+		return nil
+	}
+
+	// each probe uses its own object, so that the concurrent probes, e.g. from several managers
+	// sharing the store, won't delete the object of each other
+	probeObject := healthProbePrefix + uuid.NewString()
+	if _, err := store.Put(ctx, probeObject, bytes.NewReader([]byte(name))); err != nil {
+		return fmt.Errorf("write probe object: %w", err)
+	}
+
+	if err := store.Del(ctx, probeObject); err != nil {
+		return fmt.Errorf("delete probe object: %w", err)
+	}
+
+	return nil
+}
+
+func runHealthCheck(ctx context.Context, name string, fn func(context.Context) error) core.HealthStatus {
+	cctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn(cctx)
+	}()
+
+	// do not rely on the dependencies to respect the context
+	var err error
+	select {
+	case err = <-errCh:
+	case <-cctx.Done():
+		err = cctx.Err()
+	}
+
+	status := core.HealthStatus{
+		Name:    name,
+		Healthy: err == nil,
+		Elapsed: time.Since(start),
+	}
+	if err != nil {
+		status.Err = err.Error()
+	}
+
+	return status
+}
@@ -0,0 +1,69 @@
+package sealer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	commpwriter "github.com/filecoin-project/go-commp-utils/writer"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+)
+
+// VerifyPiece reads the local copy of the piece, and checks if its commP matches the piece cid.
+// The data is streamed through the commP writer, so that the piece is never buffered as a whole.
+// The commP is computed here rather than in the piecestore, which shouldn't depend on the proof bindings.
+func (s *Sealer) VerifyPiece(ctx context.Context, pieceCid cid.Cid) (*core.PieceVerification, error) {
+	r, err := s.pieceStore.Get(ctx, pieceCid)
+	if err != nil {
+		return nil, fmt.Errorf("get piece data of %s: %w", pieceCid, err)
+	}
+
+	defer r.Close()
+
+	computed, err := computePieceCID(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("compute piece cid of %s: %w", pieceCid, err)
+	}
+
+	verification := &core.PieceVerification{
+		PieceCid:    pieceCid,
+		Match:       computed.PieceCID.Equals(pieceCid),
+		PayloadSize: computed.PayloadSize,
+		PieceSize:   computed.PieceSize,
+	}
+
+	if !verification.Match {
+		verification.Computed = &computed.PieceCID
+	}
+
+	return verification, nil
+}
+
+func computePieceCID(ctx context.Context, r io.Reader) (commpwriter.DataCIDSize, error) {
+	w := &commpwriter.Writer{}
+	buf := make([]byte, 1<<20)
+	for {
+		if err := ctx.Err(); err != nil {
+			return commpwriter.DataCIDSize{}, err
+		}
+
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return commpwriter.DataCIDSize{}, fmt.Errorf("write to commp writer: %w", werr)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return commpwriter.DataCIDSize{}, fmt.Errorf("read piece data: %w", err)
+		}
+	}
+
+	return w.Sum()
+}
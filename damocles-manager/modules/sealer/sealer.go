@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -22,6 +23,7 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/chain"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/kvstore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/logging"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/messager"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/piecestore"
 )
@@ -51,7 +53,8 @@ func sectorStateErr(err error) error {
 //revive:disable-next-line:argument-limit
 func New(
 	scfg *modules.SafeConfig,
-	capi chain.API,
+	capi LimitedChainAPI,
+	msgClient messager.API,
 	rand core.RandomnessAPI,
 	sector core.SectorManager,
 	state core.SectorStateManager,
@@ -69,6 +72,7 @@ func New(
 	return &Sealer{
 		scfg:       scfg,
 		capi:       capi,
+		msgClient:  msgClient,
 		rand:       rand,
 		sector:     sector,
 		state:      state,
@@ -84,12 +88,15 @@ func New(
 		sectorProving: sectorProving,
 
 		prover: prover,
+
+		ops: newSectorOps(),
 	}, nil
 }
 
 type Sealer struct {
 	scfg       *modules.SafeConfig
 	capi       chain.API
+	msgClient  messager.API
 	rand       core.RandomnessAPI
 	sector     core.SectorManager
 	state      core.SectorStateManager
@@ -105,6 +112,11 @@ type Sealer struct {
 	sectorProving core.SectorProving
 
 	prover core.Prover
+
+	ops *sectorOps
+
+	simulateMu sync.Mutex
+	simulating int
 }
 
 // checkSectorNumbers returns the allocated sector numbers in `sids`
@@ -397,6 +409,9 @@ func (s *Sealer) SubmitProof(
 	if err == nil {
 		ctx, _ = metrics.New(ctx, metrics.Upsert(metrics.Miner, sid.Miner.String()))
 		metrics.Record(ctx, metrics.SectorManagerCommitSector.M(1))
+		if resp.Res == core.SubmitAccepted {
+			s.emitSectorEvent(sid, core.SectorEventSealed)
+		}
 	}
 	return resp, sectorStateErr(err)
 }
@@ -422,7 +437,16 @@ func (s *Sealer) ReportState(
 			return nil, sectorStateErr(err)
 		}
 	} else {
-		if err := s.state.Update(ctx, sid, core.WorkerOnline, &req); err != nil {
+		fieldvals := []any{&req}
+		if state.LatestState == nil || state.LatestState.StateChange.Next != req.StateChange.Next {
+			fieldvals = append(fieldvals, core.SectorStateEnteredAt(time.Now().Unix()))
+		}
+
+		if req.Failure != nil {
+			fieldvals = append(fieldvals, countRetry(state.Retries, req.StateChange.Next))
+		}
+
+		if err := s.state.Update(ctx, sid, core.WorkerOnline, fieldvals...); err != nil {
 			return nil, sectorStateErr(err)
 		}
 	}
@@ -434,6 +458,20 @@ func (s *Sealer) ReportState(
 	}, nil
 }
 
+// countRetry returns a copy of the retries, with the one of the given state incremented.
+func countRetry(retries core.SectorRetries, stateName string) core.SectorRetries {
+	counted := make(core.SectorRetries, len(retries)+1)
+	for name, retry := range retries {
+		counted[name] = retry
+	}
+
+	retry := counted[stateName]
+	retry.Count++
+	retry.LastRetryAt = time.Now().Unix()
+	counted[stateName] = retry
+	return counted
+}
+
 func (s *Sealer) ReportFinalized(ctx context.Context, sid abi.SectorID) (core.Meta, error) {
 	sectorLogger(sid).Info("sector finalized")
 	if err := s.state.Finalize(ctx, sid, func(st *core.SectorState) (bool, error) {
@@ -457,6 +495,7 @@ func (s *Sealer) ReportFinalized(ctx context.Context, sid abi.SectorID) (core.Me
 		log.With("sector", util.FormatSectorID(sid)).Errorf("release reserved: %s", err)
 	}
 
+	s.emitSectorEvent(sid, core.SectorEventFinalized)
 	return core.Empty, nil
 }
 
@@ -785,6 +824,7 @@ func checkForLifetime(public core.SectorPublicInfo, pieces core.SectorPieces) bo
 }
 
 func (s *Sealer) WorkerPing(ctx context.Context, winfo core.WorkerInfo) (core.Meta, error) {
+	winfo.Name = strings.TrimSpace(winfo.Name)
 	if winfo.Name == "" {
 		return core.Empty, fmt.Errorf("worker name is required")
 	}
@@ -793,6 +833,17 @@ func (s *Sealer) WorkerPing(ctx context.Context, winfo core.WorkerInfo) (core.Me
 		return core.Empty, fmt.Errorf("worker dest is required")
 	}
 
+	if !s.scfg.MustCommonConfig().WorkerRegistry.AutoRegister {
+		_, err := s.workerMgr.Load(ctx, winfo.Name)
+		if err != nil {
+			if errors.Is(err, kvstore.ErrKeyNotFound) {
+				return core.Empty, fmt.Errorf("worker %s is not registered", winfo.Name)
+			}
+
+			return core.Empty, fmt.Errorf("load worker info: %w", err)
+		}
+	}
+
 	pingInfo := core.WorkerPingInfo{
 		Info:     winfo,
 		LastPing: time.Now().Unix(),
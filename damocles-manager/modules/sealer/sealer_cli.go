@@ -1,12 +1,18 @@
 package sealer
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-address"
@@ -14,15 +20,18 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	gtypes "github.com/filecoin-project/venus/venus-shared/types/gateway"
 	"github.com/ipfs/go-cid"
+	"github.com/samber/lo"
 
 	"github.com/filecoin-project/venus/pkg/clock"
 	"github.com/filecoin-project/venus/venus-shared/actors/builtin"
-	specpolicy "github.com/filecoin-project/venus/venus-shared/actors/policy"
 	"github.com/filecoin-project/venus/venus-shared/types"
 
 	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/policy"
 	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/chain"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/kvstore"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/messager"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/ver"
 )
@@ -32,13 +41,261 @@ func (s *Sealer) ListSectors(
 	ws core.SectorWorkerState,
 	job core.SectorWorkerJob,
 ) ([]*core.SectorState, error) {
-	return s.state.All(ctx, ws, job)
+	return s.ListSectorsEx(ctx, ws, job, core.ListSectorsOptions{})
+}
+
+// ListSectorsEx lists the sectors matching the options.
+func (s *Sealer) ListSectorsEx(
+	ctx context.Context,
+	ws core.SectorWorkerState,
+	job core.SectorWorkerJob,
+	opts core.ListSectorsOptions,
+) ([]*core.SectorState, error) {
+	if opts.IsEmpty() {
+		return s.state.All(ctx, ws, job)
+	}
+
+	var sectors []*core.SectorState
+	err := s.state.ForEach(ctx, ws, job, func(ss core.SectorState) error {
+		if opts.Match(&ss) {
+			sectors = append(sectors, &ss)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate %s sectors: %w", ws, err)
+	}
+
+	return sectors, nil
+}
+
+// ReconcileMinerSectors compares the full set of the on-chain sectors of the miner with its local sector states.
+// Local sectors which are still sealing will be reported in LocalOnly.
+func (s *Sealer) ReconcileMinerSectors(
+	ctx context.Context,
+	mid abi.ActorID,
+) (*core.MinerSectorsReconciliation, error) {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	sinfos, err := s.capi.StateMinerSectors(ctx, maddr, nil, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get on-chain sectors: %w", err)
+	}
+
+	onChainNums := make([]uint64, 0, len(sinfos))
+	for _, sinfo := range sinfos {
+		onChainNums = append(onChainNums, uint64(sinfo.SectorNumber))
+	}
+
+	var localNums []uint64
+	for _, ws := range []core.SectorWorkerState{core.WorkerOnline, core.WorkerOffline} {
+		err := s.state.ForEach(ctx, ws, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+			if ss.ID.Miner == mid && !ss.Removed {
+				localNums = append(localNums, uint64(ss.ID.Number))
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("iterate %s sectors: %w", ws, err)
+		}
+	}
+
+	onChain := bitfield.NewFromSet(onChainNums)
+	local := bitfield.NewFromSet(localNums)
+
+	res := &core.MinerSectorsReconciliation{
+		Miner: mid,
+	}
+
+	if res.OnChainOnly, err = bitfield.SubtractBitField(onChain, local); err != nil {
+		return nil, fmt.Errorf("calculate on-chain only sectors: %w", err)
+	}
+
+	if res.LocalOnly, err = bitfield.SubtractBitField(local, onChain); err != nil {
+		return nil, fmt.Errorf("calculate local only sectors: %w", err)
+	}
+
+	if res.Both, err = bitfield.IntersectBitField(onChain, local); err != nil {
+		return nil, fmt.Errorf("calculate sectors in both: %w", err)
+	}
+
+	return res, nil
+}
+
+// SectorsChangedSince returns the states of both online and offline sectors changed at or after the given time,
+// ordered by the time of the change, so that the pollers can sync the deltas only.
+// The changes are recorded in seconds, the sectors changed within the second of the given time are included.
+func (s *Sealer) SectorsChangedSince(ctx context.Context, since time.Time) ([]*core.SectorState, error) {
+	after := core.SectorUpdatedAt(since.Unix())
+
+	var sectors []*core.SectorState
+	for _, ws := range []core.SectorWorkerState{core.WorkerOnline, core.WorkerOffline} {
+		err := s.state.ForEach(ctx, ws, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+			if ss.UpdatedAt >= after {
+				sectors = append(sectors, &ss)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("iterate %s sectors: %w", ws, err)
+		}
+	}
+
+	sort.Slice(sectors, func(i, j int) bool { return sectors[i].UpdatedAt < sectors[j].UpdatedAt })
+	return sectors, nil
+}
+
+// ListSectorsByDeadline returns the local states of the sectors assigned to the given deadline.
+func (s *Sealer) ListSectorsByDeadline(
+	ctx context.Context,
+	mid abi.ActorID,
+	deadlineIdx uint64,
+) ([]*core.SectorState, error) {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	partitions, err := s.capi.StateMinerPartitions(ctx, maddr, deadlineIdx, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get partitions for deadline %d: %w", deadlineIdx, err)
+	}
+
+	all := bitfield.New()
+	for pi := range partitions {
+		all, err = bitfield.MergeBitFields(all, partitions[pi].AllSectors)
+		if err != nil {
+			return nil, fmt.Errorf("merge sectors of partition #%d: %w", pi, err)
+		}
+	}
+
+	var sectors []*core.SectorState
+	for _, ws := range []core.SectorWorkerState{core.WorkerOnline, core.WorkerOffline} {
+		err := s.state.ForEach(ctx, ws, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+			if ss.ID.Miner != mid {
+				return nil
+			}
+
+			in, err := all.IsSet(uint64(ss.ID.Number))
+			if err != nil {
+				return fmt.Errorf("check sector in deadline: %w", err)
+			}
+
+			if in {
+				sectors = append(sectors, &ss)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("iterate %s sectors: %w", ws, err)
+		}
+	}
+
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i].ID.Number < sectors[j].ID.Number
+	})
+
+	return sectors, nil
+}
+
+// AbandonSector gives up an in-progress sector, the deals & store reservations held by it are released,
+// and the sector is moved offline with the reason recorded, so that it could be restored or removed later.
+// Unlike RemoveSector, it doesn't require the sealed files to exist.
+func (s *Sealer) AbandonSector(ctx context.Context, sid abi.SectorID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("reason is required")
+	}
+
+	release, err := s.ops.acquire(sid, "abandon")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	state, err := s.state.Load(ctx, sid, core.WorkerOnline)
+	if err != nil {
+		return sectorStateErr(err)
+	}
+
+	if state.Finalized {
+		return fmt.Errorf("sector has been finalized")
+	}
+
+	if _, err := s.ReportAborted(ctx, sid, reason); err != nil {
+		return fmt.Errorf("abort sector: %w", err)
+	}
+
+	sectorLogger(sid).Infow("sector abandoned", "reason", reason)
+	return nil
 }
 
 func (s *Sealer) RestoreSector(ctx context.Context, sid abi.SectorID, forced bool) (core.Meta, error) {
-	var onRestore func(st *core.SectorState) (bool, error)
+	return s.RestoreSectorEx(ctx, sid, core.RestoreSectorOptions{Forced: forced})
+}
+
+// RestoreSectorEx restores the sector, and points its index to the target store if given.
+// The sector files are checked in the target store before restoring, and the restore is undone
+// if the index can't be updated.
+func (s *Sealer) RestoreSectorEx(
+	ctx context.Context,
+	sid abi.SectorID,
+	opts core.RestoreSectorOptions,
+) (core.Meta, error) {
+	release, err := s.ops.acquire(sid, "restore")
+	if err != nil {
+		return core.Empty, err
+	}
+	defer release()
+
+	var indexer core.SectorTypedIndexer
+	if opts.TargetStore != "" {
+		target, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, opts.TargetStore)
+		if err != nil {
+			return core.Empty, fmt.Errorf("get objstore instance %s: %w", opts.TargetStore, err)
+		}
+
+		state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+		if err != nil {
+			return core.Empty, sectorStateErr(err)
+		}
+
+		if err := checkSectorFilesIn(ctx, target, state); err != nil {
+			return core.Empty, fmt.Errorf("check sector files in %s: %w", opts.TargetStore, err)
+		}
+
+		indexer = s.sectorIdxer.Normal()
+		if state.Upgraded {
+			indexer = s.sectorIdxer.Upgrade()
+		}
+	}
+
+	forced := opts.Forced
+	var abortReason string
+
+	onRestore := func(st *core.SectorState) (bool, error) {
+		// forced restore still requires the sector to be present and not removed
+		if st.Removed {
+			return false, fmt.Errorf("sector has been removed, can not be restored")
+		}
+
+		abortReason = st.AbortReason
+		return true, nil
+	}
+
 	if !forced {
 		onRestore = func(st *core.SectorState) (bool, error) {
+			if st.Removed {
+				return false, fmt.Errorf("sector has been removed, can not be restored")
+			}
+
 			if len(st.PieceInfos()) != 0 {
 				return false, fmt.Errorf("sector with deals can not be normally restored")
 			}
@@ -47,336 +304,2416 @@ func (s *Sealer) RestoreSector(ctx context.Context, sid abi.SectorID, forced boo
 				return false, fmt.Errorf("sector is not aborted, can not be normally restored")
 			}
 
+			abortReason = st.AbortReason
 			st.AbortReason = ""
 			return true, nil
 		}
 	}
 
-	err := s.state.Restore(ctx, sid, onRestore)
+	err = s.state.Restore(ctx, sid, onRestore)
+	if err != nil {
+		return core.Empty, sectorStateErr(err)
+	}
+
+	if indexer == nil {
+		return core.Empty, nil
+	}
+
+	err = indexer.Update(ctx, sid, core.SectorAccessStores{
+		SealedFile: opts.TargetStore,
+		CacheDir:   opts.TargetStore,
+	})
+	if err != nil {
+		undoErr := s.state.Finalize(ctx, sid, func(st *core.SectorState) (bool, error) {
+			st.AbortReason = abortReason
+			return true, nil
+		})
+		if undoErr != nil {
+			sectorLogger(sid).Errorf("undo the restore on failure of updating the index: %s", undoErr)
+		}
+
+		return core.Empty, fmt.Errorf("update sector index to %s: %w", opts.TargetStore, err)
+	}
+
+	sectorLogger(sid).Infow("sector index updated on restore", "store", opts.TargetStore)
+	return core.Empty, nil
+}
+
+// checkSectorFilesIn makes sure that the sealed file & the cache files of the sector are in the store.
+func checkSectorFilesIn(ctx context.Context, store objstore.Store, state *core.SectorState) error {
+	ssize, err := state.SectorType.SectorSize()
+	if err != nil {
+		return fmt.Errorf("get sector size: %w", err)
+	}
+
+	cacheType, sealedType := util.SectorPathTypeCache, util.SectorPathTypeSealed
+	if state.Upgraded {
+		cacheType, sealedType = util.SectorPathTypeUpdateCache, util.SectorPathTypeUpdate
+	}
+
+	meta := store.InstanceConfig(ctx).Meta
+	paths := append(
+		[]string{util.StoreSectorPath(meta, sealedType, state.ID)},
+		util.CachedFilesForSectorSize(util.StoreSectorPath(meta, cacheType, state.ID), ssize)...,
+	)
+
+	for _, p := range paths {
+		if _, err := store.Stat(ctx, p); err != nil {
+			return fmt.Errorf("stat %s: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// RestoreAborted restores all of the aborted sectors of the miner, the ones with deals are only restored if forced.
+func (s *Sealer) RestoreAborted(ctx context.Context, mid abi.ActorID, forced bool) ([]core.SectorRestoreResult, error) {
+	var sids []abi.SectorID
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(st core.SectorState) error {
+		if st.ID.Miner != mid || st.AbortReason == "" || st.Removed {
+			return nil
+		}
+
+		if !forced && len(st.PieceInfos()) != 0 {
+			return nil
+		}
+
+		sids = append(sids, st.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate offline sectors: %w", err)
+	}
+
+	results := make([]core.SectorRestoreResult, 0, len(sids))
+	for _, sid := range sids {
+		res := core.SectorRestoreResult{ID: sid}
+		if _, err := s.RestoreSector(ctx, sid, forced); err != nil {
+			res.Err = err.Error()
+		}
+
+		results = append(results, res)
+	}
+
+	return results, nil
+}
+
+// ReplaySectorStage sends the online sector back to the given stage of the sealing pipeline, by clearing the
+// states produced in & after the stage. The stages which have already been committed on chain can not be replayed.
+//
+// The worker is not told about the replay directly. With the message cids cleared and nothing left to send,
+// the next PollPreCommitState / PollProofState call of the worker gets OnChainStateFailed, and the worker
+// re-submits from its own copy of the stage outputs, which are persisted again through the cleared fields.
+// Thus the messages being replaced must have failed, otherwise the sector may end up with two messages.
+func (s *Sealer) ReplaySectorStage(ctx context.Context, sid abi.SectorID, stage core.SectorReplayStage) error {
+	if !lo.Contains(core.SectorReplayStages, stage) {
+		return fmt.Errorf("stage %q is not allowed to be replayed, should be one of %v", stage, core.SectorReplayStages)
+	}
+
+	release, err := s.ops.acquire(sid, "replay stage")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	state, err := s.state.Load(ctx, sid, core.WorkerOnline)
+	if err != nil {
+		return sectorStateErr(err)
+	}
+
+	if state.AbortReason != "" || bool(state.Finalized) || bool(state.Removed) {
+		return fmt.Errorf("sector is aborted, finalized or removed")
+	}
+
+	if state.Upgraded || state.Imported || state.NeedRebuild {
+		return fmt.Errorf("sector is not in the sealing pipeline")
+	}
+
+	// the messages in the queue of the commitment manager will be sent with the current states
+	if state.MessageInfo.NeedSend {
+		return fmt.Errorf("sector is waiting for the messages to be sent")
+	}
+
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return fmt.Errorf("construct miner address: %w", err)
+	}
+
+	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return fmt.Errorf("get sector info: %w", err)
+	}
+
+	if sinfo != nil {
+		return fmt.Errorf("sector has been proven on chain")
+	}
+
+	if err := s.checkReplayMessage(ctx, "prove commit", state.MessageInfo.CommitCid); err != nil {
+		return err
+	}
+
+	msgInfo := state.MessageInfo
+	msgInfo.CommitCid = nil
+	fieldvals := []any{(*core.ProofInfo)(nil)}
+
+	switch stage {
+	case core.SectorReplayPreCommit:
+		pci, err := s.capi.StateSectorPreCommitInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+		if err != nil {
+			return fmt.Errorf("get pre commit info: %w", err)
+		}
+
+		if pci != nil {
+			return fmt.Errorf("sector has been pre committed on chain")
+		}
+
+		if err := s.checkReplayMessage(ctx, "pre commit", state.MessageInfo.PreCommitCid); err != nil {
+			return err
+		}
+
+		msgInfo.PreCommitCid = nil
+		fieldvals = append(fieldvals, (*core.PreCommitInfo)(nil), (*core.Seed)(nil))
+
+	case core.SectorReplaySeed:
+		if state.Pre == nil {
+			return fmt.Errorf("sector has not been pre committed")
+		}
+
+		fieldvals = append(fieldvals, (*core.Seed)(nil))
+
+	case core.SectorReplayProveCommit:
+		if state.Seed == nil {
+			return fmt.Errorf("sector has not got the seed")
+		}
+	}
+
+	fieldvals = append(fieldvals, msgInfo)
+	if err := s.state.Update(ctx, sid, core.WorkerOnline, fieldvals...); err != nil {
+		return sectorStateErr(err)
+	}
+
+	return nil
+}
+
+// checkReplayMessage makes sure that the message to be cleared by a replay has failed,
+// a message which is pending in the mpool or has landed on chain can not be dropped.
+func (s *Sealer) checkReplayMessage(ctx context.Context, kind string, mcid *cid.Cid) error {
+	if mcid == nil {
+		return nil
+	}
+
+	msg, err := s.msgClient.GetMessageByUid(ctx, mcid.String())
+	if err != nil {
+		return fmt.Errorf("get %s message %s: %w", kind, mcid, err)
+	}
+
+	switch {
+	case messager.MessageFailed(msg.State):
+		return nil
+
+	case msg.State == messager.MessageState.OnChainMsg:
+		return fmt.Errorf("%s message %s has landed on chain", kind, mcid)
+
+	default:
+		return fmt.Errorf(
+			"%s message %s is still pending, state %s",
+			kind,
+			mcid,
+			messager.MessageStateToString(msg.State),
+		)
+	}
+}
+
+func (s *Sealer) CheckProvable(
+	ctx context.Context,
+	mid abi.ActorID,
+	postProofType abi.RegisteredPoStProof,
+	sectors []builtin.ExtendedSectorInfo,
+	strict, stateCheck bool,
+) (map[abi.SectorNumber]string, error) {
+	return s.sectorProving.Provable(ctx, mid, postProofType, sectors, strict, stateCheck)
+}
+
+// CheckProvableEx is CheckProvable with the options overriding the proving config for this run.
+func (s *Sealer) CheckProvableEx(
+	ctx context.Context,
+	mid abi.ActorID,
+	postProofType abi.RegisteredPoStProof,
+	sectors []builtin.ExtendedSectorInfo,
+	strict, stateCheck bool,
+	opts core.ProvableOptions,
+) (map[abi.SectorNumber]string, error) {
+	return s.sectorProving.ProvableEx(ctx, mid, postProofType, sectors, strict, stateCheck, opts)
+}
+
+// CheckProvableMixed is CheckProvable for the sectors of different seal proof types, the sectors are grouped by
+// the post proof types derived from their own seal proof types, and checked group by group.
+func (s *Sealer) CheckProvableMixed(
+	ctx context.Context,
+	mid abi.ActorID,
+	sectors []builtin.ExtendedSectorInfo,
+	strict, stateCheck bool,
+	opts core.ProvableOptions,
+) (map[abi.SectorNumber]string, error) {
+	nv, err := s.capi.StateNetworkVersion(ctx, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get network version: %w", err)
+	}
+
+	var postProofTypes []abi.RegisteredPoStProof
+	groups := map[abi.RegisteredPoStProof][]builtin.ExtendedSectorInfo{}
+	for _, sector := range sectors {
+		postProofType, err := sector.SealProof.RegisteredWindowPoStProofByNetworkVersion(nv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seal proof type %d of sector %d: %w", sector.SealProof, sector.SectorNumber, err)
+		}
+
+		if _, ok := groups[postProofType]; !ok {
+			postProofTypes = append(postProofTypes, postProofType)
+		}
+
+		groups[postProofType] = append(groups[postProofType], sector)
+	}
+
+	bad := map[abi.SectorNumber]string{}
+	for _, postProofType := range postProofTypes {
+		groupBad, err := s.sectorProving.ProvableEx(ctx, mid, postProofType, groups[postProofType], strict, stateCheck, opts)
+		if err != nil {
+			return nil, fmt.Errorf("check sectors of post proof type %d: %w", postProofType, err)
+		}
+
+		for num, reason := range groupBad {
+			bad[num] = reason
+		}
+	}
+
+	return bad, nil
+}
+
+// CheckProvableMulti runs the provable checks of multiple miners concurrently, the number of the miners
+// checked at the same time is limited by ParallelMinerCheckLimit in the proving config.
+// The requests of the same miner are merged.
+func (s *Sealer) CheckProvableMulti(
+	ctx context.Context,
+	requests []core.ProvableRequest,
+) (map[abi.ActorID]core.ProvableResult, error) {
+	merged := make([]core.ProvableRequest, 0, len(requests))
+	idxes := map[abi.ActorID]int{}
+	for _, req := range requests {
+		if idx, ok := idxes[req.Miner]; ok {
+			if merged[idx].PostProofType != req.PostProofType {
+				return nil, fmt.Errorf("mismatched post proof types for miner %d", req.Miner)
+			}
+
+			merged[idx].Sectors = append(merged[idx].Sectors, req.Sectors...)
+			continue
+		}
+
+		idxes[req.Miner] = len(merged)
+		merged = append(merged, req)
+	}
+
+	var (
+		mu      sync.Mutex
+		results = make(map[abi.ActorID]core.ProvableResult, len(merged))
+	)
+
+	limit := s.scfg.MustCommonConfig().Proving.ParallelMinerCheckLimit
+	err := runThrottled(ctx, len(merged), limit, func(ctx context.Context, i int) {
+		req := merged[i]
+
+		var res core.ProvableResult
+		bad, err := s.sectorProving.ProvableEx(
+			ctx,
+			req.Miner,
+			req.PostProofType,
+			req.Sectors,
+			req.Strict,
+			req.StateCheck,
+			req.Opts,
+		)
+		if err != nil {
+			res.Err = err.Error()
+		} else {
+			res.Bad = bad
+		}
+
+		mu.Lock()
+		results[req.Miner] = res
+		mu.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// runThrottled calls fn for each of the n indexes concurrently, at most limit of the calls run at the same time,
+// no limit if limit <= 0. The calls already started are always waited, even if the ctx is done.
+func runThrottled(ctx context.Context, n, limit int, fn func(ctx context.Context, i int)) error {
+	if limit <= 0 {
+		limit = n
+	}
+
+	var wg sync.WaitGroup
+	throttle := make(chan struct{}, limit)
+
+DISPATCH:
+	for i := 0; i < n; i++ {
+		select {
+		case throttle <- struct{}{}:
+		case <-ctx.Done():
+			break DISPATCH
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() {
+				<-throttle
+			}()
+
+			fn(ctx, i)
+		}(i)
+	}
+
+	wg.Wait()
+	return ctx.Err()
+}
+
+// ProvabilityReport runs the provable check over the live sectors of all the deadlines of the miner,
+// and groups the faults by deadline & category.
+// The sectors of each deadline are checked by post proof type through SectorProving.ProvableErrs with the options,
+// and the deadlines are checked concurrently, limited by ParallelMinerCheckLimit as in CheckProvableMulti.
+func (s *Sealer) ProvabilityReport(
+	ctx context.Context,
+	mid abi.ActorID,
+	strict bool,
+	opts core.ProvableOptions,
+) (*core.ProvabilityReport, error) {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	dinfo, err := s.capi.StateMinerProvingDeadline(ctx, maddr, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get proving deadline: %w", err)
+	}
+
+	nv, err := s.capi.StateNetworkVersion(ctx, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get network version: %w", err)
+	}
+
+	type provableGroup struct {
+		deadline      uint64
+		postProofType abi.RegisteredPoStProof
+		sectors       []builtin.ExtendedSectorInfo
+	}
+
+	var (
+		groups  []provableGroup
+		checked = map[uint64]int{}
+	)
+
+	for dlIdx := uint64(0); dlIdx < dinfo.WPoStPeriodDeadlines; dlIdx++ {
+		partitions, err := s.capi.StateMinerPartitions(ctx, maddr, dlIdx, types.EmptyTSK)
+		if err != nil {
+			return nil, fmt.Errorf("get partitions for deadline %d: %w", dlIdx, err)
+		}
+
+		idxes := map[abi.RegisteredPoStProof]int{}
+		for pi := range partitions {
+			sinfos, err := s.capi.StateMinerSectors(ctx, maddr, &partitions[pi].LiveSectors, types.EmptyTSK)
+			if err != nil {
+				return nil, fmt.Errorf("get sectors of deadline %d partition #%d: %w", dlIdx, pi, err)
+			}
+
+			for _, sinfo := range sinfos {
+				postProofType, err := sinfo.SealProof.RegisteredWindowPoStProofByNetworkVersion(nv)
+				if err != nil {
+					return nil, fmt.Errorf(
+						"invalid seal proof type %d of sector %d: %w",
+						sinfo.SealProof,
+						sinfo.SectorNumber,
+						err,
+					)
+				}
+
+				idx, ok := idxes[postProofType]
+				if !ok {
+					idx = len(groups)
+					idxes[postProofType] = idx
+					groups = append(groups, provableGroup{deadline: dlIdx, postProofType: postProofType})
+				}
+
+				groups[idx].sectors = append(groups[idx].sectors, util.SectorOnChainInfoToExtended(sinfo))
+				checked[dlIdx]++
+			}
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		bads     = make([]map[abi.SectorNumber]error, len(groups))
+	)
+
+	limit := s.scfg.MustCommonConfig().Proving.ParallelMinerCheckLimit
+	err = runThrottled(ctx, len(groups), limit, func(ctx context.Context, i int) {
+		group := groups[i]
+		bad, err := s.sectorProving.ProvableErrs(ctx, mid, group.postProofType, group.sectors, strict, false, opts)
+		if err != nil {
+			mu.Lock()
+			if firstErr == nil {
+				firstErr = fmt.Errorf("check provable for deadline %d: %w", group.deadline, err)
+			}
+			mu.Unlock()
+			return
+		}
+
+		bads[i] = bad
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	report := &core.ProvabilityReport{
+		Miner:       mid,
+		GeneratedAt: time.Now().Unix(),
+	}
+
+	dls := map[uint64]*core.DeadlineProvability{}
+	for i, group := range groups {
+		dl, ok := dls[group.deadline]
+		if !ok {
+			dl = &core.DeadlineProvability{
+				Deadline: group.deadline,
+				Checked:  checked[group.deadline],
+				Faults:   map[string][]core.ProvabilityFault{},
+			}
+			dls[group.deadline] = dl
+		}
+
+		for num, berr := range bads[i] {
+			category := provableFaultCategory(berr)
+			dl.Faults[category] = append(dl.Faults[category], core.ProvabilityFault{
+				Sector:   num,
+				Category: category,
+				Reason:   berr.Error(),
+			})
+		}
+
+		report.Faulty += len(bads[i])
+	}
+
+	for dlIdx := uint64(0); dlIdx < dinfo.WPoStPeriodDeadlines; dlIdx++ {
+		dl, ok := dls[dlIdx]
+		if !ok {
+			continue
+		}
+
+		for category := range dl.Faults {
+			faults := dl.Faults[category]
+			sort.Slice(faults, func(i, j int) bool {
+				return faults[i].Sector < faults[j].Sector
+			})
+		}
+
+		report.Checked += dl.Checked
+		report.Deadlines = append(report.Deadlines, *dl)
+	}
+
+	return report, nil
+}
+
+// provableFaultCategory classifies the errors returned by the provable check.
+func provableFaultCategory(err error) string {
+	switch {
+	case errors.Is(err, core.ErrProvableTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, core.ErrProvableNotIndexed):
+		return "not-indexed"
+	case errors.Is(err, core.ErrProvableMissingFile):
+		return "missing-file"
+	case errors.Is(err, core.ErrProvableWrongSize):
+		return "wrong-size"
+	case errors.Is(err, core.ErrProvableStateMismatch):
+		return "state-mismatch"
+	case errors.Is(err, core.ErrProvableProofFailed):
+		return "proof-failed"
+	default:
+		return "other"
+	}
+}
+
+// PrePoStCheck runs the provable check over the sectors which would be proven in the given deadline,
+// and returns the ones which would be skipped.
+func (s *Sealer) PrePoStCheck(
+	ctx context.Context,
+	mid abi.ActorID,
+	postProofType abi.RegisteredPoStProof,
+	deadlineIdx uint64,
+) (map[abi.SectorNumber]string, error) {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	partitions, err := s.capi.StateMinerPartitions(ctx, maddr, deadlineIdx, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get partitions for deadline %d: %w", deadlineIdx, err)
+	}
+
+	var tocheck []builtin.ExtendedSectorInfo
+	for pi := range partitions {
+		toProve, err := bitfield.SubtractBitField(partitions[pi].LiveSectors, partitions[pi].FaultySectors)
+		if err != nil {
+			return nil, fmt.Errorf("removing faults from partition #%d: %w", pi, err)
+		}
+
+		toProve, err = bitfield.MergeBitFields(toProve, partitions[pi].RecoveringSectors)
+		if err != nil {
+			return nil, fmt.Errorf("adding recoveries to partition #%d: %w", pi, err)
+		}
+
+		sinfos, err := s.capi.StateMinerSectors(ctx, maddr, &toProve, types.EmptyTSK)
+		if err != nil {
+			return nil, fmt.Errorf("get sectors of partition #%d: %w", pi, err)
+		}
+
+		for _, sinfo := range sinfos {
+			tocheck = append(tocheck, util.SectorOnChainInfoToExtended(sinfo))
+		}
+	}
+
+	if len(tocheck) == 0 {
+		return map[abi.SectorNumber]string{}, nil
+	}
+
+	return s.sectorProving.Provable(ctx, mid, postProofType, tocheck, false, false)
+}
+
+func (s *Sealer) SimulateWdPoSt(
+	_ context.Context,
+	ddlIndex, partitionIndex uint64,
+	maddr address.Address,
+	postProofType abi.RegisteredPoStProof,
+	sis []builtin.ExtendedSectorInfo,
+	rand abi.PoStRandomness,
+) error {
+	mid, err := address.IDFromAddress(maddr)
+	if err != nil {
+		return err
+	}
+
+	slog := log.With("miner", mid, "sectors", len(sis))
+
+	if !s.acquireSimulateSlot() {
+		return fmt.Errorf("simulation slots exhausted, try again later")
+	}
+
+	go func() {
+		defer s.releaseSimulateSlot()
+
+		tCtx := context.TODO()
+
+		tsStart := clock.NewSystemClock().Now()
+
+		slog.Info("mock generate window post start")
+		params := core.GenerateWindowPoStParams{
+			DeadlineIdx: ddlIndex,
+			MinerID:     abi.ActorID(mid),
+			ProofType:   postProofType,
+			Partitions:  []uint64{partitionIndex},
+			Sectors:     sis,
+			Randomness:  append(abi.PoStRandomness{}, rand...),
+		}
+		proof, skipped, err := s.prover.GenerateWindowPoSt(tCtx, params)
+		if err != nil {
+			slog.Warnf("generate window post failed: %v", err.Error())
+			return
+		}
+
+		elapsed := time.Since(tsStart)
+		slog.Infow("mock generate window post", "elapsed", elapsed, "proof-size", len(proof), "skipped", len(skipped))
+	}()
+
+	return nil
+}
+
+func (s *Sealer) acquireSimulateSlot() bool {
+	limit := s.scfg.MustCommonConfig().Proving.SimulateWdPoStLimit
+
+	s.simulateMu.Lock()
+	defer s.simulateMu.Unlock()
+
+	if limit > 0 && s.simulating >= limit {
+		return false
+	}
+
+	s.simulating++
+	return true
+}
+
+func (s *Sealer) releaseSimulateSlot() {
+	s.simulateMu.Lock()
+	s.simulating--
+	s.simulateMu.Unlock()
+}
+
+func (s *Sealer) SnapUpPreFetch(
+	ctx context.Context,
+	mid abi.ActorID,
+	dlindex *uint64,
+) (*core.SnapUpFetchResult, error) {
+	count, diff, err := s.snapup.PreFetch(ctx, mid, dlindex)
+	if err != nil {
+		return nil, fmt.Errorf("prefetch: %w", err)
+	}
+
+	return &core.SnapUpFetchResult{
+		Total: count,
+		Diff:  diff,
+	}, nil
+}
+
+func (s *Sealer) SnapUpCandidates(ctx context.Context, mid abi.ActorID) ([]*bitfield.BitField, error) {
+	return s.snapup.Candidates(ctx, mid)
+}
+
+func (s *Sealer) SnapUpPause(ctx context.Context, mid abi.ActorID) error {
+	return s.snapup.Pause(ctx, mid)
+}
+
+func (s *Sealer) SnapUpResume(ctx context.Context, mid abi.ActorID) error {
+	return s.snapup.Resume(ctx, mid)
+}
+
+func (s *Sealer) SnapUpCancelCommitment(ctx context.Context, sid abi.SectorID) error {
+	s.snapup.CancelCommitment(ctx, sid)
+	return nil
+}
+
+func (s *Sealer) ProvingSectorInfo(ctx context.Context, sid abi.SectorID) (core.ProvingSectorInfo, error) {
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return core.ProvingSectorInfo{}, fmt.Errorf("invalid mienr actor id: %w", err)
+	}
+
+	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return core.ProvingSectorInfo{}, fmt.Errorf("get sector info: %w", err)
+	}
+
+	private, err := s.sectorProving.SinglePubToPrivateInfo(ctx, sid.Miner, util.SectorOnChainInfoToExtended(sinfo), nil)
+	if err != nil {
+		return core.ProvingSectorInfo{}, fmt.Errorf("get private sector info: %w", err)
+	}
+
+	return core.ProvingSectorInfo{
+		OnChain: *sinfo,
+		Private: private,
+	}, nil
+}
+
+// CheckSectorProvable runs the provable check on the single sector, with its info resolved from the chain.
+func (s *Sealer) CheckSectorProvable(
+	ctx context.Context,
+	sid abi.SectorID,
+	strict bool,
+) (*core.SectorProvability, error) {
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get sector info: %w", err)
+	}
+
+	if sinfo == nil {
+		return nil, fmt.Errorf("sector not found on chain")
+	}
+
+	nv, err := s.capi.StateNetworkVersion(ctx, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get network version: %w", err)
+	}
+
+	postProofType, err := sinfo.SealProof.RegisteredWindowPoStProofByNetworkVersion(nv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seal proof type %d: %w", sinfo.SealProof, err)
+	}
+
+	tocheck := []builtin.ExtendedSectorInfo{util.SectorOnChainInfoToExtended(sinfo)}
+	bad, err := s.sectorProving.Provable(ctx, sid.Miner, postProofType, tocheck, strict, false)
+	if err != nil {
+		return nil, fmt.Errorf("check provable: %w", err)
+	}
+
+	reason, isBad := bad[sid.Number]
+	return &core.SectorProvability{
+		ID:       sid,
+		Provable: !isBad,
+		Reason:   reason,
+	}, nil
+}
+
+// InspectPrivateSectorInfo resolves the private info of the sector like ProvingSectorInfo does,
+// and checks each file required for proving. The failures are reported in the result instead of the error,
+// which is only returned if the sector can not be found on chain.
+func (s *Sealer) InspectPrivateSectorInfo(
+	ctx context.Context,
+	sid abi.SectorID,
+) (*core.PrivateSectorInspection, error) {
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get sector info: %w", err)
+	}
+
+	if sinfo == nil {
+		return nil, fmt.Errorf("sector not found on chain")
+	}
+
+	ext := util.SectorOnChainInfoToExtended(sinfo)
+	res := &core.PrivateSectorInspection{
+		Upgrade: ext.SectorKey != nil,
+	}
+
+	private, err := s.sectorProving.SinglePubToPrivateInfo(ctx, sid.Miner, ext, nil)
+	if err != nil {
+		indexType := core.SectorIndexTypeNormal
+		if res.Upgrade {
+			indexType = core.SectorIndexTypeUpgrade
+		}
+
+		res.Err = fmt.Sprintf("resolve location in the %s indexer: %s", indexType, err)
+		return res, nil
+	}
+
+	res.Private = &private
+
+	ssize, err := sinfo.SealProof.SectorSize()
+	if err != nil {
+		res.Err = fmt.Sprintf("get sector size: %s", err)
+		return res, nil
+	}
+
+	sealedFile, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, private.Accesses.SealedFile)
+	if err != nil {
+		res.Err = fmt.Sprintf("get objstore instance %s for sealed file: %s", private.Accesses.SealedFile, err)
+		return res, nil
+	}
+
+	cacheDir, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, private.Accesses.CacheDir)
+	if err != nil {
+		res.Err = fmt.Sprintf("get objstore instance %s for cache dir: %s", private.Accesses.CacheDir, err)
+		return res, nil
+	}
+
+	check := func(title string, store objstore.Store, uri string, expected int64) {
+		artifact := core.SectorArtifactCheck{
+			Title:    title,
+			URI:      uri,
+			Expected: expected,
+		}
+
+		st, err := store.Stat(ctx, uri)
+		if err != nil {
+			artifact.Err = err.Error()
+		} else {
+			artifact.Size = st.Size
+			if expected != 0 && st.Size != expected {
+				artifact.Err = fmt.Sprintf("wrong size, got %d, expect %d", st.Size, expected)
+			}
+		}
+
+		if artifact.Err != "" && res.Err == "" {
+			res.Err = fmt.Sprintf("%s %s: %s", title, uri, artifact.Err)
+		}
+
+		res.Artifacts = append(res.Artifacts, artifact)
+	}
+
+	check("sealed file", sealedFile, private.SealedSectorURI, int64(ssize))
+	for _, p := range util.CachedFilesForSectorSize(private.CacheDirURI, ssize) {
+		check("cache dir", cacheDir, p, 0)
+	}
+
+	return res, nil
+}
+
+// SectorsExpiringBefore returns the local sectors of the given miner which expire on chain before the given epoch,
+// sorted by the expiration.
+func (s *Sealer) SectorsExpiringBefore(
+	ctx context.Context,
+	mid abi.ActorID,
+	epoch abi.ChainEpoch,
+) ([]core.SectorExpirationInfo, error) {
+	var sids []abi.SectorID
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.ID.Miner == mid && !bool(ss.Removed) {
+			sids = append(sids, ss.ID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	sinfos := chain.NewSectorInfoCache(s.capi, types.EmptyTSK)
+	if err := sinfos.Prefetch(ctx, sids); err != nil {
+		return nil, fmt.Errorf("load sector infos: %w", err)
+	}
+
+	var expiring []core.SectorExpirationInfo
+	for _, sid := range sids {
+		sinfo, err := sinfos.Get(ctx, sid)
+		if err != nil {
+			return nil, fmt.Errorf("get sector info of %s: %w", util.FormatSectorID(sid), err)
+		}
+
+		// not on chain, or already terminated
+		if sinfo == nil {
+			continue
+		}
+
+		if sinfo.Expiration < epoch {
+			expiring = append(expiring, core.SectorExpirationInfo{
+				ID:         sid,
+				Expiration: sinfo.Expiration,
+			})
+		}
+	}
+
+	sort.Slice(expiring, func(i, j int) bool {
+		if expiring[i].Expiration != expiring[j].Expiration {
+			return expiring[i].Expiration < expiring[j].Expiration
+		}
+
+		return expiring[i].ID.Number < expiring[j].ID.Number
+	})
+
+	return expiring, nil
+}
+
+// ListSealedDeals returns the deals sealed in the local sectors of the given miner,
+// one entry for each deal, sorted by deal id.
+func (s *Sealer) ListSealedDeals(ctx context.Context, mid abi.ActorID) ([]core.SealedDealInfo, error) {
+	seen := map[abi.DealID]struct{}{}
+	var deals []core.SealedDealInfo
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.ID.Miner != mid || bool(ss.Removed) || ss.AbortReason != "" {
+			return nil
+		}
+
+		for _, dealID := range ss.DealIDs() {
+			if _, ok := seen[dealID]; ok {
+				continue
+			}
+
+			seen[dealID] = struct{}{}
+			deals = append(deals, core.SealedDealInfo{
+				DealID: dealID,
+				Sector: ss.ID.Number,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	sort.Slice(deals, func(i, j int) bool {
+		return deals[i].DealID < deals[j].DealID
+	})
+
+	return deals, nil
+}
+
+// DealStats counts the deals and the deal bytes sealed in the local sectors of the given miner,
+// the removed and aborted sectors are excluded.
+func (s *Sealer) DealStats(ctx context.Context, mid abi.ActorID) (*core.DealStats, error) {
+	stats := &core.DealStats{}
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.ID.Miner != mid || bool(ss.Removed) || ss.AbortReason != "" {
+			return nil
+		}
+
+		hasDeal := false
+		for _, piece := range ss.SectorPiece() {
+			if !piece.HasDealInfo() {
+				continue
+			}
+
+			hasDeal = true
+			size := piece.PieceInfo().Size
+			entry := &stats.Unverified
+			if core.IsVerifiedPiece(piece) {
+				entry = &stats.Verified
+			}
+
+			entry.Deals++
+			entry.Bytes += size
+			stats.Deals++
+			stats.Bytes += size
+		}
+
+		if hasDeal {
+			stats.Sectors++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	return stats, nil
+}
+
+// SealDurationStats summarizes the sealing durations of the sectors finalized since the given time,
+// the aborted, imported, upgraded sectors and those without timestamps are excluded.
+func (s *Sealer) SealDurationStats(
+	ctx context.Context,
+	mid abi.ActorID,
+	since time.Time,
+) (*core.SealDurationStats, error) {
+	var all []time.Duration
+	byProofType := map[abi.RegisteredSealProof][]time.Duration{}
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.ID.Miner != mid || ss.AbortReason != "" || bool(ss.Imported) || bool(ss.Upgraded) {
+			return nil
+		}
+
+		createdAt, finalizedAt := int64(ss.CreatedAt), int64(ss.FinalizedAt)
+		if createdAt == 0 || finalizedAt < createdAt || finalizedAt < since.Unix() {
+			return nil
+		}
+
+		elapsed := time.Duration(finalizedAt-createdAt) * time.Second
+		all = append(all, elapsed)
+		byProofType[ss.SectorType] = append(byProofType[ss.SectorType], elapsed)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	stats := &core.SealDurationStats{
+		SealDurationSummary: summarizeDurations(all),
+		ByProofType:         make(map[abi.RegisteredSealProof]core.SealDurationSummary, len(byProofType)),
+	}
+
+	for proofType, durations := range byProofType {
+		stats.ByProofType[proofType] = summarizeDurations(durations)
+	}
+
+	return stats, nil
+}
+
+// StuckSectors lists the sealing sectors which have stayed in the current state for longer than olderThan,
+// the creation time is used for the sectors without a recorded state entering time.
+func (s *Sealer) StuckSectors(ctx context.Context, olderThan time.Duration) ([]core.StuckSector, error) {
+	now := time.Now()
+	var stuck []core.StuckSector
+	err := s.state.ForEach(ctx, core.WorkerOnline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.AbortReason != "" || bool(ss.Finalized) {
+			return nil
+		}
+
+		enteredAt := int64(ss.StateEnteredAt)
+		if enteredAt == 0 {
+			enteredAt = int64(ss.CreatedAt)
+		}
+
+		if enteredAt == 0 {
+			return nil
+		}
+
+		stuckFor := now.Sub(time.Unix(enteredAt, 0))
+		if stuckFor < olderThan {
+			return nil
+		}
+
+		info := core.StuckSector{
+			ID:        ss.ID,
+			EnteredAt: enteredAt,
+			StuckFor:  stuckFor,
+		}
+
+		if ss.LatestState != nil {
+			info.State = ss.LatestState.StateChange.Next
+			info.Worker = ss.LatestState.Worker.Instance
+		}
+
+		stuck = append(stuck, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	sort.Slice(stuck, func(i, j int) bool {
+		return stuck[i].StuckFor > stuck[j].StuckFor
+	})
+
+	return stuck, nil
+}
+
+func summarizeDurations(durations []time.Duration) core.SealDurationSummary {
+	if len(durations) == 0 {
+		return core.SealDurationSummary{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	// nearest-rank percentile
+	percentile := func(p int) time.Duration {
+		rank := (p*len(durations) + 99) / 100
+		return durations[rank-1]
+	}
+
+	return core.SealDurationSummary{
+		Count:  len(durations),
+		Min:    durations[0],
+		Max:    durations[len(durations)-1],
+		Median: percentile(50),
+		P95:    percentile(95),
+	}
+}
+
+func (s *Sealer) WorkerGetPingInfo(ctx context.Context, name string) (*core.WorkerPingInfo, error) {
+	winfo, err := s.workerMgr.Load(ctx, name)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("load worker info: %w", err)
+	}
+
+	return &winfo, nil
+}
+
+func (s *Sealer) WorkerPingInfoList(ctx context.Context) ([]core.WorkerPingInfo, error) {
+	winfos, err := s.workerMgr.All(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("load all worker infos: %w", err)
+	}
+
+	s.markWorkersOnline(winfos)
+	return winfos, nil
+}
+
+func (s *Sealer) markWorkersOnline(winfos []core.WorkerPingInfo) {
+	cfg := s.scfg.MustCommonConfig().WorkerRegistry
+	now := time.Now()
+	for i := range winfos {
+		winfos[i].Online = winfos[i].IsOnline(now, cfg.PingInterval.Std(), cfg.OfflineGracePeriod.Std())
+	}
+}
+
+// WorkerFindByHost returns the workers whose dest address is on the given host,
+// the host could be either an ip or a hostname, which will be resolved to the ips.
+func (s *Sealer) WorkerFindByHost(ctx context.Context, host string) ([]core.WorkerPingInfo, error) {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return nil, fmt.Errorf("host is required")
+	}
+
+	hosts := map[string]struct{}{
+		host: {},
+	}
+
+	if net.ParseIP(host) == nil {
+		addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+		if err != nil {
+			log.Debugw("resolve worker host", "host", host, "err", err)
+		}
+
+		for _, addr := range addrs {
+			hosts[addr] = struct{}{}
+		}
+	}
+
+	winfos, err := s.workerMgr.All(ctx, func(winfo *core.WorkerPingInfo) bool {
+		dhost, _, err := net.SplitHostPort(winfo.Info.Dest)
+		if err != nil {
+			dhost = winfo.Info.Dest
+		}
+
+		_, ok := hosts[dhost]
+		return ok
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load worker infos: %w", err)
+	}
+
+	s.markWorkersOnline(winfos)
+	return winfos, nil
+}
+
+func (s *Sealer) WorkerPingInfoRemove(ctx context.Context, name string) error {
+	return s.workerMgr.Remove(ctx, name)
+}
+
+func (s *Sealer) WorkerRegister(ctx context.Context, name string) (bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return false, fmt.Errorf("worker name is required")
+	}
+
+	_, err := s.workerMgr.Load(ctx, name)
+	if err == nil {
+		return false, nil
+	}
+
+	if !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return false, fmt.Errorf("load worker info: %w", err)
+	}
+
+	err = s.workerMgr.Update(ctx, core.WorkerPingInfo{
+		Info: core.WorkerInfo{
+			Name: name,
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("update worker info: %w", err)
+	}
+
+	return true, nil
+}
+
+func (s *Sealer) SectorIndexerFind(
+	ctx context.Context,
+	indexType core.SectorIndexType,
+	sid abi.SectorID,
+) (core.SectorIndexLocation, error) {
+	var indexer core.SectorTypedIndexer
+
+	switch indexType {
+	case core.SectorIndexTypeNormal:
+		indexer = s.sectorIdxer.Normal()
+
+	case core.SectorIndexTypeUpgrade:
+		indexer = s.sectorIdxer.Upgrade()
+
+	default:
+		return core.SectorIndexLocation{}, fmt.Errorf("sector indexer of type %s is not supported", indexType)
+	}
+
+	instance, found, err := indexer.Find(ctx, sid)
+	if err != nil {
+		return core.SectorIndexLocation{}, fmt.Errorf("find in indexer of type %s: %w", indexType, err)
+	}
+
+	return core.SectorIndexLocation{
+		Found:    found,
+		Instance: instance,
+	}, nil
+}
+
+func (s *Sealer) TerminateSector(ctx context.Context, sid abi.SectorID) (core.SubmitTerminateResp, error) {
+	if err := s.checkDestructiveOp("terminate sector"); err != nil {
+		return core.SubmitTerminateResp{}, err
+	}
+
+	release, err := s.ops.acquire(sid, "terminate")
+	if err != nil {
+		return core.SubmitTerminateResp{}, err
+	}
+	defer release()
+
+	resp, err := s.commit.SubmitTerminate(ctx, sid)
+	if err == nil && resp.Res == core.SubmitAccepted {
+		s.emitSectorEvent(sid, core.SectorEventTerminated)
+	}
+
+	return resp, err
+}
+
+func (s *Sealer) PollTerminateSectorState(ctx context.Context, sid abi.SectorID) (core.TerminateInfo, error) {
+	return s.commit.TerminateState(ctx, sid)
+}
+
+func (s *Sealer) ListPendingTerminations(ctx context.Context, mid abi.ActorID) ([]core.PendingTermination, error) {
+	return s.commit.PendingTerminations(ctx, mid)
+}
+
+func (s *Sealer) EstimateTerminateGas(ctx context.Context, sids []abi.SectorID) ([]core.TerminateGasEstimate, error) {
+	return s.commit.EstimateTerminate(ctx, sids)
+}
+
+func (s *Sealer) CancelTermination(ctx context.Context, sid abi.SectorID) error {
+	release, err := s.ops.acquire(sid, "cancel termination")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return s.commit.CancelTerminate(ctx, sid)
+}
+
+func (s *Sealer) CanRemoveSector(ctx context.Context, sid abi.SectorID) (*core.SectorRemovability, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return nil, fmt.Errorf("load sector state: %w", err)
+	}
+
+	return s.sectorRemovability(ctx, state)
+}
+
+// sectorRemovability checks if the files of the sector can be removed, a terminated sector
+// should be kept until the winning post lookback has passed.
+func (s *Sealer) sectorRemovability(ctx context.Context, state *core.SectorState) (*core.SectorRemovability, error) {
+	res := &core.SectorRemovability{
+		Allowed: true,
+		Removed: state.Removed,
+	}
+
+	if state.Removed || state.TerminateInfo.TerminatedAt <= 0 {
+		return res, nil
+	}
+
+	ts, err := s.capi.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting chain head: %w", err)
+	}
+
+	nv, err := s.capi.StateNetworkVersion(ctx, ts.Key())
+	if err != nil {
+		return nil, fmt.Errorf("getting network version: %w", err)
+	}
+
+	res.Head = ts.Height()
+	res.AllowedAt = state.TerminateInfo.TerminatedAt + policy.GetWinningPoStSectorSetLookback(nv)
+	res.Allowed = res.Head >= res.AllowedAt
+	return res, nil
+}
+
+func (s *Sealer) RemoveSector(ctx context.Context, sid abi.SectorID) error {
+	if err := s.checkDestructiveOp("remove sector"); err != nil {
+		return err
+	}
+
+	release, err := s.ops.acquire(sid, "remove")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return fmt.Errorf("load sector state: %w", err)
+	}
+
+	if state.Removed {
+		return nil
+	}
+
+	removability, err := s.sectorRemovability(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	if !removability.Allowed {
+		return fmt.Errorf("wait for expiration(+winning lookback?): %v", removability.AllowedAt)
+	}
+
+	sealed, cache, has, err := s.sectorFiles(ctx, sid, state.Upgraded)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("object not found")
+	}
+
+	cachePath := cache.Path
+	sealedPath := sealed.Path
+	slog := log.With(
+		"sector", util.FormatSectorID(sid),
+		"sealed-store", sealed.Instance,
+		"sealed", sealedPath,
+		"cache-store", cache.Instance,
+		"cache", cachePath,
+	)
+
+	freed := pathSize(cachePath) + pathSize(sealedPath)
+	slog.Infow("removing sector files", "size", freed)
+
+	err = os.RemoveAll(cachePath)
+	if err != nil {
+		slog.Errorw("remove cache", "err", err)
+		return fmt.Errorf("remove cache: %w", err)
+	}
+
+	err = os.Remove(sealedPath)
+	if err != nil {
+		slog.Errorw("remove sealed file", "err", err)
+		return fmt.Errorf("remove sealed file: %w", err)
+	}
+
+	state.Removed = true
+	err = s.state.Update(ctx, state.ID, core.WorkerOffline, state.Removed)
+	if err != nil {
+		slog.Errorw("mark sector as removed", "err", err)
+		return fmt.Errorf("update sector Removed failed: %w", err)
+	}
+
+	slog.Infow("sector removed", "freed", freed)
+	s.emitSectorEvent(sid, core.SectorEventRemoved)
+	return nil
+}
+
+// SectorPieces returns the pieces of the sector along with their offsets, the pieces are laid out
+// one after another in the sector, including the padding ones.
+func (s *Sealer) SectorPieces(ctx context.Context, sid abi.SectorID) ([]core.SectorPieceLocation, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if errors.Is(err, kvstore.ErrKeyNotFound) {
+		state, err = s.state.Load(ctx, sid, core.WorkerOnline)
+	}
+	if err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	pieces := state.SectorPiece()
+	locations := make([]core.SectorPieceLocation, 0, len(pieces))
+	var offset abi.PaddedPieceSize
+	for _, piece := range pieces {
+		pinfo := piece.PieceInfo()
+		loc := core.SectorPieceLocation{
+			Cid:    pinfo.Cid,
+			Size:   pinfo.Size,
+			Offset: offset,
+			IsDeal: piece.HasDealInfo(),
+		}
+
+		if loc.IsDeal {
+			loc.Deal = piece.DisplayDealID()
+		}
+
+		locations = append(locations, loc)
+		offset += pinfo.Size
+	}
+
+	return locations, nil
+}
+
+func (s *Sealer) SectorPaths(ctx context.Context, sid abi.SectorID) (*core.SectorPaths, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return nil, fmt.Errorf("load sector state: %w", err)
+	}
+
+	res := &core.SectorPaths{
+		ID:       sid,
+		Upgraded: state.Upgraded,
+	}
+
+	sealed, cache, has, err := s.sectorFiles(ctx, sid, false)
+	if err != nil {
+		return nil, err
+	}
+	if has {
+		res.Sealed, res.Cache = &sealed, &cache
+	}
+
+	if state.Upgraded {
+		update, updateCache, has, err := s.sectorFiles(ctx, sid, true)
+		if err != nil {
+			return nil, err
+		}
+		if has {
+			res.Update, res.UpdateCache = &update, &updateCache
+		}
+	}
+
+	return res, nil
+}
+
+// sectorFiles resolves the full paths of the sealed file & the cache dir of the sector in the indexed store instances,
+// or the ones of the update file & the update cache dir if upgraded is set.
+func (s *Sealer) sectorFiles(
+	ctx context.Context,
+	sid abi.SectorID,
+	upgraded bool,
+) (sealed core.SectorFilePath, cache core.SectorFilePath, has bool, err error) {
+	dest := s.sectorIdxer.Normal()
+	sealedType, cacheType := util.SectorPathTypeSealed, util.SectorPathTypeCache
+	if upgraded {
+		dest = s.sectorIdxer.Upgrade()
+		sealedType, cacheType = util.SectorPathTypeUpdate, util.SectorPathTypeUpdateCache
+	}
+
+	access, has, err := dest.Find(ctx, sid)
+	if err != nil {
+		return sealed, cache, false, fmt.Errorf("find objstore instance: %w", err)
+	}
+	if !has {
+		return sealed, cache, false, nil
+	}
+
+	sealedFile, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.SealedFile)
+	if err != nil {
+		return sealed, cache, false, fmt.Errorf("get objstore instance %s for sealed file: %w", access.SealedFile, err)
+	}
+
+	cacheDir, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.CacheDir)
+	if err != nil {
+		return sealed, cache, false, fmt.Errorf("get objstore instance %s for cache dir: %w", access.CacheDir, err)
+	}
+
+	sealed = core.SectorFilePath{
+		Instance: access.SealedFile,
+		Path:     sealedFile.FullPath(ctx, util.StoreSectorPath(sealedFile.InstanceConfig(ctx).Meta, sealedType, sid)),
+	}
+	cache = core.SectorFilePath{
+		Instance: access.CacheDir,
+		Path:     cacheDir.FullPath(ctx, util.StoreSectorPath(cacheDir.InstanceConfig(ctx).Meta, cacheType, sid)),
+	}
+	return sealed, cache, true, nil
+}
+
+// pathSize returns the total size of the regular files under the given path, the unreadable ones are ignored.
+func pathSize(p string) int64 {
+	var size int64
+	_ = filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+
+		return nil
+	})
+
+	return size
+}
+
+// VerifySealedFileSizes checks the sizes of the sealed files of the indexed sectors of the given miner,
+// it is much cheaper than CheckProvable, and catches the truncated files.
+func (s *Sealer) VerifySealedFileSizes(ctx context.Context, mid abi.ActorID) ([]core.SealedFileSizeMismatch, error) {
+	var states []core.SectorState
+	err := s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if ss.ID.Miner == mid && !bool(ss.Removed) {
+			states = append(states, ss)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	var mismatches []core.SealedFileSizeMismatch
+	for i := range states {
+		state := &states[i]
+		ssize, err := state.SectorType.SectorSize()
+		if err != nil {
+			return nil, fmt.Errorf("get sector size of %s: %w", util.FormatSectorID(state.ID), err)
+		}
+
+		indexer, sealedType := s.sectorIdxer.Normal(), util.SectorPathTypeSealed
+		if state.Upgraded {
+			indexer, sealedType = s.sectorIdxer.Upgrade(), util.SectorPathTypeUpdate
+		}
+
+		access, has, err := indexer.Find(ctx, state.ID)
+		if err != nil {
+			return nil, fmt.Errorf("find objstore instance of %s: %w", util.FormatSectorID(state.ID), err)
+		}
+
+		if !has {
+			continue
+		}
+
+		mismatch := core.SealedFileSizeMismatch{
+			ID:       state.ID,
+			Instance: access.SealedFile,
+			Path:     util.SectorPath(sealedType, state.ID),
+			Expected: int64(ssize),
+		}
+
+		store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.SealedFile)
+		if err != nil {
+			mismatch.Err = fmt.Sprintf("get objstore instance: %s", err)
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+
+		sealed := util.StoreSectorPath(store.InstanceConfig(ctx).Meta, sealedType, state.ID)
+		mismatch.Path = store.FullPath(ctx, sealed)
+		stat, err := store.Stat(ctx, sealed)
+		if err != nil {
+			mismatch.Err = fmt.Sprintf("stat sealed file: %s", err)
+			mismatches = append(mismatches, mismatch)
+			continue
+		}
+
+		if stat.Size != mismatch.Expected {
+			mismatch.Actual = stat.Size
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+
+	return mismatches, nil
+}
+
+// CheckSectorCache reports the missing cache artifacts of a sector whose sealed file is intact,
+// see RepairSectorCache for regenerating them.
+func (s *Sealer) CheckSectorCache(ctx context.Context, sid abi.SectorID) (*core.SectorCacheCheckResult, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	if state.Removed {
+		return nil, fmt.Errorf("sector has been removed")
+	}
+
+	ssize, err := state.SectorType.SectorSize()
+	if err != nil {
+		return nil, fmt.Errorf("get sector size: %w", err)
+	}
+
+	dest := s.sectorIdxer.Normal()
+	if state.Upgraded {
+		dest = s.sectorIdxer.Upgrade()
+	}
+
+	access, has, err := dest.Find(ctx, sid)
+	if err != nil {
+		return nil, fmt.Errorf("find objstore instance: %w", err)
+	}
+	if !has {
+		return nil, fmt.Errorf("object not found")
+	}
+
+	sealedFile, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.SealedFile)
+	if err != nil {
+		return nil, fmt.Errorf("get objstore instance %s for sealed file: %w", access.SealedFile, err)
+	}
+
+	cacheDir, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.CacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("get objstore instance %s for cache dir: %w", access.CacheDir, err)
+	}
+
+	cacheType, sealedType := util.SectorPathTypeCache, util.SectorPathTypeSealed
+	if state.Upgraded {
+		cacheType, sealedType = util.SectorPathTypeUpdateCache, util.SectorPathTypeUpdate
+	}
+
+	cache := util.StoreSectorPath(cacheDir.InstanceConfig(ctx).Meta, cacheType, sid)
+	sealed := util.StoreSectorPath(sealedFile.InstanceConfig(ctx).Meta, sealedType, sid)
+
+	sealedStat, err := sealedFile.Stat(ctx, sealed)
+	if err != nil {
+		return nil, fmt.Errorf("stat sealed file: %w", err)
+	}
+
+	if sealedStat.Size != int64(ssize) {
+		return nil, fmt.Errorf(
+			"sealed file with wrong size (got %d, expect %d), rebuild the sector instead",
+			sealedStat.Size,
+			ssize,
+		)
+	}
+
+	res := &core.SectorCacheCheckResult{}
+	for _, fpath := range util.CachedFilesForSectorSize(cache, ssize) {
+		st, err := cacheDir.Stat(ctx, fpath)
+		if err == nil && st.Size > 0 {
+			continue
+		}
+
+		if err != nil && !errors.Is(err, objstore.ErrObjectNotFound) && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("stat cache file %s: %w", fpath, err)
+		}
+
+		res.Missing = append(res.Missing, fpath)
+	}
+
+	if len(res.Missing) > 0 {
+		log.With("sector", util.FormatSectorID(sid)).Warnw("cache artifacts missing", "files", res.Missing)
+	}
+
+	return res, nil
+}
+
+// RepairSectorCache regenerates the missing cache artifacts of a sector whose sealed file is intact.
+// The manager can't build them itself: p_aux & t_aux hold comm_c and the tree configs, which are derived from
+// the SDR layers dropped in finalization, so the sector is set for rebuild, and the workers regenerate them.
+func (s *Sealer) RepairSectorCache(
+	ctx context.Context,
+	sid abi.SectorID,
+	opt core.RebuildOptions,
+) (*core.SectorCacheRepairResult, error) {
+	check, err := s.CheckSectorCache(ctx, sid)
+	if err != nil {
+		return nil, fmt.Errorf("check cache artifacts: %w", err)
+	}
+
+	res := &core.SectorCacheRepairResult{}
+	if len(check.Missing) == 0 {
+		return res, nil
+	}
+
+	if _, err := s.SectorSetForRebuild(ctx, sid, opt); err != nil {
+		return nil, fmt.Errorf("set for rebuild: %w", err)
+	}
+
+	res.Regenerating = check.Missing
+	log.With("sector", util.FormatSectorID(sid)).Infow(
+		"rebuild scheduled to regenerate cache artifacts",
+		"files",
+		res.Regenerating,
+	)
+	return res, nil
+}
+
+// FinalizeSector is the escape hatch for the sectors stuck before finalization,
+// it moves the sector into the offline database and releases the reserved space.
+// The sector should have been sealed, i.e. landed on chain with its files persisted.
+func (s *Sealer) FinalizeSector(ctx context.Context, sid abi.SectorID) error {
+	release, err := s.ops.acquire(sid, "finalize")
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return fmt.Errorf("invalid mienr actor id: %w", err)
+	}
+
+	ts, err := s.capi.ChainHead(ctx)
+	if err != nil {
+		return fmt.Errorf("getting chain head: %w", err)
+	}
+
+	si, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, ts.Key())
+	if err != nil {
+		return err
+	}
+	if si == nil {
+		return fmt.Errorf("sector %d for miner %s not found", sid, maddr)
+	}
+
+	if err := s.state.Finalize(ctx, sid, func(st *core.SectorState) (bool, error) {
+		indexer := s.sectorIdxer.Normal()
+		if st.Upgraded {
+			if st.UpgradeLandedEpoch == nil {
+				return false, fmt.Errorf("snapup of the sector has not landed on chain")
+			}
+
+			indexer = s.sectorIdxer.Upgrade()
+		}
+
+		_, has, err := indexer.Find(ctx, sid)
+		if err != nil {
+			return false, fmt.Errorf("find objstore instance: %w", err)
+		}
+
+		if !has {
+			return false, fmt.Errorf("sector files have not been persisted")
+		}
+
+		return true, nil
+	}); err != nil {
+		return sectorStateErr(err)
+	}
+
+	if _, err := s.sectorIdxer.StoreMgr().ReleaseReserved(ctx, sid); err != nil {
+		log.With("sector", util.FormatSectorID(sid)).Errorf("release reserved: %s", err)
+	}
+
+	log.With("sector", util.FormatSectorID(sid)).Info("sector finalized manually")
+	return nil
+}
+
+func (s *Sealer) StoreReleaseReserved(ctx context.Context, sid abi.SectorID) (bool, error) {
+	done, err := s.sectorIdxer.StoreMgr().ReleaseReserved(ctx, sid)
+	if err != nil {
+		return false, fmt.Errorf("release reserved: %w", err)
+	}
+
+	return done, nil
+}
+
+func (s *Sealer) StoreReserve(
+	ctx context.Context,
+	sid abi.SectorID,
+	instanceName string,
+	size uint64,
+	by string,
+) (core.ReservedItem, error) {
+	reserved, err := s.sectorIdxer.StoreMgr().ReserveSpaceOn(ctx, sid, size, instanceName, by)
+	if err != nil {
+		return core.ReservedItem{}, fmt.Errorf("reserve space on %s: %w", instanceName, err)
+	}
+
+	return reserved, nil
+}
+
+func (s *Sealer) StoreList(ctx context.Context) ([]core.StoreDetailedInfo, error) {
+	return s.StoreListEx(ctx, core.StoreListOptions{})
+}
+
+func (s *Sealer) StoreListEx(ctx context.Context, opts core.StoreListOptions) ([]core.StoreDetailedInfo, error) {
+	var less func(a, b *core.StoreDetailedInfo) bool
+	switch opts.SortBy {
+	case core.StoreSortByNone:
+	case core.StoreSortByUsedPercent:
+		less = func(a, b *core.StoreDetailedInfo) bool { return a.UsedPercent < b.UsedPercent }
+	case core.StoreSortByFree:
+		less = func(a, b *core.StoreDetailedInfo) bool { return a.Free < b.Free }
+	case core.StoreSortByName:
+		less = func(a, b *core.StoreDetailedInfo) bool { return a.Name < b.Name }
+	default:
+		return nil, fmt.Errorf("unknown sort key %q", opts.SortBy)
+	}
+
+	infos, err := s.sectorIdxer.StoreMgr().ListInstances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list instances: %w", err)
+	}
+
+	details := make([]core.StoreDetailedInfo, 0, len(infos))
+	for i := range infos {
+		details = append(details, storeInfo2StoreDetailed(&infos[i]))
+	}
+
+	if less != nil {
+		sort.SliceStable(details, func(i, j int) bool {
+			if opts.Desc {
+				return less(&details[j], &details[i])
+			}
+			return less(&details[i], &details[j])
+		})
+	}
+
+	return details, nil
+}
+
+// StoreRefreshInfo queries the latest capacity of the given store instance on demand.
+func (s *Sealer) StoreRefreshInfo(ctx context.Context, instanceName string) (*core.StoreDetailedInfo, error) {
+	info, err := s.sectorIdxer.StoreMgr().GetInstanceInfo(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("get instance info: %w", err)
+	}
+
+	detail := storeInfo2StoreDetailed(&info)
+	return &detail, nil
+}
+
+// StoreCapacityReport sums up the capacities of all the store instances, and projects the number of sectors
+// they are still able to hold. Only the sealed files are taken into account, the space used by the cache dirs
+// is relatively small.
+func (s *Sealer) StoreCapacityReport(
+	ctx context.Context,
+	sectorSize abi.SectorSize,
+) (*core.StoreCapacityReport, error) {
+	if sectorSize == 0 {
+		return nil, fmt.Errorf("sector size is required")
+	}
+
+	infos, err := s.sectorIdxer.StoreMgr().ListInstances(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list instances: %w", err)
+	}
+
+	report := &core.StoreCapacityReport{
+		SectorSize: sectorSize,
+		Stores:     make([]core.StoreCapacity, 0, len(infos)),
+	}
+
+	for _, info := range infos {
+		capacity := core.StoreCapacity{
+			Name:        info.Instance.Config.Name,
+			ReadOnly:    info.Instance.Config.ReadOnly,
+			Total:       info.Instance.Total,
+			Free:        info.Instance.Free,
+			Reserved:    info.Reserved.ReservedSize,
+			UsedPercent: info.Instance.UsedPercent,
+		}
+
+		if !capacity.ReadOnly && capacity.Free > capacity.Reserved {
+			capacity.Available = capacity.Free - capacity.Reserved
+			capacity.SectorsRemaining = capacity.Available / uint64(sectorSize)
+		}
+
+		report.Total += capacity.Total
+		report.Free += capacity.Free
+		report.Reserved += capacity.Reserved
+		report.Available += capacity.Available
+		report.SectorsRemaining += capacity.SectorsRemaining
+		report.Stores = append(report.Stores, capacity)
+	}
+
+	sort.Slice(report.Stores, func(i, j int) bool {
+		return report.Stores[i].Name < report.Stores[j].Name
+	})
+
+	return report, nil
+}
+
+// SectorsOnStore lists the sectors whose files are indexed on the store instance, both online & offline sectors
+// are checked. The indexer has no reverse mapping, so every sector state will be scanned.
+func (s *Sealer) SectorsOnStore(ctx context.Context, instanceName string) (*core.SectorsOnStore, error) {
+	if _, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, instanceName); err != nil {
+		return nil, fmt.Errorf("get objstore instance %s: %w", instanceName, err)
+	}
+
+	res := &core.SectorsOnStore{
+		Instance: instanceName,
+	}
+
+	for _, ws := range []core.SectorWorkerState{core.WorkerOnline, core.WorkerOffline} {
+		err := s.state.ForEach(ctx, ws, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+			if ss.Removed {
+				return nil
+			}
+
+			access, has, err := s.sectorIdxer.Normal().Find(ctx, ss.ID)
+			if err != nil {
+				return fmt.Errorf("find objstore instance for %s: %w", util.FormatSectorID(ss.ID), err)
+			}
+
+			if has && access.SealedFile == instanceName {
+				res.SealedFile = append(res.SealedFile, ss.ID)
+			}
+
+			if has && access.CacheDir == instanceName {
+				res.CacheDir = append(res.CacheDir, ss.ID)
+			}
+
+			if !ss.Upgraded {
+				return nil
+			}
+
+			access, has, err = s.sectorIdxer.Upgrade().Find(ctx, ss.ID)
+			if err != nil {
+				return fmt.Errorf("find upgrade objstore instance for %s: %w", util.FormatSectorID(ss.ID), err)
+			}
+
+			if has && access.SealedFile == instanceName {
+				res.UpdateFile = append(res.UpdateFile, ss.ID)
+			}
+
+			if has && access.CacheDir == instanceName {
+				res.UpdateCache = append(res.UpdateCache, ss.ID)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("iterate %s sectors: %w", ws, err)
+		}
+	}
+
+	return res, nil
+}
+
+// ReindexStore scans the sector files in the store instance, e.g. those restored from backups out-of-band,
+// and points their entries in the sector indexer to the instance.
+// The files of the sectors without states, or already indexed to other instances, are skipped and reported,
+// since they are probably stale copies.
+func (s *Sealer) ReindexStore(ctx context.Context, instanceName string, dryRun bool) (*core.StoreReindexResult, error) {
+	found, unrecognized, err := s.scanStoreSectorFiles(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("scan sector files: %w", err)
+	}
+
+	res := &core.StoreReindexResult{
+		Found: core.SectorsOnStore{
+			Instance: instanceName,
+		},
+		Unrecognized: unrecognized,
+		DryRun:       dryRun,
+	}
+
+	for _, part := range []struct {
+		typ     string
+		indexer core.SectorTypedIndexer
+		sids    []abi.SectorID
+		dest    *[]abi.SectorID
+		isCache bool
+	}{
+		{"sealed", s.sectorIdxer.Normal(), found.SealedFile, &res.Found.SealedFile, false},
+		{"cache", s.sectorIdxer.Normal(), found.CacheDir, &res.Found.CacheDir, true},
+		{"update", s.sectorIdxer.Upgrade(), found.UpdateFile, &res.Found.UpdateFile, false},
+		{"update-cache", s.sectorIdxer.Upgrade(), found.UpdateCache, &res.Found.UpdateCache, true},
+	} {
+		for _, sid := range part.sids {
+			reason, err := s.reindexSkipReason(ctx, part.indexer, sid, instanceName, part.isCache)
+			if err != nil {
+				return nil, err
+			}
+
+			if reason != "" {
+				res.Skipped = append(res.Skipped, core.StoreReindexSkipped{ID: sid, Type: part.typ, Reason: reason})
+				continue
+			}
+
+			*part.dest = append(*part.dest, sid)
+			if dryRun {
+				continue
+			}
+
+			access := core.SectorAccessStores{SealedFile: instanceName}
+			if part.isCache {
+				access = core.SectorAccessStores{CacheDir: instanceName}
+			}
+
+			if err := part.indexer.Update(ctx, sid, access); err != nil {
+				return nil, fmt.Errorf("update sector index for %s: %w", util.FormatSectorID(sid), err)
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// reindexSkipReason tells why the sector file found in the instance should not be indexed, or empty if it should.
+func (s *Sealer) reindexSkipReason(
+	ctx context.Context,
+	indexer core.SectorTypedIndexer,
+	sid abi.SectorID,
+	instanceName string,
+	isCache bool,
+) (string, error) {
+	state, err := s.FindSectorInAllStates(ctx, sid)
+	if err != nil {
+		if errors.Is(err, kvstore.ErrKeyNotFound) {
+			return "sector state not found", nil
+		}
+
+		return "", fmt.Errorf("load state of %s: %w", util.FormatSectorID(sid), err)
+	}
+
+	if state.Removed {
+		return "sector has been removed", nil
+	}
+
+	access, has, err := indexer.Find(ctx, sid)
 	if err != nil {
-		return core.Empty, err
+		return "", fmt.Errorf("find objstore instance for %s: %w", util.FormatSectorID(sid), err)
 	}
 
-	return core.Empty, nil
-}
+	indexed := access.SealedFile
+	if isCache {
+		indexed = access.CacheDir
+	}
 
-func (s *Sealer) CheckProvable(
-	ctx context.Context,
-	mid abi.ActorID,
-	postProofType abi.RegisteredPoStProof,
-	sectors []builtin.ExtendedSectorInfo,
-	strict, stateCheck bool,
-) (map[abi.SectorNumber]string, error) {
-	return s.sectorProving.Provable(ctx, mid, postProofType, sectors, strict, stateCheck)
+	if has && indexed != "" && indexed != instanceName {
+		return fmt.Sprintf("already indexed to %s", indexed), nil
+	}
+
+	return "", nil
 }
 
-func (s *Sealer) SimulateWdPoSt(
-	_ context.Context,
-	ddlIndex, partitionIndex uint64,
-	maddr address.Address,
-	postProofType abi.RegisteredPoStProof,
-	sis []builtin.ExtendedSectorInfo,
-	rand abi.PoStRandomness,
-) error {
-	mid, err := address.IDFromAddress(maddr)
+// FindOrphanedFiles lists the sector files in the store instance which the sector indexer doesn't point to.
+func (s *Sealer) FindOrphanedFiles(ctx context.Context, instanceName string) (*core.StoreOrphanedFiles, error) {
+	found, unrecognized, err := s.scanStoreSectorFiles(ctx, instanceName)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("scan sector files: %w", err)
 	}
 
-	slog := log.With("miner", mid, "sectors", len(sis))
-
-	go func() {
-		tCtx := context.TODO()
+	store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("get objstore instance %s: %w", instanceName, err)
+	}
 
-		tsStart := clock.NewSystemClock().Now()
+	res := &core.StoreOrphanedFiles{
+		Instance:     instanceName,
+		Unrecognized: unrecognized,
+	}
 
-		slog.Info("mock generate window post start")
-		params := core.GenerateWindowPoStParams{
-			DeadlineIdx: ddlIndex,
-			MinerID:     abi.ActorID(mid),
-			ProofType:   postProofType,
-			Partitions:  []uint64{partitionIndex},
-			Sectors:     sis,
-			Randomness:  append(abi.PoStRandomness{}, rand...),
-		}
-		proof, skipped, err := s.prover.GenerateWindowPoSt(tCtx, params)
-		if err != nil {
-			slog.Warnf("generate window post failed: %v", err.Error())
-			return
+	for _, typ := range util.SectorPathTypes {
+		indexer, sids := s.sectorIdxer.Normal(), found.SealedFile
+		switch typ {
+		case util.SectorPathTypeCache:
+			sids = found.CacheDir
+		case util.SectorPathTypeUpdate:
+			indexer, sids = s.sectorIdxer.Upgrade(), found.UpdateFile
+		case util.SectorPathTypeUpdateCache:
+			indexer, sids = s.sectorIdxer.Upgrade(), found.UpdateCache
 		}
 
-		elapsed := time.Since(tsStart)
-		slog.Infow("mock generate window post", "elapsed", elapsed, "proof-size", len(proof), "skipped", len(skipped))
-	}()
+		for _, sid := range sids {
+			access, has, err := indexer.Find(ctx, sid)
+			if err != nil {
+				return nil, fmt.Errorf("find objstore instance for %s: %w", util.FormatSectorID(sid), err)
+			}
 
-	return nil
-}
+			indexed := access.SealedFile
+			if typ.IsDir() {
+				indexed = access.CacheDir
+			}
 
-func (s *Sealer) SnapUpPreFetch(
-	ctx context.Context,
-	mid abi.ActorID,
-	dlindex *uint64,
-) (*core.SnapUpFetchResult, error) {
-	count, diff, err := s.snapup.PreFetch(ctx, mid, dlindex)
-	if err != nil {
-		return nil, fmt.Errorf("prefetch: %w", err)
+			if has && indexed == instanceName {
+				continue
+			}
+
+			category, err := s.orphanedFileCategory(ctx, sid, has && indexed != "")
+			if err != nil {
+				return nil, err
+			}
+
+			res.Files = append(res.Files, core.OrphanedSectorFile{
+				ID:       sid,
+				Type:     string(typ),
+				Path:     util.StoreSectorPath(store.InstanceConfig(ctx).Meta, typ, sid),
+				Category: category,
+			})
+		}
 	}
 
-	return &core.SnapUpFetchResult{
-		Total: count,
-		Diff:  diff,
-	}, nil
+	return res, nil
 }
 
-func (s *Sealer) SnapUpCandidates(ctx context.Context, mid abi.ActorID) ([]*bitfield.BitField, error) {
-	return s.snapup.Candidates(ctx, mid)
-}
+func (s *Sealer) orphanedFileCategory(ctx context.Context, sid abi.SectorID, indexed bool) (string, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOnline)
+	if err == nil && !state.Removed {
+		return core.OrphanedFileInProgress, nil
+	}
 
-func (s *Sealer) SnapUpCancelCommitment(ctx context.Context, sid abi.SectorID) error {
-	s.snapup.CancelCommitment(ctx, sid)
-	return nil
-}
+	if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return "", fmt.Errorf("load online state of %s: %w", util.FormatSectorID(sid), err)
+	}
 
-func (s *Sealer) ProvingSectorInfo(ctx context.Context, sid abi.SectorID) (core.ProvingSectorInfo, error) {
-	maddr, err := address.NewIDAddress(uint64(sid.Miner))
-	if err != nil {
-		return core.ProvingSectorInfo{}, fmt.Errorf("invalid mienr actor id: %w", err)
+	if indexed {
+		return core.OrphanedFileIndexedElsewhere, nil
 	}
 
-	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
-	if err != nil {
-		return core.ProvingSectorInfo{}, fmt.Errorf("get sector info: %w", err)
+	state, err = s.state.Load(ctx, sid, core.WorkerOffline)
+	if err == nil && !state.Removed {
+		return core.OrphanedFileUnindexed, nil
 	}
 
-	private, err := s.sectorProving.SinglePubToPrivateInfo(ctx, sid.Miner, util.SectorOnChainInfoToExtended(sinfo), nil)
-	if err != nil {
-		return core.ProvingSectorInfo{}, fmt.Errorf("get private sector info: %w", err)
+	if err != nil && !errors.Is(err, kvstore.ErrKeyNotFound) {
+		return "", fmt.Errorf("load offline state of %s: %w", util.FormatSectorID(sid), err)
 	}
 
-	return core.ProvingSectorInfo{
-		OnChain: *sinfo,
-		Private: private,
-	}, nil
+	return core.OrphanedFileNoSector, nil
 }
 
-func (s *Sealer) WorkerGetPingInfo(ctx context.Context, name string) (*core.WorkerPingInfo, error) {
-	winfo, err := s.workerMgr.Load(ctx, name)
+// StoreRebalance moves the sealed files & cache dirs of at most maxSectors finalized sectors
+// from one store instance to another. The sector indexer will be updated only after the files
+// have been copied & verified, and the source files will be removed after that.
+func (s *Sealer) StoreRebalance(
+	ctx context.Context,
+	fromInstance, toInstance string,
+	maxSectors int,
+) (*core.StoreRebalanceResult, error) {
+	if fromInstance == toInstance {
+		return nil, fmt.Errorf("source and destination are the same instance %s", fromInstance)
+	}
+
+	if maxSectors <= 0 {
+		return nil, fmt.Errorf("max sectors should be positive, got %d", maxSectors)
+	}
+
+	from, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, fromInstance)
 	if err != nil {
-		if errors.Is(err, kvstore.ErrKeyNotFound) {
-			return nil, nil
-		}
+		return nil, fmt.Errorf("get objstore instance %s: %w", fromInstance, err)
+	}
 
-		return nil, fmt.Errorf("load worker info: %w", err)
+	to, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, toInstance)
+	if err != nil {
+		return nil, fmt.Errorf("get objstore instance %s: %w", toInstance, err)
 	}
 
-	return &winfo, nil
-}
+	if to.InstanceConfig(ctx).ReadOnly {
+		return nil, fmt.Errorf("objstore instance %s is readonly", toInstance)
+	}
 
-func (s *Sealer) WorkerPingInfoList(ctx context.Context) ([]core.WorkerPingInfo, error) {
-	winfos, err := s.workerMgr.All(ctx, nil)
+	// the files are copied to the same relative paths
+	fromLayout, err := util.ParseSectorPathLayout(from.InstanceConfig(ctx).Meta)
 	if err != nil {
-		return nil, fmt.Errorf("load all worker infos: %w", err)
+		return nil, fmt.Errorf("parse sector path layout of %s: %w", fromInstance, err)
 	}
 
-	return winfos, nil
-}
+	toLayout, err := util.ParseSectorPathLayout(to.InstanceConfig(ctx).Meta)
+	if err != nil {
+		return nil, fmt.Errorf("parse sector path layout of %s: %w", toInstance, err)
+	}
 
-func (s *Sealer) WorkerPingInfoRemove(ctx context.Context, name string) error {
-	return s.workerMgr.Remove(ctx, name)
-}
+	if !fromLayout.Equal(toLayout) {
+		return nil, fmt.Errorf("objstore instances %s and %s have different sector path layouts", fromInstance, toInstance)
+	}
 
-func (s *Sealer) SectorIndexerFind(
-	ctx context.Context,
-	indexType core.SectorIndexType,
-	sid abi.SectorID,
-) (core.SectorIndexLocation, error) {
-	var indexer core.SectorTypedIndexer
+	var candidates []abi.SectorID
+	errEnough := errors.New("enough candidates")
+	err = s.state.ForEach(ctx, core.WorkerOffline, core.SectorWorkerJobAll, func(ss core.SectorState) error {
+		if !rebalanceable(&ss) {
+			return nil
+		}
 
-	switch indexType {
-	case core.SectorIndexTypeNormal:
-		indexer = s.sectorIdxer.Normal()
+		access, has, err := s.sectorTypedIndexer(&ss).Find(ctx, ss.ID)
+		if err != nil {
+			return fmt.Errorf("find objstore instance for %s: %w", util.FormatSectorID(ss.ID), err)
+		}
 
-	case core.SectorIndexTypeUpgrade:
-		indexer = s.sectorIdxer.Upgrade()
+		if !has || (access.SealedFile != fromInstance && access.CacheDir != fromInstance) {
+			return nil
+		}
 
-	default:
-		return core.SectorIndexLocation{}, fmt.Errorf("sector indexer of type %s is not supported", indexType)
+		candidates = append(candidates, ss.ID)
+		if len(candidates) >= maxSectors {
+			return errEnough
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnough) {
+		return nil, fmt.Errorf("iterate offline sectors: %w", err)
 	}
 
-	instance, found, err := indexer.Find(ctx, sid)
-	if err != nil {
-		return core.SectorIndexLocation{}, fmt.Errorf("find in indexer of type %s: %w", indexType, err)
+	res := &core.StoreRebalanceResult{}
+	for _, sid := range candidates {
+		slog := log.With("sector", util.FormatSectorID(sid), "from", fromInstance, "to", toInstance)
+		if err := s.rebalanceSector(ctx, sid, from, to); err != nil {
+			slog.Warnw("rebalance sector", "err", err)
+			res.Failed = append(res.Failed, core.StoreRebalanceFailure{ID: sid, Err: err.Error()})
+			continue
+		}
+
+		slog.Info("sector rebalanced")
+		res.Moved = append(res.Moved, sid)
 	}
 
-	return core.SectorIndexLocation{
-		Found:    found,
-		Instance: instance,
-	}, nil
+	return res, nil
 }
 
-func (s *Sealer) TerminateSector(ctx context.Context, sid abi.SectorID) (core.SubmitTerminateResp, error) {
-	return s.commit.SubmitTerminate(ctx, sid)
+// rebalanceable tells if the files of the sector are settled, so that they could be moved.
+func rebalanceable(state *core.SectorState) bool {
+	return bool(state.Finalized) && state.AbortReason == "" && !bool(state.Removed)
 }
 
-func (s *Sealer) PollTerminateSectorState(ctx context.Context, sid abi.SectorID) (core.TerminateInfo, error) {
-	return s.commit.TerminateState(ctx, sid)
+func (s *Sealer) sectorTypedIndexer(state *core.SectorState) core.SectorTypedIndexer {
+	if state.Upgraded {
+		return s.sectorIdxer.Upgrade()
+	}
+
+	return s.sectorIdxer.Normal()
 }
 
-func (s *Sealer) RemoveSector(ctx context.Context, sid abi.SectorID) error {
-	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+func (s *Sealer) rebalanceSector(ctx context.Context, sid abi.SectorID, from, to objstore.Store) error {
+	release, err := s.ops.acquire(sid, "rebalance")
 	if err != nil {
-		return fmt.Errorf("load sector state: %w", err)
+		return err
 	}
+	defer release()
 
-	if state.Removed {
-		return nil
+	// the sector may have been changed since the candidates were collected
+	state, err := s.state.Load(ctx, sid, core.WorkerOffline)
+	if err != nil {
+		return sectorStateErr(err)
 	}
 
-	if state.TerminateInfo.TerminatedAt > 0 {
-		ts, err := s.capi.ChainHead(ctx)
-		if err != nil {
-			return fmt.Errorf("getting chain head: %w", err)
-		}
+	if !rebalanceable(state) {
+		return fmt.Errorf("sector is not finalized, or has been aborted or removed")
+	}
 
-		nv, err := s.capi.StateNetworkVersion(ctx, ts.Key())
-		if err != nil {
-			return fmt.Errorf("getting network version: %w", err)
-		}
+	indexer := s.sectorTypedIndexer(state)
+	access, has, err := indexer.Find(ctx, sid)
+	if err != nil {
+		return fmt.Errorf("find objstore instance: %w", err)
+	}
 
-		if ts.Height() < state.TerminateInfo.TerminatedAt+specpolicy.GetWinningPoStSectorSetLookback(nv) {
-			height := state.TerminateInfo.TerminatedAt + specpolicy.GetWinningPoStSectorSetLookback(nv)
-			return fmt.Errorf("wait for expiration(+winning lookback?): %v", height)
-		}
+	if !has {
+		return fmt.Errorf("sector not indexed")
 	}
 
-	dest := s.sectorIdxer.Normal()
+	cacheType, sealedType := util.SectorPathTypeCache, util.SectorPathTypeSealed
 	if state.Upgraded {
-		dest = s.sectorIdxer.Upgrade()
+		cacheType, sealedType = util.SectorPathTypeUpdateCache, util.SectorPathTypeUpdate
 	}
 
-	access, has, err := dest.Find(ctx, sid)
-	if err != nil {
-		return fmt.Errorf("find objstore instance: %w", err)
+	// the layouts of the two instances have been checked to be the same
+	cache := util.StoreSectorPath(from.InstanceConfig(ctx).Meta, cacheType, sid)
+	sealed := util.StoreSectorPath(from.InstanceConfig(ctx).Meta, sealedType, sid)
+
+	var files []string
+	moved := access
+	if access.SealedFile == from.Instance(ctx) {
+		files = append(files, sealed)
+		moved.SealedFile = to.Instance(ctx)
 	}
-	if !has {
-		return fmt.Errorf("object not found")
+
+	if access.CacheDir == from.Instance(ctx) {
+		cached, err := listCacheFiles(from.FullPath(ctx, cache), cache)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, cached...)
+		moved.CacheDir = to.Instance(ctx)
 	}
 
-	sealedFile, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.SealedFile)
-	if err != nil {
-		return fmt.Errorf("get objstore instance %s for sealed file: %w", access.SealedFile, err)
+	if moved == access {
+		return fmt.Errorf("sector files are not on %s", from.Instance(ctx))
 	}
 
-	cacheDir, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, access.CacheDir)
-	if err != nil {
-		return fmt.Errorf("get objstore instance %s for cache dir: %w", access.CacheDir, err)
+	// the files copied so far will be removed from the destination if anything goes wrong before
+	// the indexer being updated, so that no space is leaked on it
+	var copied []string
+	rollback := func() {
+		for i := len(copied) - 1; i >= 0; i-- {
+			if err := to.Del(ctx, copied[i]); err != nil {
+				log.Warnw("remove copied file", "store", to.Instance(ctx), "path", copied[i], "err", err)
+			}
+		}
+
+		if moved.CacheDir != access.CacheDir {
+			_ = os.Remove(to.FullPath(ctx, cache))
+		}
 	}
 
-	var cache string
-	var sealed string
-	if state.Upgraded {
-		cache = util.SectorPath(util.SectorPathTypeUpdateCache, state.ID)
-		sealed = util.SectorPath(util.SectorPathTypeUpdate, state.ID)
-	} else {
-		cache = util.SectorPath(util.SectorPathTypeCache, state.ID)
-		sealed = util.SectorPath(util.SectorPathTypeSealed, state.ID)
+	if moved.CacheDir != access.CacheDir {
+		if err := os.MkdirAll(to.FullPath(ctx, cache), 0755); err != nil {
+			return fmt.Errorf("create cache dir: %w", err)
+		}
 	}
 
-	cachePath := cacheDir.FullPath(ctx, cache)
-	err = os.RemoveAll(cachePath)
-	if err != nil {
-		return fmt.Errorf("remove cache: %w", err)
+	for _, fpath := range files {
+		if err := copyObject(ctx, fpath, from, to); err != nil {
+			copied = append(copied, fpath)
+			rollback()
+			return err
+		}
+
+		copied = append(copied, fpath)
 	}
 
-	sealedPath := sealedFile.FullPath(ctx, sealed)
-	err = os.Remove(sealedPath)
+	err = indexer.Update(ctx, sid, moved)
 	if err != nil {
-		return fmt.Errorf("remove sealed file: %w", err)
+		rollback()
+		return fmt.Errorf("update sector indexer: %w", err)
 	}
 
-	state.Removed = true
-	err = s.state.Update(ctx, state.ID, core.WorkerOffline, state.Removed)
-	if err != nil {
-		return fmt.Errorf("update sector Removed failed: %w", err)
+	// the sector is accessed through the new instance from now on, failures on cleaning up will only be logged
+	for _, fpath := range files {
+		if err := from.Del(ctx, fpath); err != nil {
+			log.Warnw("remove source file", "store", from.Instance(ctx), "path", fpath, "err", err)
+		}
+	}
+
+	if moved.CacheDir != access.CacheDir {
+		if err := os.RemoveAll(from.FullPath(ctx, cache)); err != nil {
+			log.Warnw("remove source cache dir", "store", from.Instance(ctx), "path", cache, "err", err)
+		}
 	}
 
 	return nil
 }
 
-func (s *Sealer) FinalizeSector(ctx context.Context, sid abi.SectorID) error {
-	maddr, err := address.NewIDAddress(uint64(sid.Miner))
-	if err != nil {
-		return fmt.Errorf("invalid mienr actor id: %w", err)
-	}
+// listCacheFiles returns the paths, relative to the store, of all the files in the cache dir,
+// not only the ones required by the proofs, so that nothing is left behind.
+func listCacheFiles(dir string, relDir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 
-	ts, err := s.capi.ChainHead(ctx)
-	if err != nil {
-		return fmt.Errorf("getting chain head: %w", err)
-	}
+		if d.IsDir() {
+			return nil
+		}
 
-	si, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, ts.Key())
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.Join(relDir, rel))
+		return nil
+	})
 	if err != nil {
-		return err
-	}
-	if si == nil {
-		return fmt.Errorf("sector %d for miner %s not found", sid, maddr)
+		return nil, fmt.Errorf("list cache dir %s: %w", dir, err)
 	}
 
-	if err := s.state.Finalize(ctx, sid, func(st *core.SectorState) (bool, error) {
-		return true, nil
-	}); err != nil {
-		return sectorStateErr(err)
+	return files, nil
+}
+
+// copyObject copies the object from src to dest, and verifies the size of the copy.
+func copyObject(ctx context.Context, p string, src, dest objstore.Store) error {
+	stat, err := src.Stat(ctx, p)
+	if err != nil {
+		return fmt.Errorf("stat %s in %s: %w", p, src.Instance(ctx), err)
 	}
 
-	if _, err := s.sectorIdxer.StoreMgr().ReleaseReserved(ctx, sid); err != nil {
-		log.With("sector", util.FormatSectorID(sid)).Errorf("release reserved: %s", err)
+	r, err := src.Get(ctx, p)
+	if err != nil {
+		return fmt.Errorf("open %s in %s: %w", p, src.Instance(ctx), err)
 	}
 
-	return nil
-}
+	defer r.Close()
 
-func (s *Sealer) StoreReleaseReserved(ctx context.Context, sid abi.SectorID) (bool, error) {
-	done, err := s.sectorIdxer.StoreMgr().ReleaseReserved(ctx, sid)
+	count, err := dest.Put(ctx, p, r)
 	if err != nil {
-		return false, fmt.Errorf("release reserved: %w", err)
+		return fmt.Errorf("copy %s into %s: %w", p, dest.Instance(ctx), err)
 	}
 
-	return done, nil
-}
-
-func (s *Sealer) StoreList(ctx context.Context) ([]core.StoreDetailedInfo, error) {
-	infos, err := s.sectorIdxer.StoreMgr().ListInstances(ctx)
+	copied, err := dest.Stat(ctx, p)
 	if err != nil {
-		return nil, fmt.Errorf("list instances: %w", err)
+		return fmt.Errorf("stat %s in %s: %w", p, dest.Instance(ctx), err)
 	}
 
-	details := make([]core.StoreDetailedInfo, 0, len(infos))
-	for i := range infos {
-		reservedBy := make([]core.ReservedItem, 0, len(infos[i].Reserved.Reserved))
-		for _, res := range infos[i].Reserved.Reserved {
-			reservedBy = append(reservedBy, res)
-		}
-		sort.Slice(reservedBy, func(i, j int) bool {
-			if reservedBy[i].At != reservedBy[j].At {
-				return reservedBy[i].At < reservedBy[j].At
-			}
+	if count != stat.Size || copied.Size != stat.Size {
+		return fmt.Errorf("incomplete copy of %s: got %d bytes, expect %d", p, copied.Size, stat.Size)
+	}
 
-			return reservedBy[i].By < reservedBy[j].By
-		})
+	return nil
+}
 
-		details = append(details, core.StoreDetailedInfo{
-			StoreBasicInfo: storeConfig2StoreBasic(&infos[i].Instance.Config),
-			Type:           infos[i].Instance.Type,
-			Total:          infos[i].Instance.Total,
-			Free:           infos[i].Instance.Free,
-			Used:           infos[i].Instance.Used,
-			UsedPercent:    infos[i].Instance.UsedPercent,
-			Reserved:       infos[i].Reserved.ReservedSize,
-			ReservedBy:     reservedBy,
-		})
+func storeInfo2StoreDetailed(info *objstore.StoreInfo) core.StoreDetailedInfo {
+	reservedBy := make([]core.ReservedItem, 0, len(info.Reserved.Reserved))
+	for _, res := range info.Reserved.Reserved {
+		reservedBy = append(reservedBy, res)
 	}
+	sort.Slice(reservedBy, func(i, j int) bool {
+		if reservedBy[i].At != reservedBy[j].At {
+			return reservedBy[i].At < reservedBy[j].At
+		}
 
-	return details, nil
+		return reservedBy[i].By < reservedBy[j].By
+	})
+
+	return core.StoreDetailedInfo{
+		StoreBasicInfo: storeConfig2StoreBasic(&info.Instance.Config),
+		Type:           info.Instance.Type,
+		Total:          info.Instance.Total,
+		Free:           info.Instance.Free,
+		Used:           info.Instance.Used,
+		UsedPercent:    info.Instance.UsedPercent,
+		Reserved:       info.Reserved.ReservedSize,
+		ReservedBy:     reservedBy,
+	}
 }
 
 func storeConfig2StoreBasic(ocfg *objstore.Config) core.StoreBasicInfo {
@@ -480,8 +2817,327 @@ func (s *Sealer) ImportSector(
 	return s.state.Import(ctx, ws, state, override)
 }
 
+// ImportSectorsFromDir scans the dir for the sector files of the miner, and imports the sectors found,
+// with the minimal states reconstructed from the on-chain sector infos and the sidecar core.SectorImportMeta.
+// The sectors missing files or required metadata, e.g. the ticket which the chain doesn't keep
+// for the proven sectors, are flagged in the result instead of being imported.
+func (s *Sealer) ImportSectorsFromDir(
+	ctx context.Context,
+	mid abi.ActorID,
+	storePath string,
+) (*core.SectorsDirImportResult, error) {
+	root, err := filepath.Abs(storePath)
+	if err != nil {
+		return nil, fmt.Errorf("get absolute path of %s: %w", storePath, err)
+	}
+
+	instance, err := s.storeInstanceAt(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	// the dir is laid out as the store instance at it, or the default layout if none
+	layout := util.DefaultSectorPathLayout
+	if instance != "" {
+		store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, instance)
+		if err != nil {
+			return nil, fmt.Errorf("get objstore instance %s: %w", instance, err)
+		}
+
+		layout, err = util.ParseSectorPathLayout(store.InstanceConfig(ctx).Meta)
+		if err != nil {
+			return nil, fmt.Errorf("parse sector path layout of %s: %w", instance, err)
+		}
+	}
+
+	found, err := scanDirSectorFiles(root, layout, mid)
+	if err != nil {
+		return nil, fmt.Errorf("scan sector files in %s: %w", root, err)
+	}
+
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return nil, fmt.Errorf("invalid miner actor id: %w", err)
+	}
+
+	sids := make([]abi.SectorID, 0, len(found))
+	for sid := range found {
+		sids = append(sids, sid)
+	}
+	sort.Slice(sids, func(i, j int) bool { return sids[i].Number < sids[j].Number })
+
+	res := &core.SectorsDirImportResult{
+		Instance: instance,
+		Sectors:  make([]core.SectorDirImport, 0, len(sids)),
+	}
+
+	for _, sid := range sids {
+		item, err := s.importSectorFromDir(ctx, maddr, root, sid, found[sid], instance)
+		if err != nil {
+			return nil, fmt.Errorf("import %s: %w", util.FormatSectorID(sid), err)
+		}
+
+		res.Sectors = append(res.Sectors, item)
+	}
+
+	return res, nil
+}
+
+func (s *Sealer) importSectorFromDir(
+	ctx context.Context,
+	maddr address.Address,
+	root string,
+	sid abi.SectorID,
+	files *sectorFilesInDir,
+	instance string,
+) (core.SectorDirImport, error) {
+	item := core.SectorDirImport{
+		ID:       sid,
+		Upgraded: files.update || files.updateCache,
+	}
+
+	info, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return item, fmt.Errorf("get on-chain sector info: %w", err)
+	}
+
+	if info == nil {
+		item.Problems = append(item.Problems, "sector info not found on chain")
+		return item, nil
+	}
+
+	if upgraded := info.SectorKeyCID != nil; upgraded != item.Upgraded {
+		item.Problems = append(
+			item.Problems,
+			fmt.Sprintf("upgraded is %t on chain, but %t by the files", upgraded, item.Upgraded),
+		)
+		return item, nil
+	}
+
+	if item.Upgraded {
+		if !files.update {
+			item.Problems = append(item.Problems, "update file not found")
+		}
+		if !files.updateCache {
+			item.Problems = append(item.Problems, "update cache dir not found")
+		}
+	} else {
+		if !files.sealed {
+			item.Problems = append(item.Problems, "sealed file not found")
+		}
+		if !files.cache {
+			item.Problems = append(item.Problems, "cache dir not found")
+		}
+	}
+
+	meta, err := loadSectorImportMeta(root, sid)
+	if err != nil {
+		return item, err
+	}
+
+	state := &core.SectorState{
+		ID:         sid,
+		SectorType: info.SealProof,
+		Pre: &core.PreCommitInfo{
+			CommR: info.SealedCID,
+		},
+		Finalized: true,
+		Upgraded:  core.SectorUpgraded(item.Upgraded),
+		Imported:  true,
+	}
+
+	if meta == nil {
+		item.Problems = append(
+			item.Problems,
+			fmt.Sprintf("ticket not found, provide it in %s", sectorImportMetaPath(root, sid)),
+		)
+	} else {
+		ticket := meta.Ticket
+		if len(ticket.Ticket) == 0 && ticket.Epoch > 0 {
+			ticket, err = s.rand.GetTicket(ctx, types.EmptyTSK, ticket.Epoch, sid.Miner)
+			if err != nil {
+				return item, fmt.Errorf("rederive ticket at %d: %w", meta.Ticket.Epoch, err)
+			}
+		}
+
+		state.Ticket = &ticket
+		state.Pre.Ticket = ticket
+		state.Seed = meta.Seed
+	}
+
+	if item.Upgraded {
+		commR, err := util.CID2ReplicaCommitment(*info.SectorKeyCID)
+		if err != nil {
+			return item, fmt.Errorf("convert sector key cid to commitment: %w", err)
+		}
+
+		state.Pre.CommR = *info.SectorKeyCID
+		state.UpgradePublic = &core.SectorUpgradePublic{
+			CommR:      commR,
+			SealedCID:  *info.SectorKeyCID,
+			Activation: info.Activation,
+			Expiration: info.Expiration,
+		}
+		state.UpgradedInfo = &core.SectorUpgradedInfo{
+			SealedCID: info.SealedCID,
+		}
+	}
+
+	for _, verr := range state.Validate() {
+		item.Problems = append(item.Problems, verr.Error())
+	}
+
+	if len(item.Problems) > 0 {
+		return item, nil
+	}
+
+	imported, err := s.state.Import(ctx, core.WorkerOffline, state, false)
+	if err != nil {
+		return item, fmt.Errorf("import sector state: %w", err)
+	}
+
+	if !imported {
+		item.Problems = append(item.Problems, "sector state already exists")
+		return item, nil
+	}
+
+	item.Imported = true
+	if instance == "" {
+		return item, nil
+	}
+
+	indexer, access := s.sectorIdxer.Normal(), core.SectorAccessStores{SealedFile: instance, CacheDir: instance}
+	if item.Upgraded {
+		indexer = s.sectorIdxer.Upgrade()
+	}
+
+	if err := indexer.Update(ctx, sid, access); err != nil {
+		return item, fmt.Errorf("update sector index: %w", err)
+	}
+
+	return item, nil
+}
+
+// SetSectorLabels merges the labels into the ones of the sector, the labels with empty values are removed.
+// It returns the labels after merging.
+func (s *Sealer) SetSectorLabels(
+	ctx context.Context,
+	sid abi.SectorID,
+	labels map[string]string,
+) (core.SectorLabels, error) {
+	release, err := s.ops.acquire(sid, "set labels")
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	ws := core.WorkerOffline
+	state, err := s.state.Load(ctx, sid, ws)
+	if errors.Is(err, kvstore.ErrKeyNotFound) {
+		ws = core.WorkerOnline
+		state, err = s.state.Load(ctx, sid, ws)
+	}
+	if err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	merged := make(core.SectorLabels, len(state.Labels)+len(labels))
+	for k, v := range state.Labels {
+		merged[k] = v
+	}
+
+	for k, v := range labels {
+		if k == "" {
+			return nil, fmt.Errorf("empty label key")
+		}
+
+		if v == "" {
+			delete(merged, k)
+			continue
+		}
+
+		merged[k] = v
+	}
+
+	if err := s.state.Update(ctx, sid, ws, merged); err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	return merged, nil
+}
+
+// RederiveTicket recomputes the ticket of the sector at the given epoch, and stores it back into the sector state.
+// It is used to recover the sectors whose ticket info is lost, so that they can be rebuilt.
+func (s *Sealer) RederiveTicket(ctx context.Context, sid abi.SectorID, epoch abi.ChainEpoch) (*core.Ticket, error) {
+	ts, err := s.capi.ChainHead(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get chain head: %w", err)
+	}
+
+	if epoch < 0 || epoch > ts.Height()-policy.SealRandomnessLookback {
+		return nil, fmt.Errorf("ticket epoch %d out of range, current height %d", epoch, ts.Height())
+	}
+
+	ws := core.WorkerOffline
+	state, err := s.state.Load(ctx, sid, ws)
+	if errors.Is(err, kvstore.ErrKeyNotFound) {
+		ws = core.WorkerOnline
+		state, err = s.state.Load(ctx, sid, ws)
+	}
+	if err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	if state.Removed {
+		return nil, fmt.Errorf("sector has been removed")
+	}
+
+	ticket, err := s.rand.GetTicket(ctx, ts.Key(), epoch, sid.Miner)
+	if err != nil {
+		return nil, fmt.Errorf("get ticket at %d: %w", epoch, err)
+	}
+
+	// the sector must not be sealed with another ticket
+	if state.Pre != nil && len(state.Pre.Ticket.Ticket) > 0 && !ticketEqual(state.Pre.Ticket, ticket) {
+		return nil, fmt.Errorf(
+			"sector has been pre-committed with the ticket at %d, which differs from the one at %d",
+			state.Pre.Ticket.Epoch,
+			epoch,
+		)
+	}
+
+	if state.Ticket != nil && len(state.Ticket.Ticket) > 0 {
+		if !ticketEqual(*state.Ticket, ticket) {
+			return nil, fmt.Errorf(
+				"sector already has the ticket at %d, which differs from the one at %d",
+				state.Ticket.Epoch,
+				epoch,
+			)
+		}
+
+		return &ticket, nil
+	}
+
+	if err := s.state.Update(ctx, sid, ws, &ticket); err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	log.With("sector", util.FormatSectorID(sid)).Infow("ticket rederived", "epoch", epoch)
+	return &ticket, nil
+}
+
+func ticketEqual(a, b core.Ticket) bool {
+	return a.Epoch == b.Epoch && bytes.Equal(a.Ticket, b.Ticket)
+}
+
 func (s *Sealer) SectorSetForRebuild(ctx context.Context, sid abi.SectorID, opt core.RebuildOptions) (bool, error) {
-	_, err := s.scfg.MinerConfig(sid.Miner)
+	release, err := s.ops.acquire(sid, "rebuild setup")
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	_, err = s.scfg.MinerConfig(sid.Miner)
 	if err != nil {
 		return false, fmt.Errorf("miner config unavailable: %w", err)
 	}
@@ -561,9 +3217,139 @@ func (s *Sealer) SectorSetForRebuild(ctx context.Context, sid abi.SectorID, opt
 		return false, fmt.Errorf("set rebuild info: %w", err)
 	}
 
+	s.emitSectorEvent(sid, core.SectorEventRebuildScheduled)
 	return true, nil
 }
 
+func (s *Sealer) ListRebuildSectors(ctx context.Context, mid *abi.ActorID) ([]core.SectorRebuildStatus, error) {
+	infos, err := s.rebuild.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load rebuild infos: %w", err)
+	}
+
+	pending := make(map[abi.SectorID]core.SectorRebuildInfo, len(infos))
+	for i := range infos {
+		pending[infos[i].Sector.ID] = infos[i]
+	}
+
+	var sectors []core.SectorRebuildStatus
+	err = s.state.ForEach(ctx, core.WorkerOnline, core.SectorWorkerJobRebuild, func(ss core.SectorState) error {
+		if mid != nil && ss.ID.Miner != *mid {
+			return nil
+		}
+
+		status := core.SectorRebuildStatus{
+			ID: ss.ID,
+		}
+
+		if info, ok := pending[ss.ID]; ok {
+			status.Info = &info
+		}
+
+		sectors = append(sectors, status)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate sectors: %w", err)
+	}
+
+	sort.Slice(sectors, func(i, j int) bool {
+		if sectors[i].ID.Miner != sectors[j].ID.Miner {
+			return sectors[i].ID.Miner < sectors[j].ID.Miner
+		}
+
+		return sectors[i].ID.Number < sectors[j].ID.Number
+	})
+
+	return sectors, nil
+}
+
+// RebuildProgress returns the progress of a sector being rebuilt, based on the latest state reported by the worker.
+func (s *Sealer) RebuildProgress(ctx context.Context, sid abi.SectorID) (*core.SectorRebuildProgress, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOnline)
+	if err != nil {
+		return nil, sectorStateErr(err)
+	}
+
+	if !state.NeedRebuild {
+		return nil, fmt.Errorf("sector is not being rebuilt")
+	}
+
+	progress := core.RebuildProgressOf(sid, state.LatestState)
+	return &progress, nil
+}
+
+// ValidateUpgradeInfo checks the UpgradePublic & UpgradedInfo stored in the sector state against the sector info
+// on chain, the sector key of a snapup sector should be the sealed cid before the upgrade.
+func (s *Sealer) ValidateUpgradeInfo(ctx context.Context, sid abi.SectorID) (*core.SectorUpgradeValidation, error) {
+	state, err := s.state.Load(ctx, sid, core.WorkerOnline)
+	if err != nil {
+		if !errors.Is(err, kvstore.ErrKeyNotFound) {
+			return nil, sectorStateErr(err)
+		}
+
+		state, err = s.state.Load(ctx, sid, core.WorkerOffline)
+		if err != nil {
+			return nil, sectorStateErr(err)
+		}
+	}
+
+	maddr, err := address.NewIDAddress(uint64(sid.Miner))
+	if err != nil {
+		return nil, fmt.Errorf("construct miner address: %w", err)
+	}
+
+	sinfo, err := s.capi.StateSectorGetInfo(ctx, maddr, sid.Number, types.EmptyTSK)
+	if err != nil {
+		return nil, fmt.Errorf("get sector info: %w", err)
+	}
+
+	if sinfo == nil {
+		return nil, fmt.Errorf("sector not found on chain")
+	}
+
+	res := &core.SectorUpgradeValidation{
+		ID:           sid,
+		SectorKeyCID: sinfo.SectorKeyCID,
+		SealedCID:    sinfo.SealedCID,
+	}
+
+	mismatch := func(format string, args ...any) {
+		res.Mismatches = append(res.Mismatches, fmt.Sprintf(format, args...))
+	}
+
+	if sinfo.SectorKeyCID == nil {
+		if state.UpgradePublic != nil || state.UpgradedInfo != nil {
+			mismatch("upgrade info exists, but the sector is not upgraded on chain")
+		}
+
+		return res, nil
+	}
+
+	if pub := state.UpgradePublic; pub == nil {
+		mismatch("upgrade public info is missing")
+	} else {
+		if !pub.SealedCID.Equals(*sinfo.SectorKeyCID) {
+			mismatch("sealed cid in upgrade public info %s, sector key on chain %s", pub.SealedCID, *sinfo.SectorKeyCID)
+		}
+
+		commR, err := util.CID2ReplicaCommitment(*sinfo.SectorKeyCID)
+		if err != nil {
+			mismatch("invalid sector key on chain: %s", err)
+		} else if pub.CommR != commR {
+			mismatch("comm_r in upgrade public info does not match the sector key on chain")
+		}
+	}
+
+	if info := state.UpgradedInfo; info == nil {
+		mismatch("upgraded info is missing")
+	} else if !info.SealedCID.Equals(sinfo.SealedCID) {
+		mismatch("sealed cid in upgraded info %s, sealed cid on chain %s", info.SealedCID, sinfo.SealedCID)
+	}
+
+	return res, nil
+}
+
 func (s *Sealer) UnsealPiece(
 	ctx context.Context,
 	sid abi.SectorID,
@@ -662,6 +3448,51 @@ func (s *Sealer) UnsealPiece(
 	return stream, nil
 }
 
+func (*Sealer) APIFingerprint(_ context.Context) (map[string]string, error) {
+	return core.APIFingerprint(), nil
+}
+
+// SetPieceBandwidthLimit changes the bandwidth limits of the piece transfers served by the piecestore proxy,
+// in bytes per second, 0 means no limit.
+func (s *Sealer) SetPieceBandwidthLimit(_ context.Context, perTransfer, aggregate int64) error {
+	if perTransfer < 0 || aggregate < 0 {
+		return fmt.Errorf("bandwidth limits should not be negative")
+	}
+
+	s.pieceStore.SetBandwidthLimit(perTransfer, aggregate)
+	return nil
+}
+
+func (s *Sealer) ListLocalPieces(ctx context.Context, offset, limit int) ([]core.LocalPiece, error) {
+	pieces, err := s.pieceStore.ListLocalPieces(ctx, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list local pieces: %w", err)
+	}
+
+	res := make([]core.LocalPiece, 0, len(pieces))
+	for _, p := range pieces {
+		res = append(res, core.LocalPiece(p))
+	}
+
+	return res, nil
+}
+
+func (s *Sealer) ProverInfo(_ context.Context) (*core.ProverInfo, error) {
+	// only the nvidia devices are detectable for now
+	gpus, err := filepath.Glob("/dev/nvidia[0-9]*")
+	if err != nil {
+		return nil, fmt.Errorf("detect gpu devices: %w", err)
+	}
+
+	return &core.ProverInfo{
+		Backend:        ver.Prover,
+		Production:     ver.ProverIsProd(),
+		Version:        ver.VersionStr(),
+		Implementation: fmt.Sprintf("%T", s.prover),
+		GPUs:           gpus,
+	}, nil
+}
+
 func (*Sealer) Version(_ context.Context) (string, error) {
 	return ver.VersionStr(), nil
 }
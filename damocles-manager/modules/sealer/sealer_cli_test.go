@@ -0,0 +1,273 @@
+package sealer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	managerplugin "github.com/ipfs-force-community/damocles/manager-plugin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/impl/sectors"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/chain"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/kvstore"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore/filestore"
+	"github.com/ipfs-force-community/damocles/damocles-manager/testutil"
+)
+
+type mockSectorInfoChain struct {
+	chain.API
+	infos map[abi.SectorNumber]*types.SectorOnChainInfo
+}
+
+func (m *mockSectorInfoChain) StateSectorGetInfo(
+	_ context.Context,
+	_ address.Address,
+	n abi.SectorNumber,
+	_ types.TipSetKey,
+) (*types.SectorOnChainInfo, error) {
+	return m.infos[n], nil
+}
+
+func TestImportSectorsFromDir(t *testing.T) {
+	ctx := context.Background()
+
+	storeMgr, err := objstore.NewStoreManager(nil, nil, testutil.BadgerKVStore(t, "store"))
+	require.NoError(t, err, "construct store mgr")
+
+	indexer, err := sectors.NewIndexer(
+		storeMgr,
+		testutil.BadgerKVStore(t, "normal"),
+		testutil.BadgerKVStore(t, "upgrade"),
+	)
+	require.NoError(t, err, "construct indexer")
+
+	stateMgr, err := sectors.NewStateManager(
+		testutil.BadgerKVStore(t, "online"),
+		testutil.BadgerKVStore(t, "offline"),
+		&managerplugin.LoadedPlugins{},
+	)
+	require.NoError(t, err, "construct state mgr")
+
+	commR, err := util.ReplicaCommitment2CID([32]byte{1})
+	require.NoError(t, err, "construct comm_r")
+
+	mid := abi.ActorID(1000)
+	withMeta := abi.SectorID{Miner: mid, Number: 1}
+	withoutMeta := abi.SectorID{Miner: mid, Number: 2}
+
+	infos := map[abi.SectorNumber]*types.SectorOnChainInfo{}
+	for _, sid := range []abi.SectorID{withMeta, withoutMeta} {
+		infos[sid.Number] = &types.SectorOnChainInfo{
+			SectorNumber: sid.Number,
+			SealProof:    abi.RegisteredSealProof_StackedDrg2KiBV1_1,
+			SealedCID:    commR,
+		}
+	}
+
+	s := &Sealer{
+		capi:        &mockSectorInfoChain{infos: infos},
+		state:       stateMgr,
+		sectorIdxer: indexer,
+	}
+
+	root := t.TempDir()
+	for _, sid := range []abi.SectorID{withMeta, withoutMeta} {
+		cacheDir := filepath.Join(root, util.SectorPath(util.SectorPathTypeCache, sid))
+		require.NoError(t, os.MkdirAll(cacheDir, 0o755), "create cache dir")
+
+		sealedFile := filepath.Join(root, util.SectorPath(util.SectorPathTypeSealed, sid))
+		require.NoError(t, os.MkdirAll(filepath.Dir(sealedFile), 0o755), "create sealed dir")
+		require.NoError(t, os.WriteFile(sealedFile, []byte("sealed"), 0o644), "create sealed file")
+	}
+
+	ticket := core.Ticket{Ticket: abi.Randomness{1, 2, 3}, Epoch: 100}
+	meta, err := json.Marshal(core.SectorImportMeta{Ticket: ticket})
+	require.NoError(t, err, "marshal sector import meta")
+
+	metaPath := sectorImportMetaPath(root, withMeta)
+	require.NoError(t, os.MkdirAll(filepath.Dir(metaPath), 0o755), "create meta dir")
+	require.NoError(t, os.WriteFile(metaPath, meta, 0o644), "write sector import meta")
+
+	res, err := s.ImportSectorsFromDir(ctx, mid, root)
+	require.NoError(t, err, "import sectors from dir")
+	require.Equal(t, "", res.Instance)
+	require.Len(t, res.Sectors, 2)
+
+	require.Equal(t, withMeta, res.Sectors[0].ID)
+	require.True(t, res.Sectors[0].Imported, "problems: %v", res.Sectors[0].Problems)
+	require.Empty(t, res.Sectors[0].Problems)
+
+	require.Equal(t, withoutMeta, res.Sectors[1].ID)
+	require.False(t, res.Sectors[1].Imported)
+	require.NotEmpty(t, res.Sectors[1].Problems)
+
+	state, err := stateMgr.Load(ctx, withMeta, core.WorkerOffline)
+	require.NoError(t, err, "load imported sector state")
+	require.True(t, state.Imported)
+	require.True(t, state.Finalized)
+	require.Equal(t, &ticket, state.Ticket)
+	require.Equal(t, commR, state.Pre.CommR)
+
+	_, err = stateMgr.Load(ctx, withoutMeta, core.WorkerOffline)
+	require.ErrorIs(t, err, kvstore.ErrKeyNotFound)
+}
+
+func TestReindexStore(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	store, err := filestore.Open(objstore.Config{Name: "store", Path: root}, false)
+	require.NoError(t, err, "open file store")
+
+	storeMgr, err := objstore.NewStoreManager([]objstore.Store{store}, nil, testutil.BadgerKVStore(t, "store"))
+	require.NoError(t, err, "construct store mgr")
+
+	indexer, err := sectors.NewIndexer(
+		storeMgr,
+		testutil.BadgerKVStore(t, "normal"),
+		testutil.BadgerKVStore(t, "upgrade"),
+	)
+	require.NoError(t, err, "construct indexer")
+
+	stateMgr, err := sectors.NewStateManager(
+		testutil.BadgerKVStore(t, "online"),
+		testutil.BadgerKVStore(t, "offline"),
+		&managerplugin.LoadedPlugins{},
+	)
+	require.NoError(t, err, "construct state mgr")
+
+	s := &Sealer{
+		state:       stateMgr,
+		sectorIdxer: indexer,
+	}
+
+	mid := abi.ActorID(1000)
+	lost := abi.SectorID{Miner: mid, Number: 1}
+	conflicting := abi.SectorID{Miner: mid, Number: 2}
+	stateless := abi.SectorID{Miner: mid, Number: 3}
+
+	for _, sid := range []abi.SectorID{lost, conflicting, stateless} {
+		cacheDir := filepath.Join(root, util.SectorPath(util.SectorPathTypeCache, sid))
+		require.NoError(t, os.MkdirAll(cacheDir, 0o755), "create cache dir")
+
+		sealedFile := filepath.Join(root, util.SectorPath(util.SectorPathTypeSealed, sid))
+		require.NoError(t, os.MkdirAll(filepath.Dir(sealedFile), 0o755), "create sealed dir")
+		require.NoError(t, os.WriteFile(sealedFile, []byte("sealed"), 0o644), "create sealed file")
+	}
+
+	for _, sid := range []abi.SectorID{lost, conflicting} {
+		_, err := stateMgr.Import(ctx, core.WorkerOffline, &core.SectorState{ID: sid, Finalized: true}, false)
+		require.NoError(t, err, "import sector state")
+	}
+
+	other := core.SectorAccessStores{SealedFile: "other", CacheDir: "other"}
+	require.NoError(t, indexer.Normal().Update(ctx, conflicting, other), "index the conflicting sector")
+
+	res, err := s.ReindexStore(ctx, "store", false)
+	require.NoError(t, err, "reindex store")
+	require.Equal(t, []abi.SectorID{lost}, res.Found.SealedFile)
+	require.Equal(t, []abi.SectorID{lost}, res.Found.CacheDir)
+	require.Len(t, res.Skipped, 4)
+
+	skipped := map[abi.SectorID]int{}
+	for _, item := range res.Skipped {
+		skipped[item.ID]++
+	}
+	require.Equal(t, map[abi.SectorID]int{conflicting: 2, stateless: 2}, skipped)
+
+	access, has, err := indexer.Normal().Find(ctx, lost)
+	require.NoError(t, err, "find the lost sector")
+	require.True(t, has)
+	require.Equal(t, core.SectorAccessStores{SealedFile: "store", CacheDir: "store"}, access)
+
+	access, has, err = indexer.Normal().Find(ctx, conflicting)
+	require.NoError(t, err, "find the conflicting sector")
+	require.True(t, has)
+	require.Equal(t, other, access, "the conflicting entry should be kept")
+
+	_, has, err = indexer.Normal().Find(ctx, stateless)
+	require.NoError(t, err, "find the stateless sector")
+	require.False(t, has)
+}
+
+func TestRestoreSectorExToStore(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+	store, err := filestore.Open(objstore.Config{Name: "store", Path: root}, false)
+	require.NoError(t, err, "open file store")
+
+	storeMgr, err := objstore.NewStoreManager([]objstore.Store{store}, nil, testutil.BadgerKVStore(t, "store"))
+	require.NoError(t, err, "construct store mgr")
+
+	indexer, err := sectors.NewIndexer(
+		storeMgr,
+		testutil.BadgerKVStore(t, "normal"),
+		testutil.BadgerKVStore(t, "upgrade"),
+	)
+	require.NoError(t, err, "construct indexer")
+
+	stateMgr, err := sectors.NewStateManager(
+		testutil.BadgerKVStore(t, "online"),
+		testutil.BadgerKVStore(t, "offline"),
+		&managerplugin.LoadedPlugins{},
+	)
+	require.NoError(t, err, "construct state mgr")
+
+	s := &Sealer{
+		state:       stateMgr,
+		sectorIdxer: indexer,
+		ops:         newSectorOps(),
+	}
+
+	sid := abi.SectorID{Miner: 1000, Number: 1}
+	_, err = stateMgr.Import(ctx, core.WorkerOffline, &core.SectorState{
+		ID:          sid,
+		SectorType:  abi.RegisteredSealProof_StackedDrg2KiBV1_1,
+		AbortReason: "aborted",
+	}, false)
+	require.NoError(t, err, "import sector state")
+
+	sealedFile := filepath.Join(root, util.SectorPath(util.SectorPathTypeSealed, sid))
+	require.NoError(t, os.MkdirAll(filepath.Dir(sealedFile), 0o755), "create sealed dir")
+	require.NoError(t, os.WriteFile(sealedFile, []byte("sealed"), 0o644), "create sealed file")
+
+	opts := core.RestoreSectorOptions{TargetStore: "store"}
+	_, err = s.RestoreSectorEx(ctx, sid, opts)
+	require.Error(t, err, "restore without the cache files")
+
+	state, err := stateMgr.Load(ctx, sid, core.WorkerOffline)
+	require.NoError(t, err, "the sector should be left offline")
+	require.Equal(t, "aborted", state.AbortReason)
+
+	_, has, err := indexer.Normal().Find(ctx, sid)
+	require.NoError(t, err, "find the sector")
+	require.False(t, has)
+
+	cacheDir := filepath.Join(root, util.SectorPath(util.SectorPathTypeCache, sid))
+	require.NoError(t, os.MkdirAll(cacheDir, 0o755), "create cache dir")
+	for _, p := range util.CachedFilesForSectorSize(cacheDir, 2<<10) {
+		require.NoError(t, os.WriteFile(p, []byte("cache"), 0o644), "create cache file")
+	}
+
+	_, err = s.RestoreSectorEx(ctx, sid, opts)
+	require.NoError(t, err, "restore with the sector files")
+
+	state, err = stateMgr.Load(ctx, sid, core.WorkerOnline)
+	require.NoError(t, err, "the sector should be restored")
+	require.Empty(t, state.AbortReason)
+
+	access, has, err := indexer.Normal().Find(ctx, sid)
+	require.NoError(t, err, "find the sector")
+	require.True(t, has)
+	require.Equal(t, core.SectorAccessStores{SealedFile: "store", CacheDir: "store"}, access)
+}
@@ -0,0 +1,62 @@
+package sealer
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
+	"github.com/ipfs-force-community/damocles/damocles-manager/ver"
+)
+
+// checkDestructiveOp refuses the destructive operations on the builds with the fake prover, whose view of
+// the sectors can not be trusted, unless it is forced in the config.
+func (s *Sealer) checkDestructiveOp(op string) error {
+	if ver.ProverIsProd() || s.scfg.MustCommonConfig().AllowDestructiveOpsWithFakeProver {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%s refused with the %s prover, set Common.AllowDestructiveOpsWithFakeProver to force it",
+		op,
+		ver.Prover,
+	)
+}
+
+// sectorOps tracks the mutating operations in progress, so that the conflicting ones on the same sector,
+// e.g. a remove racing a rebuild setup, fail fast instead of interleaving.
+// The entries are dropped once the operations are done, so the map only holds the sectors being operated.
+type sectorOps struct {
+	mu   sync.Mutex
+	busy map[abi.SectorID]string
+}
+
+func newSectorOps() *sectorOps {
+	return &sectorOps{
+		busy: map[abi.SectorID]string{},
+	}
+}
+
+// acquire marks the sector as being operated by op, the returned func must be called to release it.
+func (so *sectorOps) acquire(sid abi.SectorID, op string) (func(), error) {
+	so.mu.Lock()
+	defer so.mu.Unlock()
+
+	if running, ok := so.busy[sid]; ok {
+		return nil, fmt.Errorf(
+			"%w: sector %s busy, %s in progress",
+			core.APIErrCodeSectorBusy,
+			util.FormatSectorID(sid),
+			running,
+		)
+	}
+
+	so.busy[sid] = op
+	return func() {
+		so.mu.Lock()
+		delete(so.busy, sid)
+		so.mu.Unlock()
+	}, nil
+}
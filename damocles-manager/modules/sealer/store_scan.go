@@ -0,0 +1,144 @@
+package sealer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/core"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
+)
+
+// scanStoreSectorFiles finds the sector files in the store instance laid out as the sector path layout
+// of the instance, along with the files not recognized, relative to the root of the store.
+func (s *Sealer) scanStoreSectorFiles(
+	ctx context.Context,
+	instanceName string,
+) (*core.SectorsOnStore, []string, error) {
+	store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, instanceName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get objstore instance %s: %w", instanceName, err)
+	}
+
+	root := store.FullPath(ctx, "")
+	if root == "" {
+		return nil, nil, fmt.Errorf("objstore instance %s does not expose a local path", instanceName)
+	}
+
+	layout, err := util.ParseSectorPathLayout(store.InstanceConfig(ctx).Meta)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse sector path layout of %s: %w", instanceName, err)
+	}
+
+	found, unrecognized, err := layout.ScanSectorFiles(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan sector files: %w", err)
+	}
+
+	return &core.SectorsOnStore{
+		Instance:    instanceName,
+		SealedFile:  found[util.SectorPathTypeSealed],
+		CacheDir:    found[util.SectorPathTypeCache],
+		UpdateFile:  found[util.SectorPathTypeUpdate],
+		UpdateCache: found[util.SectorPathTypeUpdateCache],
+	}, unrecognized, nil
+}
+
+// sectorFilesInDir marks the types of the sector files found in a dir.
+type sectorFilesInDir struct {
+	sealed, cache, update, updateCache bool
+}
+
+// scanDirSectorFiles finds the sector files of the miner in the dir laid out as the given layout.
+func scanDirSectorFiles(
+	root string,
+	layout *util.SectorPathLayout,
+	mid abi.ActorID,
+) (map[abi.SectorID]*sectorFilesInDir, error) {
+	scanned, _, err := layout.ScanSectorFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("scan sector files: %w", err)
+	}
+
+	found := map[abi.SectorID]*sectorFilesInDir{}
+	for _, typ := range util.SectorPathTypes {
+		for _, sid := range scanned[typ] {
+			if sid.Miner != mid {
+				continue
+			}
+
+			files, ok := found[sid]
+			if !ok {
+				files = &sectorFilesInDir{}
+				found[sid] = files
+			}
+
+			switch typ {
+			case util.SectorPathTypeSealed:
+				files.sealed = true
+			case util.SectorPathTypeCache:
+				files.cache = true
+			case util.SectorPathTypeUpdate:
+				files.update = true
+			case util.SectorPathTypeUpdateCache:
+				files.updateCache = true
+			}
+		}
+	}
+
+	return found, nil
+}
+
+// sectorImportMetaDir is the sub dir holding the core.SectorImportMeta files of the sectors.
+const sectorImportMetaDir = "meta"
+
+func sectorImportMetaPath(root string, sid abi.SectorID) string {
+	return filepath.Join(root, sectorImportMetaDir, util.FormatSectorID(sid)+".json")
+}
+
+// loadSectorImportMeta reads the sidecar metadata of the sector in the dir, it returns nil if none.
+func loadSectorImportMeta(root string, sid abi.SectorID) (*core.SectorImportMeta, error) {
+	data, err := os.ReadFile(sectorImportMetaPath(root, sid))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read sector import meta: %w", err)
+	}
+
+	var meta core.SectorImportMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("unmarshal sector import meta: %w", err)
+	}
+
+	return &meta, nil
+}
+
+// storeInstanceAt returns the name of the store instance whose local path is the dir, or empty if none.
+func (s *Sealer) storeInstanceAt(ctx context.Context, dir string) (string, error) {
+	infos, err := s.sectorIdxer.StoreMgr().ListInstances(ctx)
+	if err != nil {
+		return "", fmt.Errorf("list objstore instances: %w", err)
+	}
+
+	for _, info := range infos {
+		name := info.Instance.Config.Name
+		store, err := s.sectorIdxer.StoreMgr().GetInstance(ctx, name)
+		if err != nil {
+			return "", fmt.Errorf("get objstore instance %s: %w", name, err)
+		}
+
+		if root := store.FullPath(ctx, ""); root != "" && filepath.Clean(root) == dir {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,275 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// SectorPathTemplateMetaPrefix is the prefix of the keys in the Meta of a persist store config,
+// which override the layouts of the sector files of the path types in that store,
+// e.g. `SectorPathTemplate.sealed = "sealed/{{.Miner}}/{{.Number}}"`.
+//
+// The values are Go text/templates executed with SectorPathTemplateData,
+// the path types not overridden keep the default layout of SectorPath.
+// The workers know nothing about the templates, so the stores with any of them are never reserved for sealing.
+const SectorPathTemplateMetaPrefix = "SectorPathTemplate."
+
+// SectorPathTemplateData is the data used to execute the sector path templates.
+type SectorPathTemplateData struct {
+	Type     string
+	Miner    abi.ActorID
+	Number   abi.SectorNumber
+	SectorID string
+}
+
+// the sentinel sector id used to validate the templates and to derive the patterns matching the rendered paths,
+// the numbers are chosen so that neither of them is a part of the other one.
+var sentinelSectorID = abi.SectorID{Miner: 918273645, Number: 546372819}
+
+// SectorPathLayout resolves the paths of the sector files in a store, see SectorPathTemplateMetaPrefix.
+// The zero value is the default layout.
+type SectorPathLayout struct {
+	texts map[pathType]string
+	tmpls map[pathType]*template.Template
+}
+
+// DefaultSectorPathLayout lays out the sector files as SectorPath.
+var DefaultSectorPathLayout = &SectorPathLayout{}
+
+var sectorPathLayouts sync.Map
+
+// ParseSectorPathLayout parses the sector path templates in the Meta of a persist store config.
+// The templates must render relative paths, without `..`, which tell the sectors apart.
+func ParseSectorPathLayout(meta map[string]string) (*SectorPathLayout, error) {
+	texts := map[pathType]string{}
+	for key, text := range meta {
+		name, ok := strings.CutPrefix(key, SectorPathTemplateMetaPrefix)
+		if !ok {
+			continue
+		}
+
+		typ := pathType(name)
+		switch typ {
+		case SectorPathTypeCache, SectorPathTypeSealed, SectorPathTypeUpdate, SectorPathTypeUpdateCache:
+		default:
+			return nil, fmt.Errorf("unknown sector path type %q in %s", name, key)
+		}
+
+		texts[typ] = text
+	}
+
+	if len(texts) == 0 {
+		return DefaultSectorPathLayout, nil
+	}
+
+	cacheKey := sectorPathLayoutKey(texts)
+	if cached, ok := sectorPathLayouts.Load(cacheKey); ok {
+		return cached.(*SectorPathLayout), nil
+	}
+
+	layout := &SectorPathLayout{
+		texts: texts,
+		tmpls: make(map[pathType]*template.Template, len(texts)),
+	}
+
+	for typ, text := range texts {
+		tmpl, err := template.New(string(typ)).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parse sector path template for %s: %w", typ, err)
+		}
+
+		layout.tmpls[typ] = tmpl
+	}
+
+	rendered := map[string]pathType{}
+	for _, typ := range SectorPathTypes {
+		p, err := layout.render(typ, sentinelSectorID)
+		if err != nil {
+			return nil, fmt.Errorf("render sector path template for %s: %w", typ, err)
+		}
+
+		p = filepath.ToSlash(p)
+		if !strings.Contains(p, strconv.FormatUint(uint64(sentinelSectorID.Miner), 10)) ||
+			!strings.Contains(p, strconv.FormatUint(uint64(sentinelSectorID.Number), 10)) {
+			return nil, fmt.Errorf("sector path template for %s should contain both the miner and the sector number", typ)
+		}
+
+		if other, ok := rendered[p]; ok {
+			return nil, fmt.Errorf("sector path templates for %s and %s render the same path", other, typ)
+		}
+		rendered[p] = typ
+	}
+
+	sectorPathLayouts.Store(cacheKey, layout)
+	return layout, nil
+}
+
+func sectorPathLayoutKey(texts map[pathType]string) string {
+	keys := make([]string, 0, len(texts))
+	for typ, text := range texts {
+		keys = append(keys, string(typ)+"="+text)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\x00")
+}
+
+// StoreSectorPath returns the path of the sector file of the type in the store with the given Meta config.
+// The templates are validated while loading the store configs, it falls back to SectorPath in case
+// the Meta has been changed to something invalid since then.
+func StoreSectorPath(meta map[string]string, typ pathType, sid abi.SectorID) string {
+	layout, err := ParseSectorPathLayout(meta)
+	if err != nil {
+		return SectorPath(typ, sid)
+	}
+
+	return layout.SectorPath(typ, sid)
+}
+
+// SectorPath returns the path of the sector file of the type in this layout.
+func (l *SectorPathLayout) SectorPath(typ pathType, sid abi.SectorID) string {
+	p, err := l.render(typ, sid)
+	if err != nil {
+		return SectorPath(typ, sid)
+	}
+
+	return p
+}
+
+// IsDefault tells if the layout is the default one, i.e. none of the path types is overridden.
+func (l *SectorPathLayout) IsDefault() bool {
+	return len(l.tmpls) == 0
+}
+
+// Equal tells if the two layouts resolve the same paths for all the sectors.
+func (l *SectorPathLayout) Equal(other *SectorPathLayout) bool {
+	return sectorPathLayoutKey(l.texts) == sectorPathLayoutKey(other.texts)
+}
+
+func (l *SectorPathLayout) render(typ pathType, sid abi.SectorID) (string, error) {
+	tmpl, ok := l.tmpls[typ]
+	if !ok {
+		return SectorPath(typ, sid), nil
+	}
+
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, SectorPathTemplateData{
+		Type:     string(typ),
+		Miner:    sid.Miner,
+		Number:   sid.Number,
+		SectorID: FormatSectorID(sid),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	raw := filepath.ToSlash(sb.String())
+	if raw == "" || path.IsAbs(raw) || filepath.IsAbs(sb.String()) {
+		return "", fmt.Errorf("sector path %q should be a relative path", sb.String())
+	}
+
+	for _, elem := range strings.Split(raw, "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("sector path %q should not contain `..`", sb.String())
+		}
+	}
+
+	p := filepath.Clean(sb.String())
+	if p == "." {
+		return "", fmt.Errorf("sector path %q should not be empty", sb.String())
+	}
+
+	return p, nil
+}
+
+// sectorPathPattern matches the paths, in slash form, of the sector files of a type in a layout.
+type sectorPathPattern struct {
+	re *regexp.Regexp
+	// tells if each of the sub-matches of re is the miner or the sector number
+	isMiner []bool
+	// the static leading dirs of the paths, which contain all the files of the type
+	top string
+}
+
+// pattern derives the sectorPathPattern of the type from the path rendered for the sentinel sector id.
+// The sectors parsed by the pattern should still be confirmed by rendering their paths again.
+func (l *SectorPathLayout) pattern(typ pathType) (*sectorPathPattern, error) {
+	p, err := l.render(typ, sentinelSectorID)
+	if err != nil {
+		return nil, err
+	}
+
+	p = filepath.ToSlash(p)
+	miner := strconv.FormatUint(uint64(sentinelSectorID.Miner), 10)
+	number := strconv.FormatUint(uint64(sentinelSectorID.Number), 10)
+
+	pat := &sectorPathPattern{}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for rest := p; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, miner):
+			expr.WriteString("([0-9]+)")
+			pat.isMiner = append(pat.isMiner, true)
+			rest = rest[len(miner):]
+
+		case strings.HasPrefix(rest, number):
+			expr.WriteString("([0-9]+)")
+			pat.isMiner = append(pat.isMiner, false)
+			rest = rest[len(number):]
+
+		default:
+			expr.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+		}
+	}
+	expr.WriteString("$")
+
+	pat.re, err = regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("compile the pattern of %s: %w", typ, err)
+	}
+
+	var top []string
+	for _, elem := range strings.Split(path.Dir(p), "/") {
+		if elem == "." || strings.Contains(elem, miner) || strings.Contains(elem, number) {
+			break
+		}
+		top = append(top, elem)
+	}
+	pat.top = strings.Join(top, "/")
+
+	return pat, nil
+}
+
+// parse extracts the sector id from the path in slash form.
+func (pat *sectorPathPattern) parse(p string) (abi.SectorID, bool) {
+	matches := pat.re.FindStringSubmatch(p)
+	if matches == nil {
+		return abi.SectorID{}, false
+	}
+
+	var sid abi.SectorID
+	for i, isMiner := range pat.isMiner {
+		num, err := strconv.ParseUint(matches[i+1], 10, 64)
+		if err != nil {
+			return abi.SectorID{}, false
+		}
+
+		if isMiner {
+			sid.Miner = abi.ActorID(num)
+		} else {
+			sid.Number = abi.SectorNumber(num)
+		}
+	}
+
+	return sid, true
+}
@@ -0,0 +1,95 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSectorPathLayout(t *testing.T) {
+	sid := abi.SectorID{Miner: 1000, Number: 42}
+
+	t.Run("default", func(t *testing.T) {
+		layout, err := ParseSectorPathLayout(map[string]string{"SomeKey": "SomeValue"})
+		require.NoError(t, err)
+		require.True(t, layout.IsDefault())
+
+		for _, typ := range SectorPathTypes {
+			require.Equal(t, SectorPath(typ, sid), layout.SectorPath(typ, sid))
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		layout, err := ParseSectorPathLayout(map[string]string{
+			SectorPathTemplateMetaPrefix + "sealed": "sealed/{{.Miner}}/{{.Number}}",
+			SectorPathTemplateMetaPrefix + "cache":  "cache/{{.Miner}}/{{.SectorID}}",
+		})
+		require.NoError(t, err)
+		require.False(t, layout.IsDefault())
+
+		require.Equal(t, filepath.Join("sealed", "1000", "42"), layout.SectorPath(SectorPathTypeSealed, sid))
+		require.Equal(t, filepath.Join("cache", "1000", "s-t01000-42"), layout.SectorPath(SectorPathTypeCache, sid))
+		require.Equal(t, SectorPath(SectorPathTypeUpdate, sid), layout.SectorPath(SectorPathTypeUpdate, sid))
+		require.False(t, layout.Equal(DefaultSectorPathLayout))
+	})
+
+	for name, text := range map[string]string{
+		"absolute":        "/sealed/{{.Miner}}/{{.Number}}",
+		"parent dir":      "../sealed/{{.Miner}}/{{.Number}}",
+		"without number":  "cache/{{.Miner}}",
+		"without miner":   "sealed/{{.Number}}",
+		"unknown field":   "sealed/{{.Miner}}/{{.Number}}/{{.Unknown}}",
+		"same as default": "cache/{{.SectorID}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSectorPathLayout(map[string]string{SectorPathTemplateMetaPrefix + "sealed": text})
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := ParseSectorPathLayout(map[string]string{SectorPathTemplateMetaPrefix + "unsealed": "{{.SectorID}}"})
+		require.Error(t, err)
+	})
+}
+
+func TestScanSectorFiles(t *testing.T) {
+	layout, err := ParseSectorPathLayout(map[string]string{
+		SectorPathTemplateMetaPrefix + "sealed": "sealed/{{.Miner}}/{{.Number}}",
+		SectorPathTemplateMetaPrefix + "cache":  "{{.Miner}}/cache-{{.Number}}",
+	})
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	sids := []abi.SectorID{{Miner: 1000, Number: 1}, {Miner: 1001, Number: 2}}
+	for _, sid := range sids {
+		sealed := filepath.Join(root, layout.SectorPath(SectorPathTypeSealed, sid))
+		require.NoError(t, os.MkdirAll(filepath.Dir(sealed), 0o755))
+		require.NoError(t, os.WriteFile(sealed, []byte("sealed"), 0o644))
+
+		cache := filepath.Join(root, layout.SectorPath(SectorPathTypeCache, sid))
+		require.NoError(t, os.MkdirAll(cache, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(cache, "p_aux"), []byte("p_aux"), 0o644))
+	}
+
+	// laid out as the default layout, which is not the one of the store
+	stale := filepath.Join(root, SectorPath(SectorPathTypeSealed, sids[0]))
+	require.NoError(t, os.WriteFile(stale, []byte("sealed"), 0o644))
+
+	found, unrecognized, err := layout.ScanSectorFiles(root)
+	require.NoError(t, err)
+	require.ElementsMatch(t, sids, found[SectorPathTypeSealed])
+	require.ElementsMatch(t, sids, found[SectorPathTypeCache])
+	require.Empty(t, found[SectorPathTypeUpdate])
+	require.Empty(t, found[SectorPathTypeUpdateCache])
+	require.Equal(t, []string{SectorPath(SectorPathTypeSealed, sids[0])}, unrecognized)
+
+	found, unrecognized, err = DefaultSectorPathLayout.ScanSectorFiles(root)
+	require.NoError(t, err)
+	require.Equal(t, []abi.SectorID{sids[0]}, found[SectorPathTypeSealed])
+	require.Empty(t, found[SectorPathTypeCache])
+	require.Len(t, unrecognized, 2)
+}
@@ -0,0 +1,105 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// ScanSectorFiles walks the top level dirs of the sector files under the root, the entries laid out
+// as the layout are reported as the sectors of their types, and the other files as unrecognized,
+// in the form of the paths relative to the root.
+func (l *SectorPathLayout) ScanSectorFiles(root string) (map[pathType][]abi.SectorID, []string, error) {
+	patterns := make(map[pathType]*sectorPathPattern, len(SectorPathTypes))
+	tops := make([]string, 0, len(SectorPathTypes))
+	for _, typ := range SectorPathTypes {
+		pat, err := l.pattern(typ)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get the pattern of %s: %w", typ, err)
+		}
+
+		patterns[typ] = pat
+		tops = append(tops, pat.top)
+	}
+
+	found := map[pathType][]abi.SectorID{}
+	var unrecognized []string
+	for _, top := range walkRoots(tops) {
+		walkRoot := filepath.Join(root, filepath.FromSlash(top))
+		if _, err := os.Stat(walkRoot); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		err := filepath.WalkDir(walkRoot, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if p == walkRoot {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+
+			for _, typ := range SectorPathTypes {
+				if d.IsDir() != typ.IsDir() {
+					continue
+				}
+
+				sid, ok := patterns[typ].parse(filepath.ToSlash(rel))
+				if !ok || l.SectorPath(typ, sid) != rel {
+					continue
+				}
+
+				found[typ] = append(found[typ], sid)
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !d.IsDir() {
+				unrecognized = append(unrecognized, rel)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("walk %s: %w", walkRoot, err)
+		}
+	}
+
+	return found, unrecognized, nil
+}
+
+// walkRoots dedups the top level dirs, and drops the ones nested in the others.
+func walkRoots(tops []string) []string {
+	sorted := append([]string(nil), tops...)
+	sort.Strings(sorted)
+
+	var roots []string
+	for _, top := range sorted {
+		nested := false
+		for _, r := range roots {
+			if r == "" || top == r || strings.HasPrefix(top, r+"/") {
+				nested = true
+				break
+			}
+		}
+
+		if !nested {
+			roots = append(roots, top)
+		}
+	}
+
+	return roots
+}
@@ -48,6 +48,19 @@ const (
 	SectorPathTypeUpdateCache pathType = "update-cache"
 )
 
+// SectorPathTypes are all the types of the sector files.
+var SectorPathTypes = []pathType{
+	SectorPathTypeSealed,
+	SectorPathTypeCache,
+	SectorPathTypeUpdate,
+	SectorPathTypeUpdateCache,
+}
+
+// IsDir tells if the sector files of the type are dirs.
+func (typ pathType) IsDir() bool {
+	return typ == SectorPathTypeCache || typ == SectorPathTypeUpdateCache
+}
+
 const sectorIDFormat = "s-t0%d-%d"
 
 func SectorPath(typ pathType, sid abi.SectorID) string {
@@ -0,0 +1,94 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/miner"
+	"github.com/filecoin-project/venus/venus-shared/types"
+)
+
+// SectorInfoCache loads the on-chain sector infos in batches by miner, and caches the results for its lifetime.
+// It is meant to be used within a single call, so the results will not be refreshed.
+type SectorInfoCache struct {
+	api API
+	tsk types.TipSetKey
+
+	mu sync.Mutex
+	// nil value means the sector is not found on chain
+	infos map[abi.SectorID]*miner.SectorOnChainInfo
+}
+
+func NewSectorInfoCache(api API, tsk types.TipSetKey) *SectorInfoCache {
+	return &SectorInfoCache{
+		api:   api,
+		tsk:   tsk,
+		infos: map[abi.SectorID]*miner.SectorOnChainInfo{},
+	}
+}
+
+// Prefetch loads the infos of the given sectors which are not cached yet, with one chain call for each miner.
+func (c *SectorInfoCache) Prefetch(ctx context.Context, sids []abi.SectorID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	missing := map[abi.ActorID][]uint64{}
+	for _, sid := range sids {
+		if _, ok := c.infos[sid]; ok {
+			continue
+		}
+
+		missing[sid.Miner] = append(missing[sid.Miner], uint64(sid.Number))
+	}
+
+	for mid, nums := range missing {
+		if err := c.fetch(ctx, mid, nums); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get returns the on-chain info of the given sector, nil if not found.
+func (c *SectorInfoCache) Get(ctx context.Context, sid abi.SectorID) (*miner.SectorOnChainInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if info, ok := c.infos[sid]; ok {
+		return info, nil
+	}
+
+	if err := c.fetch(ctx, sid.Miner, []uint64{uint64(sid.Number)}); err != nil {
+		return nil, err
+	}
+
+	return c.infos[sid], nil
+}
+
+func (c *SectorInfoCache) fetch(ctx context.Context, mid abi.ActorID, nums []uint64) error {
+	maddr, err := address.NewIDAddress(uint64(mid))
+	if err != nil {
+		return fmt.Errorf("invalid miner actor id %d: %w", mid, err)
+	}
+
+	bits := bitfield.NewFromSet(nums)
+	sinfos, err := c.api.StateMinerSectors(ctx, maddr, &bits, c.tsk)
+	if err != nil {
+		return fmt.Errorf("get sector infos of %s: %w", maddr, err)
+	}
+
+	for _, num := range nums {
+		c.infos[abi.SectorID{Miner: mid, Number: abi.SectorNumber(num)}] = nil
+	}
+
+	for _, sinfo := range sinfos {
+		c.infos[abi.SectorID{Miner: mid, Number: sinfo.SectorNumber}] = sinfo
+	}
+
+	return nil
+}
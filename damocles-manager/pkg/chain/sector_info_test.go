@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/venus/venus-shared/actors/builtin/miner"
+	"github.com/filecoin-project/venus/venus-shared/api/chain/v1/mock"
+	"github.com/filecoin-project/venus/venus-shared/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSectorInfoCache(t *testing.T) {
+	ctx := context.Background()
+	mc := gomock.NewController(t)
+	capi := mock.NewMockFullNode(mc)
+
+	for _, mid := range []abi.ActorID{1000, 1001} {
+		maddr, err := address.NewIDAddress(uint64(mid))
+		require.NoError(t, err)
+
+		capi.EXPECT().
+			StateMinerSectors(gomock.Any(), maddr, gomock.Any(), types.EmptyTSK).
+			Return([]*miner.SectorOnChainInfo{{SectorNumber: 1, Expiration: 100}}, nil).
+			Times(1)
+	}
+
+	cache := NewSectorInfoCache(capi, types.EmptyTSK)
+	sids := []abi.SectorID{
+		{Miner: 1000, Number: 1},
+		{Miner: 1000, Number: 2},
+		{Miner: 1001, Number: 1},
+	}
+
+	require.NoError(t, cache.Prefetch(ctx, sids))
+	// all cached, no more chain calls
+	require.NoError(t, cache.Prefetch(ctx, sids))
+
+	info, err := cache.Get(ctx, abi.SectorID{Miner: 1000, Number: 1})
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	require.Equal(t, abi.ChainEpoch(100), info.Expiration)
+
+	info, err = cache.Get(ctx, abi.SectorID{Miner: 1000, Number: 2})
+	require.NoError(t, err)
+	require.Nil(t, info, "sector not on chain")
+
+	info, err = cache.Get(ctx, abi.SectorID{Miner: 1001, Number: 1})
+	require.NoError(t, err)
+	require.NotNil(t, info)
+}
@@ -36,6 +36,12 @@ var MessageState = struct {
 	mtypes.NonceConflictMsg,
 }
 
+// MessageFailed tells if the message in the state will never land on chain by itself.
+// The nonce of a NonceConflictMsg has been taken by another message, it is regarded as failed as well.
+func MessageFailed(state mtypes.MessageState) bool {
+	return state == mtypes.FailedMsg || state == mtypes.NonceConflictMsg
+}
+
 type API = mapi.IMessager
 
 func New(ctx context.Context, api, token string) (API, jsonrpc.ClientCloser, error) {
@@ -0,0 +1,27 @@
+package messager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mtypes "github.com/filecoin-project/venus/venus-shared/types/messager"
+)
+
+func TestMessageFailed(t *testing.T) {
+	testCases := []struct {
+		state  mtypes.MessageState
+		failed bool
+	}{
+		{MessageState.UnKnown, false},
+		{MessageState.UnFillMsg, false},
+		{MessageState.FillMsg, false},
+		{MessageState.OnChainMsg, false},
+		{MessageState.FailedMsg, true},
+		{MessageState.NonceConflictMsg, true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.failed, MessageFailed(tc.state), "state %s", MessageStateToString(tc.state))
+	}
+}
@@ -284,6 +284,10 @@ func (s *Store) Put(_ context.Context, p string, r io.Reader) (int64, error) {
 		return 0, err
 	}
 
+	if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+		return 0, fmt.Errorf("obj %s: create parent dir %w", p, err)
+	}
+
 	file, err := os.OpenFile(fpath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
 	if err != nil {
 		return 0, fmt.Errorf("obj %s: create %w", p, err)
@@ -62,8 +62,10 @@ type StoreInfo struct {
 type Manager interface {
 	GetInstance(ctx context.Context, name string) (Store, error)
 	ListInstances(ctx context.Context) ([]StoreInfo, error)
+	GetInstanceInfo(ctx context.Context, name string) (StoreInfo, error)
 	ReserveSpace(ctx context.Context, by abi.SectorID, size uint64, candidates []string) (*Config, error)
 	ReleaseReserved(ctx context.Context, by abi.SectorID) (bool, error)
+	ReserveSpaceOn(ctx context.Context, sid abi.SectorID, size uint64, instance string, by string) (StoreReserved, error)
 }
 
 type StoreSelectPolicy struct {
@@ -164,6 +166,38 @@ func (m *StoreManager) ListInstances(ctx context.Context) ([]StoreInfo, error) {
 	return infos, nil
 }
 
+// GetInstanceInfo queries the latest instance info of the given store, along with the reserved stat
+func (m *StoreManager) GetInstanceInfo(ctx context.Context, name string) (StoreInfo, error) {
+	store, err := m.GetInstance(ctx, name)
+	if err != nil {
+		return StoreInfo{}, err
+	}
+
+	insInfo, err := store.InstanceInfo(ctx)
+	if err != nil {
+		return StoreInfo{}, fmt.Errorf("get instance info for %s: %w", name, err)
+	}
+
+	info := StoreInfo{
+		Instance: insInfo,
+	}
+
+	err = m.modifyReserved(ctx, func(summary *StoreReserveSummary) (bool, error) {
+		reserved, ok := summary.Stats[name]
+		if !ok {
+			reserved = emptyStoreReserveStat()
+		}
+
+		info.Reserved = *reserved
+		return false, nil
+	})
+	if err != nil {
+		return StoreInfo{}, fmt.Errorf("get reserved info: %w", err)
+	}
+
+	return info, nil
+}
+
 type storeCandidate struct {
 	Store
 	InstanceInfo
@@ -224,6 +258,11 @@ func (m *StoreManager) ReserveSpace(
 					continue
 				}
 
+				// the workers write the sector files in the default layout only
+				if !hasDefaultSectorPathLayout(&info.Config) {
+					continue
+				}
+
 				weight := info.Config.Weight
 				if weight == 0 {
 					weight = 1
@@ -286,6 +325,93 @@ func (m *StoreManager) ReserveSpace(
 	return selected, nil
 }
 
+// ReserveSpaceOn reserves the space for the sector on the given instance.
+// It fails if the instance is not available for the sector, or the free space is not enough
+// after the existing reservations.
+// The reservation is still recorded under the sector, so that it could be released by ReleaseReserved,
+// by is the tag of the reservation, defaults to the sector id if empty.
+func (m *StoreManager) ReserveSpaceOn(
+	ctx context.Context,
+	sid abi.SectorID,
+	size uint64,
+	instance string,
+	by string,
+) (StoreReserved, error) {
+	st, err := m.GetInstance(ctx, instance)
+	if err != nil {
+		return StoreReserved{}, err
+	}
+
+	if policy, ok := m.policy[instance]; ok && !policy.Allowed(sid.Miner) {
+		return StoreReserved{}, fmt.Errorf("instance %s is not allowed for miner %d", instance, sid.Miner)
+	}
+
+	info, err := st.InstanceInfo(ctx)
+	if err != nil {
+		return StoreReserved{}, fmt.Errorf("get instance info for %s: %w", instance, err)
+	}
+
+	if info.Config.ReadOnly {
+		return StoreReserved{}, fmt.Errorf("instance %s is readonly", instance)
+	}
+
+	if !hasDefaultSectorPathLayout(&info.Config) {
+		return StoreReserved{}, fmt.Errorf("instance %s has custom sector path templates", instance)
+	}
+
+	key := util.FormatSectorID(sid)
+	if by == "" {
+		by = key
+	}
+
+	var reserved StoreReserved
+	err = m.modifyReserved(ctx, func(summary *StoreReserveSummary) (bool, error) {
+		for name, stat := range summary.Stats {
+			if prev, ok := stat.Reserved[key]; ok {
+				return false, fmt.Errorf("already reserved %d bytes on %s", prev.Size, name)
+			}
+		}
+
+		resStat, ok := summary.Stats[instance]
+		if !ok {
+			resStat = emptyStoreReserveStat()
+			summary.Stats[instance] = resStat
+		}
+
+		if info.Free < resStat.ReservedSize+size {
+			return false, fmt.Errorf(
+				"not enough space on %s, free %d, reserved %d, required %d",
+				instance,
+				info.Free,
+				resStat.ReservedSize,
+				size,
+			)
+		}
+
+		reserved = StoreReserved{
+			By:   by,
+			Size: size,
+			At:   time.Now().Unix(),
+		}
+		resStat.Reserved[key] = reserved
+		resStat.ReservedSize += size
+
+		return true, nil
+	})
+	if err != nil {
+		return StoreReserved{}, fmt.Errorf("reserve space: %w", err)
+	}
+
+	mgrLog.Infow("space reserved", "sector", key, "by", by, "size", size, "ins", instance)
+	return reserved, nil
+}
+
+// hasDefaultSectorPathLayout tells if the sector files in the store are laid out as util.SectorPath.
+func hasDefaultSectorPathLayout(cfg *Config) bool {
+	layout, err := util.ParseSectorPathLayout(cfg.Meta)
+	return err == nil && layout.IsDefault()
+}
+
 func (m *StoreManager) ReleaseReserved(ctx context.Context, sid abi.SectorID) (bool, error) {
 	by := util.FormatSectorID(sid)
 	released := false
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs-force-community/damocles/damocles-manager/modules/util"
 	"github.com/ipfs-force-community/damocles/damocles-manager/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -355,3 +356,83 @@ func TestStoreSelectPolicy(t *testing.T) {
 		)
 	}
 }
+
+func TestStoreManagerGetInstanceInfo(t *testing.T) {
+	ctx := context.Background()
+	kvs := testutil.BadgerKVStore(t, "test")
+
+	storeName := "store-1M"
+	store, err := NewMockStore(Config{
+		Name: storeName,
+	}, 1<<20)
+	require.NoError(t, err, "construct store-1M")
+
+	mgr, err := NewStoreManager([]Store{store}, nil, kvs)
+	require.NoError(t, err, "construct store mgr")
+
+	_, err = mgr.GetInstanceInfo(ctx, "not-exist")
+	require.ErrorIs(t, err, ErrObjectStoreInstanceNotFound)
+
+	_, err = mgr.ReserveSpace(ctx, abi.SectorID{Miner: 1, Number: 1}, 1<<10, nil)
+	require.NoError(t, err, "reserve space")
+
+	info, err := mgr.GetInstanceInfo(ctx, storeName)
+	require.NoError(t, err, "get instance info")
+	require.Equal(t, storeName, info.Instance.Config.Name)
+	require.Equal(t, uint64(1<<20), info.Instance.Total)
+	require.Equal(t, uint64(1<<10), info.Reserved.ReservedSize)
+	require.Len(t, info.Reserved.Reserved, 1)
+}
+
+func TestStoreManagerReserveSpaceOn(t *testing.T) {
+	ctx := context.Background()
+	kvs := testutil.BadgerKVStore(t, "test")
+
+	storeName := "store-1M"
+	store, err := NewMockStore(Config{
+		Name: storeName,
+	}, 1<<20)
+	require.NoError(t, err, "construct store-1M")
+
+	storeNameReadOnly := "store-readonly"
+	storeReadOnly, err := NewMockStore(Config{
+		Name:     storeNameReadOnly,
+		ReadOnly: true,
+	}, 1<<20)
+	require.NoError(t, err, "construct store-readonly")
+
+	mgr, err := NewStoreManager([]Store{store, storeReadOnly}, nil, kvs)
+	require.NoError(t, err, "construct store mgr")
+
+	sid1 := abi.SectorID{Miner: 1, Number: 1}
+	sid2 := abi.SectorID{Miner: 1, Number: 2}
+
+	_, err = mgr.ReserveSpaceOn(ctx, sid1, 1<<10, "not-exist", "")
+	require.ErrorIs(t, err, ErrObjectStoreInstanceNotFound)
+
+	_, err = mgr.ReserveSpaceOn(ctx, sid1, 1<<10, storeNameReadOnly, "")
+	require.Error(t, err, "reserve on readonly store")
+
+	reserved, err := mgr.ReserveSpaceOn(ctx, sid1, 1<<19, storeName, "scheduler")
+	require.NoError(t, err, "reserve space")
+	require.Equal(t, uint64(1<<19), reserved.Size)
+	require.Equal(t, "scheduler", reserved.By)
+
+	_, err = mgr.ReserveSpaceOn(ctx, sid1, 1<<10, storeName, "")
+	require.Error(t, err, "reserve twice")
+
+	_, err = mgr.ReserveSpaceOn(ctx, sid2, 1<<19+1, storeName, "")
+	require.Error(t, err, "reserve more than the free space")
+
+	info, err := mgr.GetInstanceInfo(ctx, storeName)
+	require.NoError(t, err, "get instance info")
+	require.Equal(t, uint64(1<<19), info.Reserved.ReservedSize)
+
+	released, err := mgr.ReleaseReserved(ctx, sid1)
+	require.NoError(t, err, "release reserved")
+	require.True(t, released)
+
+	reserved, err = mgr.ReserveSpaceOn(ctx, sid2, 1<<19+1, storeName, "")
+	require.NoError(t, err, "reserve after release")
+	require.Equal(t, util.FormatSectorID(sid2), reserved.By)
+}
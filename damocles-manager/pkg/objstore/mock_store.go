@@ -71,12 +71,8 @@ func (ms *MockStore) Get(_ context.Context, p string) (io.ReadCloser, error) {
 		return nil, ErrObjectNotFound
 	}
 
-	r := new(bytes.Buffer)
-	_, err := io.Copy(r, buf)
-	if err != nil {
-		return nil, err
-	}
-
+	// copy the content, so that the object will not be drained by the reader
+	r := bytes.NewBuffer(append([]byte(nil), buf.Bytes()...))
 	return io.NopCloser(r), nil
 }
 
@@ -0,0 +1,67 @@
+package piecestore
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// needsAuth tells if the request should carry the token, the writes always do if the token is configured,
+// while the reads only do if AuthReads is enabled.
+func (p *Proxy) needsAuth(req *http.Request) bool {
+	if p.cfg.AuthToken == "" {
+		return false
+	}
+
+	return req.Method != http.MethodGet || p.cfg.AuthReads
+}
+
+// authorized checks the bearer token in the `Authorization` header against the configured one.
+func (p *Proxy) authorized(req *http.Request) bool {
+	scheme, token, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(p.cfg.AuthToken)) == 1
+}
+
+// Serve runs a standalone http server of the proxy on the configured listen address,
+// tls is enabled if the cert & key files are set. It blocks until the context is done or the server fails.
+func (p *Proxy) Serve(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:    p.cfg.Listen,
+		Handler: p,
+		BaseContext: func(net.Listener) context.Context {
+			return ctx
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if p.cfg.TLSCertFile != "" {
+			errCh <- srv.ListenAndServeTLS(p.cfg.TLSCertFile, p.cfg.TLSKeyFile)
+			return
+		}
+
+		errCh <- srv.ListenAndServe()
+	}()
+
+	log.Infow("piecestore proxy listening", "addr", p.cfg.Listen, "tls", p.cfg.TLSCertFile != "")
+	select {
+	case <-ctx.Done():
+		return srv.Shutdown(context.Background())
+
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,109 @@
+package piecestore
+
+import (
+	"net/http"
+	"time"
+)
+
+// ProxyConfig controls the behaviours of the piece store proxy.
+type ProxyConfig struct {
+	// PublicBaseURL, if set, replaces the scheme & host of the redirect targets,
+	// e.g. `https://pieces.example.com`.
+	PublicBaseURL string
+
+	// TrustForwardedHeaders makes the proxy rewrite the redirect targets based on the
+	// `X-Forwarded-Proto` & `X-Forwarded-Host` headers when PublicBaseURL is not set.
+	// Only enable this when the proxy sits behind a trusted reverse proxy.
+	TrustForwardedHeaders bool
+
+	// Replicas is the number of writable stores each piece will be written into, at least 1.
+	// The PUT request succeeds as long as any of the writes succeeds.
+	Replicas int
+
+	// ReplicaConcurrency is the max number of the stores written at the same time, to avoid saturating the disk io.
+	// The uploaded data is teed into that many stores, the rest are copied from a store holding the piece afterwards.
+	// 1 will be used if not set.
+	ReplicaConcurrency int
+
+	// RequiredReplicas is the number of the successful writes the PUT request waits for, capped to Replicas,
+	// the other writes keep running in the background. 1 will be used if not set.
+	RequiredReplicas int
+
+	// ReadTimeout is the timeout of opening the piece data in each local store for the GET requests,
+	// a store that exceeds it will be skipped. It applies to each read of the opened piece data as well,
+	// a stalled read fails the request. 0 means no timeout.
+	ReadTimeout time.Duration
+
+	// AccessLog enables the access log of the GET requests, emitted after each request completes,
+	// with the client address, the source which served the piece, and the bytes transferred.
+	AccessLog bool
+
+	// PieceIndex enables the in-memory index of the pieces in the local stores, built by listing the stores
+	// at startup, so that the GET requests don't have to probe every store, and the misses are redirected
+	// without touching the disks.
+	PieceIndex bool
+
+	// PieceIndexRefreshInterval is the interval of rebuilding the piece index, to catch up with the changes
+	// made out of the proxy. 0 means never.
+	PieceIndexRefreshInterval time.Duration
+
+	// RedirectStatus is the status code of the redirect responses for the pieces not found locally,
+	// one of 301, 302, 303, 307 and 308, 302 will be used if not set.
+	RedirectStatus int
+
+	// UploadDir is the directory holding the partial data of the resumable uploads,
+	// the default temp dir will be used if empty.
+	UploadDir string
+
+	// SelfURLs are the base urls through which the proxy itself is reachable, e.g. `http://10.0.0.1:9999`,
+	// the redirects targeting any of them, or the Listen address, are refused with 502 to break the loops
+	// formed by misconfigured proxies redirecting to each other.
+	SelfURLs []string
+
+	// DetectSelfByRequestHost makes the redirects targeting the host of the request regarded as loops as well.
+	// Don't enable it if the market service is reachable through the same host, e.g. behind a shared gateway.
+	DetectSelfByRequestHost bool
+
+	// ShardChars, if positive, is the number of the trailing chars of the piece cid used as the name of the sub dir
+	// the piece is stored in, to keep the number of files in each dir manageable. The pieces stored in the root dir
+	// before enabling it are still readable.
+	ShardChars int
+
+	// TransferRateLimit is the bandwidth limit of each download or upload, in bytes per second, 0 means no limit.
+	TransferRateLimit int64
+
+	// AggregateRateLimit is the bandwidth limit of all the downloads and uploads in total, in bytes per second,
+	// 0 means no limit.
+	AggregateRateLimit int64
+
+	// AuthToken, if set, is the bearer token required by the upload requests, via the `Authorization` header.
+	AuthToken string
+
+	// AuthReads makes the download requests require the AuthToken as well.
+	AuthReads bool
+
+	// Listen, if set, is the address of a standalone http server serving the proxy only,
+	// in addition to the one mounted on the api server of damocles-manager.
+	Listen string
+
+	// TLSCertFile & TLSKeyFile enable tls on the standalone http server, both or neither should be set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// UploadSessionTimeout is the duration after which an inactive resumable upload session will be
+	// cleaned up along with its partial data, 1h will be used if not set.
+	UploadSessionTimeout time.Duration
+
+	// MaxUploadSize is the max total size declared by a resumable upload, in bytes,
+	// 64GiB will be used if not set.
+	MaxUploadSize int64
+}
+
+func DefaultProxyConfig() ProxyConfig {
+	return ProxyConfig{
+		Replicas:           1,
+		ReplicaConcurrency: 2,
+		RequiredReplicas:   1,
+		RedirectStatus:     http.StatusFound,
+	}
+}
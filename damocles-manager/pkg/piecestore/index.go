@@ -0,0 +1,160 @@
+package piecestore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
+)
+
+// pieceIndex maps the object keys of the pieces to the names of the local stores holding them,
+// so that the lookups don't have to touch every store. It is built by listing the root dirs of the stores,
+// the index is only authoritative for the misses if all the stores have been listed successfully.
+// A nil *pieceIndex is valid, and indexes nothing.
+type pieceIndex struct {
+	mu       sync.RWMutex
+	entries  map[string]string
+	complete bool
+	// sharded makes the objects in the sub dirs of the stores indexed as well
+	sharded bool
+}
+
+func newPieceIndex() *pieceIndex {
+	return &pieceIndex{
+		entries: map[string]string{},
+	}
+}
+
+// rebuild lists all the stores and replaces the entries, the stores listed earlier take precedence.
+// The entries are kept as they are if the ctx is done before all the stores are listed.
+func (pi *pieceIndex) rebuild(ctx context.Context, stores []objstore.Store) {
+	if pi == nil {
+		return
+	}
+
+	start := time.Now()
+	entries := map[string]string{}
+	complete := true
+	for _, store := range stores {
+		if ctx.Err() != nil {
+			return
+		}
+
+		instance := store.Instance(ctx)
+		keys, err := listStoreKeys(ctx, store, pi.sharded)
+		if err != nil {
+			log.Warnw("list piece store for index", "store", instance, "err", err)
+			complete = false
+			continue
+		}
+
+		for _, key := range keys {
+			if _, ok := entries[key]; !ok {
+				entries[key] = instance
+			}
+		}
+	}
+
+	pi.mu.Lock()
+	pi.entries = entries
+	pi.complete = complete
+	pi.mu.Unlock()
+
+	log.Infow("piece index rebuilt", "pieces", len(entries), "complete", complete, "elapsed", time.Since(start))
+}
+
+// lookup returns the store instance holding the key, authoritative tells if a miss could be trusted.
+func (pi *pieceIndex) lookup(key string) (instance string, found bool, authoritative bool) {
+	if pi == nil {
+		return "", false, false
+	}
+
+	pi.mu.RLock()
+	defer pi.mu.RUnlock()
+	instance, found = pi.entries[key]
+	return instance, found, pi.complete
+}
+
+func (pi *pieceIndex) add(key, instance string) {
+	if pi == nil {
+		return
+	}
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if _, ok := pi.entries[key]; !ok {
+		pi.entries[key] = instance
+	}
+}
+
+// remove drops the entry if it still points to the given instance, used when the object is found missing.
+func (pi *pieceIndex) remove(key, instance string) {
+	if pi == nil {
+		return
+	}
+
+	pi.mu.Lock()
+	defer pi.mu.Unlock()
+	if pi.entries[key] == instance {
+		delete(pi.entries, key)
+	}
+}
+
+// refresh rebuilds the index periodically, to catch up with the out-of-band changes of the stores.
+func (pi *pieceIndex) refresh(ctx context.Context, stores []objstore.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pi.rebuild(ctx, stores)
+		}
+	}
+}
+
+// listStoreKeys lists the objects in the root dir of the store, and in the sub dirs if sharded,
+// only the local path based stores are supported.
+func listStoreKeys(ctx context.Context, store objstore.Store, sharded bool) ([]string, error) {
+	root := store.FullPath(ctx, "")
+	if root == "" {
+		return nil, fmt.Errorf("store does not expose a local path")
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("read dir %s: %w", root, err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type().IsRegular() || entry.Type()&os.ModeSymlink != 0 {
+			keys = append(keys, entry.Name())
+			continue
+		}
+
+		if !sharded || !entry.IsDir() {
+			continue
+		}
+
+		subs, err := os.ReadDir(filepath.Join(root, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %w", entry.Name(), err)
+		}
+
+		for _, sub := range subs {
+			if sub.Type().IsRegular() || sub.Type()&os.ModeSymlink != 0 {
+				keys = append(keys, path.Join(entry.Name(), sub.Name()))
+			}
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,77 @@
+package piecestore
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
+)
+
+// LocalPiece is a piece found in the local stores, Key is the object key in the store.
+type LocalPiece struct {
+	PieceCid cid.Cid
+	Size     int64
+	Store    string
+	Key      string
+}
+
+// ListLocalPieces lists the pieces in the local stores, ordered by the stores and then the keys,
+// the objects not named after piece cids are skipped. limit <= 0 means no limit.
+// Only the pieces in the requested page are stat-ed for the sizes.
+func (p *Proxy) ListLocalPieces(ctx context.Context, offset, limit int) ([]LocalPiece, error) {
+	type found struct {
+		store objstore.Store
+		piece LocalPiece
+	}
+
+	var all []found
+	for _, store := range p.locals {
+		instance := store.Instance(ctx)
+		keys, err := listStoreKeys(ctx, store, p.cfg.ShardChars > 0)
+		if err != nil {
+			return nil, fmt.Errorf("list store %s: %w", instance, err)
+		}
+
+		for _, key := range keys {
+			cidStr, _ := parsePieceName(path.Base(key))
+			c, err := cid.Decode(cidStr)
+			if err != nil {
+				continue
+			}
+
+			all = append(all, found{
+				store: store,
+				piece: LocalPiece{
+					PieceCid: c,
+					Store:    instance,
+					Key:      key,
+				},
+			})
+		}
+	}
+
+	if offset < 0 || offset >= len(all) {
+		return []LocalPiece{}, nil
+	}
+
+	all = all[offset:]
+	if limit > 0 && limit < len(all) {
+		all = all[:limit]
+	}
+
+	pieces := make([]LocalPiece, 0, len(all))
+	for _, f := range all {
+		stat, err := f.store.Stat(ctx, f.piece.Key)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s in store %s: %w", f.piece.Key, f.piece.Store, err)
+		}
+
+		f.piece.Size = stat.Size
+		pieces = append(pieces, f.piece)
+	}
+
+	return pieces, nil
+}
@@ -1,11 +1,22 @@
 package piecestore
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ipfs/go-cid"
 
@@ -14,7 +25,10 @@ import (
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
 )
 
-var log = logging.New("piecestore")
+var (
+	log       = logging.New("piecestore")
+	accessLog = logging.New("piecestore-access")
+)
 
 const carSuffix = ".car"
 
@@ -25,26 +39,125 @@ func parsePieceName(s string) (cid string, cidWithDotCar string) {
 	return s, s + carSuffix
 }
 
+// pieceKeys returns the object keys to look up for the given piece,
+// the ones based on the canonical cid string come first.
+func pieceKeys(c cid.Cid, cidStr string) []string {
+	canonical := c.String()
+	if canonical == cidStr {
+		return []string{canonical, canonical + carSuffix}
+	}
+
+	return []string{canonical, canonical + carSuffix, cidStr, cidStr + carSuffix}
+}
+
 type PieceStore interface {
 	Get(ctx context.Context, pieceCid cid.Cid) (io.ReadCloser, error)
 	Put(ctx context.Context, pieceCid cid.Cid, data io.Reader) (int64, error)
+	ListLocalPieces(ctx context.Context, offset, limit int) ([]LocalPiece, error)
+	SetBandwidthLimit(perTransfer, aggregate int64)
 }
 
 var _ PieceStore = (*Proxy)(nil)
 
-func NewProxy(locals []objstore.Store, mapi market.API) *Proxy {
-	return &Proxy{
-		locals: locals,
-		market: mapi,
+// NewProxy returns a proxy serving the pieces from the local stores, and redirecting the clients to
+// the market service for the others. The background tasks of the proxy are stopped by Close.
+func NewProxy(locals []objstore.Store, mapi market.API, cfg ProxyConfig) *Proxy {
+	local := NewLocalSource(locals, cfg.ReadTimeout)
+	local.shardChars = cfg.ShardChars
+	if cfg.PieceIndex {
+		local.index = newPieceIndex()
+		local.index.sharded = cfg.ShardChars > 0
+	}
+
+	sources := []PieceSource{local}
+	if mapi != nil {
+		sources = append(sources, NewMarketSource(mapi))
+	}
+
+	p := NewProxyWithSources(locals, sources, cfg)
+	p.index = local.index
+	if p.index != nil {
+		p.background(func(ctx context.Context) {
+			p.index.rebuild(ctx, locals)
+			if cfg.PieceIndexRefreshInterval > 0 {
+				p.index.refresh(ctx, locals, cfg.PieceIndexRefreshInterval)
+			}
+		})
+	}
+
+	return p
+}
+
+// NewProxyWithSources returns a proxy serving the pieces from the given sources in order,
+// the uploaded pieces are written into the local stores.
+func NewProxyWithSources(locals []objstore.Store, sources []PieceSource, cfg ProxyConfig) *Proxy {
+	switch cfg.RedirectStatus {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		if cfg.RedirectStatus != 0 {
+			log.Warnw("unsupported redirect status, use 302 instead", "status", cfg.RedirectStatus)
+		}
+		cfg.RedirectStatus = http.StatusFound
 	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Proxy{
+		cfg:     cfg,
+		locals:  locals,
+		sources: sources,
+		uploads: newUploadSessions(cfg.UploadDir, cfg.UploadSessionTimeout, cfg.MaxUploadSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	p.aggregate = newBandwidthLimiter(&p.aggregateRate)
+	p.SetBandwidthLimit(cfg.TransferRateLimit, cfg.AggregateRateLimit)
+	p.background(p.uploads.sweepLoop)
+	return p
 }
 
 type Proxy struct {
-	locals []objstore.Store
-	market market.API
+	cfg     ProxyConfig
+	locals  []objstore.Store
+	sources []PieceSource
+	uploads *uploadSessions
+	// index is shared with the local source, so that the uploaded pieces are indexed
+	index *pieceIndex
+
+	transferRate  atomic.Int64
+	aggregateRate atomic.Int64
+	aggregate     *bandwidthLimiter
+
+	// ctx is cancelled by Close to stop the background tasks tracked by bgWg
+	ctx    context.Context
+	cancel context.CancelFunc
+	bgWg   sync.WaitGroup
+}
+
+// background runs the task in a goroutine, with the context cancelled on Close.
+func (p *Proxy) background(task func(ctx context.Context)) {
+	p.bgWg.Add(1)
+	go func() {
+		defer p.bgWg.Done()
+		task(p.ctx)
+	}()
+}
+
+// Close stops the background tasks of the proxy, and waits for them to exit.
+// The partial data of the resumable uploads are removed as well.
+func (p *Proxy) Close() {
+	p.cancel()
+	p.bgWg.Wait()
 }
 
 func (p *Proxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if p.needsAuth(req) && !p.authorized(req) {
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="piecestore"`)
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
 	switch req.Method {
 	case http.MethodGet:
 		p.handleGet(rw, req)
@@ -56,36 +169,410 @@ func (p *Proxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 }
 
 func (p *Proxy) handleGet(rw http.ResponseWriter, req *http.Request) {
+	if strings.Trim(req.URL.Path, "/ ") == "" {
+		p.handleList(rw, req)
+		return
+	}
+
+	if !p.cfg.AccessLog {
+		p.serveGet(rw, req)
+		return
+	}
+
+	start := time.Now()
+	arw := &accessRecorder{ResponseWriter: rw, status: http.StatusOK}
+	servedBy := p.serveGet(arw, req)
+	accessLog.Infow(
+		"piece retrieval",
+		"path", req.URL.Path,
+		"client", p.clientAddr(req),
+		"served-by", servedBy,
+		"status", arw.status,
+		"bytes", arw.written,
+		"elapsed", time.Since(start),
+	)
+}
+
+// handleList responds the pieces in the local stores in json, paginated by the `offset` & `limit` queries.
+func (p *Proxy) handleList(rw http.ResponseWriter, req *http.Request) {
+	var page [2]int
+	for i, name := range []string{"offset", "limit"} {
+		v := req.URL.Query().Get(name)
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(rw, fmt.Sprintf("parse %s: %s", name, err), http.StatusBadRequest)
+			return
+		}
+
+		page[i] = n
+	}
+
+	pieces, err := p.ListLocalPieces(req.Context(), page[0], page[1])
+	if err != nil {
+		log.Errorw("list local pieces", "err", err)
+		http.Error(rw, fmt.Sprintf("list local pieces: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(pieces); err != nil {
+		log.Warnw("write local pieces", "err", err)
+	}
+}
+
+// serveGet serves the piece data, and returns the name of the source which served the request,
+// prefixed with `redirect:` if the client was redirected, empty if no source served it.
+func (p *Proxy) serveGet(rw http.ResponseWriter, req *http.Request) string {
 	path := strings.Trim(req.URL.Path, "/ ")
-	cidStr, cidWithDotCar := parsePieceName(path)
+	cidStr, _ := parsePieceName(path)
 	c, err := cid.Decode(cidStr)
 	if err != nil {
 		http.Error(rw, fmt.Sprintf("cast %s to cid: %s", cidStr, err), http.StatusBadRequest)
+		return ""
+	}
+
+	ctx := withRequestedName(req.Context(), cidStr)
+	for _, src := range p.sources {
+		if redirector, ok := src.(PieceRedirector); ok {
+			p.redirect(rw, req, c, redirector)
+			return "redirect:" + src.Name()
+		}
+
+		r, err := src.Get(ctx, c)
+		if err != nil {
+			if !errors.Is(err, ErrPieceNotFound) {
+				log.Warnw("get piece data", "piece", cidStr, "source", src.Name(), "err", err)
+			}
+			continue
+		}
+
+		err = writePieceData(rw, req, p.throttle(ctx, r))
+		if err != nil {
+			log.Warnw("transfer piece data", "piece", cidStr, "source", src.Name(), "err", err)
+		}
+		r.Close()
+		return src.Name()
+	}
+
+	http.Error(rw, fmt.Sprintf("piece %s not found", cidStr), http.StatusNotFound)
+	return ""
+}
+
+// clientAddr returns the address of the client, the one from `X-Forwarded-For` is preferred
+// if the forwarded headers are trusted.
+func (p *Proxy) clientAddr(req *http.Request) string {
+	if p.cfg.TrustForwardedHeaders {
+		if addr := firstHeaderValue(req.Header.Get("X-Forwarded-For")); addr != "" {
+			return addr
+		}
+	}
+
+	return req.RemoteAddr
+}
+
+// accessRecorder records the status code & the size of the response body for the access log.
+type accessRecorder struct {
+	http.ResponseWriter
+	status  int
+	written int64
+}
+
+func (a *accessRecorder) WriteHeader(status int) {
+	a.status = status
+	a.ResponseWriter.WriteHeader(status)
+}
+
+func (a *accessRecorder) Write(b []byte) (int, error) {
+	n, err := a.ResponseWriter.Write(b)
+	a.written += int64(n)
+	return n, err
+}
+
+func (p *Proxy) redirect(rw http.ResponseWriter, req *http.Request, c cid.Cid, redirector PieceRedirector) {
+	resource, err := redirector.PieceURL(req.Context(), c)
+	if err != nil {
+		log.Errorw("get piece url", "piece", c, "err", err)
+		http.Error(rw, fmt.Sprintf("get piece url: %s", err), http.StatusInternalServerError)
 		return
 	}
 
-	for _, store := range p.locals {
-		for _, p := range []string{cidStr, cidWithDotCar} {
-			if r, err := store.Get(req.Context(), p); err == nil {
-				_, err := io.Copy(rw, r)
+	// check the resource url given by the market, the rewritten target may share the public host with the proxy
+	if p.isSelfTarget(req, resource) {
+		log.Errorw("redirect target points to the proxy itself", "piece", c, "resource", resource)
+		http.Error(rw, fmt.Sprintf("redirect loop detected for piece %s", c), http.StatusBadGateway)
+		return
+	}
+
+	target, err := p.redirectTarget(req, resource)
+	if err != nil {
+		log.Errorw("construct redirect target", "piece", c, "err", err)
+		http.Error(rw, fmt.Sprintf("construct redirect target: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(rw, req, target, p.cfg.RedirectStatus)
+}
+
+// isSelfTarget tells if the redirect target points back to the proxy, via any of the configured self urls,
+// the listen address of the standalone server, or the host of the request if DetectSelfByRequestHost is enabled.
+func (p *Proxy) isSelfTarget(req *http.Request, target string) bool {
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if p.cfg.DetectSelfByRequestHost && strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+
+	// the listen address without a host, e.g. `:9999`, matches nothing
+	host, _, err := net.SplitHostPort(p.cfg.Listen)
+	if err == nil && host != "" && strings.EqualFold(u.Host, p.cfg.Listen) {
+		return true
+	}
+
+	for _, self := range p.cfg.SelfURLs {
+		su, err := url.Parse(self)
+		if err != nil {
+			continue
+		}
+
+		if strings.EqualFold(u.Host, su.Host) && (su.Scheme == "" || strings.EqualFold(u.Scheme, su.Scheme)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	// gzipMinSize is the min size of the piece data worth compressing
+	gzipMinSize = 1 << 10
+	// gzipSampleSize is the size of the leading piece data used to estimate the compressibility
+	gzipSampleSize = 64 << 10
+	// gzipMinSaving is the min ratio of the bytes saved by compressing the sample
+	gzipMinSaving = 0.1
+)
+
+// writePieceData streams the piece data into the response, the data will be gzip-compressed if the client asks
+// for it, unless the data is too small, or hardly compressible like the already compressed or sealed data.
+func writePieceData(rw http.ResponseWriter, req *http.Request, r io.Reader) error {
+	rw.Header().Add("Vary", "Accept-Encoding")
+	if !acceptsGzip(req.Header.Values("Accept-Encoding")) {
+		_, err := io.Copy(rw, r)
+		return err
+	}
+
+	sample := make([]byte, gzipSampleSize)
+	n, err := io.ReadFull(r, sample)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	sample = sample[:n]
+	r = io.MultiReader(bytes.NewReader(sample), r)
+	if n < gzipMinSize || !compressible(sample) {
+		_, err := io.Copy(rw, r)
+		return err
+	}
+
+	rw.Header().Set("Content-Encoding", "gzip")
+	rw.Header().Del("Content-Length")
+
+	gw := gzip.NewWriter(rw)
+	if _, err := io.Copy(gw, r); err != nil {
+		gw.Close()
+		return err
+	}
+
+	return gw.Close()
+}
+
+// compressible tells if compressing the sample saves at least gzipMinSaving of the bytes.
+func compressible(sample []byte) bool {
+	cw := &countingWriter{}
+	fw, err := flate.NewWriter(cw, flate.BestSpeed)
+	if err != nil {
+		return false
+	}
+
+	if _, err := fw.Write(sample); err != nil {
+		return false
+	}
+
+	if err := fw.Close(); err != nil {
+		return false
+	}
+
+	return float64(cw.n) <= float64(len(sample))*(1-gzipMinSaving)
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	c.n += int64(len(b))
+	return len(b), nil
+}
+
+// acceptsGzip checks if gzip is one of the acceptable content codings with a non-zero qvalue.
+func acceptsGzip(values []string) bool {
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			coding, params, _ := strings.Cut(item, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding != "gzip" && coding != "x-gzip" {
+				continue
+			}
+
+			q := 1.0
+			for _, param := range strings.Split(params, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+					continue
+				}
+
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
 				if err != nil {
-					log.Warnw("transfer piece data for %s: %s", p, err)
+					q = 0
+				} else {
+					q = parsed
 				}
-				r.Close()
-				return
+			}
+
+			if q > 0 {
+				return true
 			}
 		}
 	}
 
-	http.Redirect(rw, req, p.market.PieceResourceURL(c), http.StatusFound)
+	return false
+}
+
+// redirectTarget rewrites the scheme & host of the given resource url,
+// so that the clients outside could reach it when the proxy is not internet-facing.
+func (p *Proxy) redirectTarget(req *http.Request, resource string) (string, error) {
+	var scheme, host string
+	switch {
+	case p.cfg.PublicBaseURL != "":
+		base, err := url.Parse(p.cfg.PublicBaseURL)
+		if err != nil {
+			return "", fmt.Errorf("parse public base url %q: %w", p.cfg.PublicBaseURL, err)
+		}
+
+		scheme, host = base.Scheme, base.Host
+
+	case p.cfg.TrustForwardedHeaders:
+		scheme = firstHeaderValue(req.Header.Get("X-Forwarded-Proto"))
+		host = firstHeaderValue(req.Header.Get("X-Forwarded-Host"))
+
+	default:
+		return resource, nil
+	}
+
+	if host == "" {
+		return resource, nil
+	}
+
+	u, err := url.Parse(resource)
+	if err != nil {
+		return "", fmt.Errorf("parse resource url %q: %w", resource, err)
+	}
+
+	if scheme != "" {
+		u.Scheme = scheme
+	}
+	u.Host = host
+
+	return u.String(), nil
+}
+
+func firstHeaderValue(v string) string {
+	if idx := strings.IndexByte(v, ','); idx >= 0 {
+		v = v[:idx]
+	}
+
+	return strings.TrimSpace(v)
 }
 
 func (p *Proxy) handlePut(rw http.ResponseWriter, req *http.Request) {
 	path := strings.Trim(req.URL.Path, "/ ")
-	dataSize := req.ContentLength
+	cidStr, _ := parsePieceName(path)
+	c, err := cid.Decode(cidStr)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("cast %s to cid: %s", cidStr, err), http.StatusBadRequest)
+		return
+	}
+
+	// use the canonical cid string as the key, keep the suffix if provided
+	key := c.String()
+	if strings.HasSuffix(path, carSuffix) {
+		key += carSuffix
+	}
+
+	if req.Header.Get("Content-Range") != "" {
+		p.handleChunkedPut(rw, req, key)
+		return
+	}
+
+	p.storePiece(rw, req, key, p.throttle(req.Context(), req.Body), req.ContentLength)
+}
 
+// storePiece writes the piece data into the writable stores, and responds the result to the client.
+// It tells if the piece has been stored.
+func (p *Proxy) storePiece(
+	rw http.ResponseWriter,
+	req *http.Request,
+	key string,
+	data io.Reader,
+	dataSize int64,
+) bool {
+	key = shardKey(key, p.cfg.ShardChars)
+	targets, writable := p.writableStores(req.Context(), dataSize)
+	if len(targets) > 0 {
+		count, err := p.writeReplicas(req.Context(), key, data, targets)
+		if err != nil {
+			log.Errorw("put piece data", "key", key, "count", count, "err", err)
+			http.Error(rw, fmt.Sprintf("put piece data: %s", err), http.StatusInternalServerError)
+			return false
+		}
+
+		log.Infow("put piece data", "key", key, "count", count)
+		return true
+	}
+
+	if writable == 0 {
+		log.Errorw("put piece data", "key", key, "err", "no writable store available")
+		http.Error(rw, "no writable piece store available", http.StatusServiceUnavailable)
+		return false
+	}
+
+	log.Errorw("put piece data", "key", key, "size", dataSize, "err", "insufficient storage")
+	http.Error(
+		rw,
+		fmt.Sprintf("no piece store has enough free space for %d bytes", dataSize),
+		http.StatusInsufficientStorage,
+	)
+	return false
+}
+
+// writableStores selects at most `Replicas` stores which are able to hold the piece data,
+// along with the count of the writable stores.
+func (p *Proxy) writableStores(ctx context.Context, dataSize int64) ([]objstore.Store, int) {
+	replicas := p.cfg.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+
+	writable := 0
+	selected := make([]objstore.Store, 0, replicas)
 	for _, store := range p.locals {
-		storeInfo, err := store.InstanceInfo(req.Context())
+		storeInfo, err := store.InstanceInfo(ctx)
 		if err != nil {
 			log.Warnw("get store instance info", "err", err)
 			continue
@@ -95,26 +582,28 @@ func (p *Proxy) handlePut(rw http.ResponseWriter, req *http.Request) {
 			continue
 		}
 
+		writable++
+
+		if len(selected) >= replicas {
+			continue
+		}
+
 		// todo : we can't get the free space of the store some time, so there is compromise when free == 0
 		if storeInfo.Free > uint64(dataSize) || storeInfo.Free == 0 {
-			count, err := store.Put(req.Context(), path, req.Body)
-			if err != nil {
-				log.Errorw("put piece data", "path", path, "store", storeInfo.Config.Name, "count", count, "err", err)
-				http.Error(rw, fmt.Sprintf("put piece data: %s", err), http.StatusInternalServerError)
-			}
-
-			log.Infow("put piece data", "path", path, "count", count)
-			return
+			selected = append(selected, store)
 		}
 	}
-	log.Errorw("put piece data", "path", path, "err", "no store available")
-	http.Error(rw, "no piece store available", http.StatusInternalServerError)
+
+	return selected, writable
 }
 
 func (p *Proxy) Get(ctx context.Context, pieceCid cid.Cid) (io.ReadCloser, error) {
-	key := pieceCid.String()
-	for _, store := range p.locals {
-		if r, err := store.Get(ctx, key); err == nil {
+	for _, src := range p.sources {
+		if _, ok := src.(PieceRedirector); ok {
+			continue
+		}
+
+		if r, err := src.Get(ctx, pieceCid); err == nil {
 			return r, nil
 		}
 	}
@@ -123,21 +612,16 @@ func (p *Proxy) Get(ctx context.Context, pieceCid cid.Cid) (io.ReadCloser, error
 }
 
 func (p *Proxy) Put(ctx context.Context, pieceCid cid.Cid, data io.Reader) (int64, error) {
-	key := pieceCid.String()
-	for _, store := range p.locals {
-		storeInfo, err := store.InstanceInfo(ctx)
-		if err != nil {
-			log.Warnw("get store instance info", "err", err)
-			continue
-		}
-
-		if storeInfo.Config.ReadOnly {
-			continue
-		}
+	if !pieceCid.Defined() {
+		return 0, fmt.Errorf("undefined piece cid")
+	}
 
-		count, err := store.Put(ctx, key, data)
+	key := shardKey(pieceCid.String(), p.cfg.ShardChars)
+	targets, _ := p.writableStores(ctx, 0)
+	if len(targets) > 0 {
+		count, err := p.writeReplicas(ctx, key, data, targets)
 		if err != nil {
-			log.Errorw("put piece data", "path", key, "store", storeInfo.Config.Name, "count", count, "err", err)
+			log.Errorw("put piece data", "path", key, "count", count, "err", err)
 			return 0, err
 		}
 
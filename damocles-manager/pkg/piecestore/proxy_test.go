@@ -1,18 +1,26 @@
 package piecestore
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/market"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
 	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore/filestore"
+	"github.com/ipfs/go-cid"
 	"github.com/jbenet/go-random"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -26,6 +34,10 @@ var (
 )
 
 func setupStoreProxy(t *testing.T, resourceEndPoint string) *Proxy {
+	return setupStoreProxyWithConfig(t, resourceEndPoint, DefaultProxyConfig())
+}
+
+func setupStoreProxyWithConfig(t *testing.T, resourceEndPoint string, cfg ProxyConfig) *Proxy {
 	st, err := filestore.Open(objstore.Config{
 		Name: "mock test",
 		Path: t.TempDir(),
@@ -38,7 +50,7 @@ func setupStoreProxy(t *testing.T, resourceEndPoint string) *Proxy {
 		IMarket:          mock.NewMockIMarket(mc),
 		ResourceEndpoint: resourceEndPoint,
 	}
-	return NewProxy([]objstore.Store{st}, marketAPI)
+	return NewProxy([]objstore.Store{st}, marketAPI, cfg)
 }
 
 func TestStorePoxy(t *testing.T) {
@@ -90,6 +102,897 @@ func TestStorePoxy(t *testing.T) {
 			assert.FailNow(t, "expect redirect header but not found")
 		}
 	})
+
+	t.Run("redirect with public base url", func(t *testing.T) {
+		resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+		storeProxy := setupStoreProxyWithConfig(t, "http://10.0.0.1:41235", ProxyConfig{
+			PublicBaseURL: "https://pieces.example.com",
+		})
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+		req.Header.Set("X-Forwarded-Host", "ignored.example.com")
+		w := httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, fmt.Sprintf("https://pieces.example.com?resource-id=%s", resourceID), w.Header().Get("Location"))
+	})
+
+	t.Run("redirect with forwarded headers", func(t *testing.T) {
+		resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+		for _, trust := range []bool{false, true} {
+			storeProxy := setupStoreProxyWithConfig(t, "http://10.0.0.1:41235", ProxyConfig{
+				TrustForwardedHeaders: trust,
+			})
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "pieces.example.com, 10.0.0.2")
+			w := httptest.NewRecorder()
+			storeProxy.ServeHTTP(w, req)
+
+			expected := fmt.Sprintf("http://10.0.0.1:41235?resource-id=%s", resourceID)
+			if trust {
+				expected = fmt.Sprintf("https://pieces.example.com?resource-id=%s", resourceID)
+			}
+
+			assert.Equal(t, http.StatusFound, w.Code)
+			assert.Equal(t, expected, w.Header().Get("Location"), "trust forwarded headers: %v", trust)
+		}
+	})
+
+	t.Run("redirect with custom status", func(t *testing.T) {
+		resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+		for status, expected := range map[int]int{
+			0:                            http.StatusFound,
+			http.StatusTemporaryRedirect: http.StatusTemporaryRedirect,
+			http.StatusSeeOther:          http.StatusSeeOther,
+			http.StatusOK:                http.StatusFound,
+		} {
+			storeProxy := setupStoreProxyWithConfig(t, "http://10.0.0.1:41235", ProxyConfig{
+				RedirectStatus: status,
+			})
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+			w := httptest.NewRecorder()
+			storeProxy.ServeHTTP(w, req)
+
+			assert.Equal(t, expected, w.Code, "configured status: %d", status)
+		}
+	})
+
+	t.Run("refuse redirecting to self", func(t *testing.T) {
+		resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+		for _, c := range []struct {
+			endpoint string
+			cfg      ProxyConfig
+			expected int
+		}{
+			{"http://127.0.0.1:3030", ProxyConfig{}, http.StatusFound},
+			{"http://127.0.0.1:3030", ProxyConfig{DetectSelfByRequestHost: true}, http.StatusBadGateway},
+			{"http://127.0.0.1:9999", ProxyConfig{Listen: "127.0.0.1:9999"}, http.StatusBadGateway},
+			{"http://127.0.0.1:9999", ProxyConfig{Listen: ":9999"}, http.StatusFound},
+			{"http://10.0.0.1:41235", ProxyConfig{SelfURLs: []string{"http://10.0.0.1:41235"}}, http.StatusBadGateway},
+			{"http://10.0.0.1:41235", ProxyConfig{SelfURLs: []string{"https://10.0.0.1:41235"}}, http.StatusFound},
+			{"http://10.0.0.1:41235", ProxyConfig{SelfURLs: []string{"http://10.0.0.2:41235"}}, http.StatusFound},
+			// the target rewritten to the public host shared with the proxy is not a loop
+			{
+				"http://10.0.0.1:41235",
+				ProxyConfig{TrustForwardedHeaders: true, DetectSelfByRequestHost: true},
+				http.StatusFound,
+			},
+			{
+				"http://10.0.0.1:41235",
+				ProxyConfig{PublicBaseURL: "http://127.0.0.1:3030", DetectSelfByRequestHost: true},
+				http.StatusFound,
+			},
+		} {
+			storeProxy := setupStoreProxyWithConfig(t, c.endpoint, c.cfg)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+			req.Header.Set("X-Forwarded-Host", "127.0.0.1:3030")
+			w := httptest.NewRecorder()
+			storeProxy.ServeHTTP(w, req)
+
+			assert.Equal(t, c.expected, w.Code, "endpoint: %s, config: %+v", c.endpoint, c.cfg)
+		}
+	})
+}
+
+func TestStoreProxyPutErrors(t *testing.T) {
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	setup := func(t *testing.T, readOnly bool) *Proxy {
+		st, err := objstore.NewMockStore(objstore.Config{
+			Name:     "mock test",
+			ReadOnly: readOnly,
+		}, 1<<10)
+		require.NoError(t, err, "construct mock store")
+
+		return NewProxy([]objstore.Store{st}, nil, DefaultProxyConfig())
+	}
+
+	cases := []struct {
+		name     string
+		readOnly bool
+		path     string
+		size     int
+		expected int
+	}{
+		{name: "invalid cid", path: "not-a-cid", size: 100, expected: http.StatusBadRequest},
+		{name: "read only stores", readOnly: true, path: resourceID, size: 100, expected: http.StatusServiceUnavailable},
+		{name: "insufficient storage", path: resourceID, size: 4 << 10, expected: http.StatusInsufficientStorage},
+		{name: "ok", path: resourceID, size: 100, expected: http.StatusOK},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			storeProxy := setup(t, c.readOnly)
+			req := httptest.NewRequest(
+				http.MethodPut,
+				fmt.Sprintf("http://127.0.0.1:3030/%s", c.path),
+				bytes.NewReader(make([]byte, c.size)),
+			)
+			w := httptest.NewRecorder()
+			storeProxy.ServeHTTP(w, req)
+
+			assert.Equal(t, c.expected, w.Code)
+		})
+	}
+}
+
+func TestStoreProxyAuth(t *testing.T) {
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	st, err := objstore.NewMockStore(objstore.Config{
+		Name: "mock test",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+
+	cfg := DefaultProxyConfig()
+	cfg.AuthToken = "secret"
+	storeProxy := NewProxy([]objstore.Store{st}, nil, cfg)
+
+	do := func(method, token string) int {
+		req := httptest.NewRequest(
+			method,
+			fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+			bytes.NewReader([]byte("piece data")),
+		)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPut, ""))
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodPut, "wrong"))
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "secret"))
+	require.Equal(t, http.StatusOK, do(http.MethodGet, ""))
+
+	storeProxy.cfg.AuthReads = true
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, ""))
+	require.Equal(t, http.StatusOK, do(http.MethodGet, "secret"))
+}
+
+func TestStoreProxyPutCanonicalKey(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+	upperResourceID := strings.ToUpper(resourceID)
+
+	st, err := objstore.NewMockStore(objstore.Config{
+		Name: "mock test",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+
+	storeProxy := NewProxy([]objstore.Store{st}, nil, DefaultProxyConfig())
+
+	data := []byte("piece data")
+	req := httptest.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://127.0.0.1:3030/%s", upperResourceID),
+		bytes.NewReader(data),
+	)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err = st.Stat(ctx, resourceID)
+	require.NoError(t, err, "piece should be stored with the canonical key")
+
+	_, err = st.Stat(ctx, upperResourceID)
+	require.ErrorIs(t, err, objstore.ErrObjectNotFound)
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", upperResourceID), nil)
+	w = httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, data, w.Body.Bytes())
+}
+
+func TestStoreProxyShardedKeys(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+	legacyID := "bafy2bzacea2a75bbdhr6gjozglrmp4akkgzsw3xh3s62j3hga5uhmkxwne5b6"
+
+	st, err := objstore.NewMockStore(objstore.Config{
+		Name: "mock test",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+
+	cfg := DefaultProxyConfig()
+	cfg.ShardChars = 2
+	storeProxy := NewProxy([]objstore.Store{st}, nil, cfg)
+
+	data := []byte("piece data")
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), bytes.NewReader(data))
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err = st.Stat(ctx, "pw/"+resourceID)
+	require.NoError(t, err, "piece should be stored in the shard dir")
+
+	_, err = st.Put(ctx, legacyID, bytes.NewReader(data))
+	require.NoError(t, err, "put legacy piece")
+
+	for _, id := range []string{resourceID, legacyID} {
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", id), nil)
+		w = httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "resource: %s", id)
+		require.Equal(t, data, w.Body.Bytes(), "resource: %s", id)
+	}
+}
+
+func TestListLocalPieces(t *testing.T) {
+	ctx := context.Background()
+	shardedID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+	legacyID := "bafy2bzacea2a75bbdhr6gjozglrmp4akkgzsw3xh3s62j3hga5uhmkxwne5b6"
+
+	st, err := filestore.Open(objstore.Config{
+		Name: "mock test",
+		Path: t.TempDir(),
+	}, false)
+	require.NoError(t, err, "open mock store")
+
+	cfg := DefaultProxyConfig()
+	cfg.ShardChars = 2
+	storeProxy := NewProxy([]objstore.Store{st}, nil, cfg)
+
+	_, err = storeProxy.Put(ctx, cid.MustParse(shardedID), bytes.NewReader([]byte("piece data")))
+	require.NoError(t, err, "put sharded piece")
+	_, err = st.Put(ctx, legacyID+carSuffix, bytes.NewReader([]byte("legacy")))
+	require.NoError(t, err, "put legacy piece")
+	_, err = st.Put(ctx, "not-a-piece", bytes.NewReader([]byte("junk")))
+	require.NoError(t, err, "put junk")
+
+	pieces, err := storeProxy.ListLocalPieces(ctx, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, pieces, 2)
+
+	sizes := map[string]int64{}
+	for _, p := range pieces {
+		require.Equal(t, st.Instance(ctx), p.Store)
+		sizes[p.PieceCid.String()] = p.Size
+	}
+	require.Equal(t, map[string]int64{shardedID: 10, legacyID: 6}, sizes)
+
+	page, err := storeProxy.ListLocalPieces(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, pieces[1:], page)
+
+	page, err = storeProxy.ListLocalPieces(ctx, 2, 1)
+	require.NoError(t, err)
+	require.Empty(t, page)
+
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:3030/?limit=1", nil)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var listed []LocalPiece
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Equal(t, pieces[:1], listed)
+}
+
+func TestStoreProxyPutReplicas(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	stores := make([]objstore.Store, 0, 3)
+	for i := 0; i < 3; i++ {
+		st, err := objstore.NewMockStore(objstore.Config{
+			Name: fmt.Sprintf("mock test %d", i),
+		}, 1<<10)
+		require.NoError(t, err, "construct mock store")
+		stores = append(stores, st)
+	}
+
+	cfg := DefaultProxyConfig()
+	cfg.Replicas = 2
+	cfg.RequiredReplicas = 2
+	storeProxy := NewProxy(stores, nil, cfg)
+	defer storeProxy.Close()
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+		bytes.NewReader([]byte("piece data")),
+	)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for i, st := range stores {
+		_, err := st.Stat(ctx, resourceID)
+		if i < cfg.Replicas {
+			require.NoErrorf(t, err, "piece should be replicated into store #%d", i)
+		} else {
+			require.ErrorIsf(t, err, objstore.ErrObjectNotFound, "piece should not be written into store #%d", i)
+		}
+	}
+}
+
+type failingStore struct {
+	objstore.Store
+}
+
+func (*failingStore) Put(_ context.Context, _ string, r io.Reader) (int64, error) {
+	n, _ := io.CopyN(io.Discard, r, 2)
+	return n, fmt.Errorf("disk failure")
+}
+
+func TestStoreProxyPutReplicaFailure(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	stores := make([]objstore.Store, 0, 3)
+	for i := 0; i < 3; i++ {
+		st, err := objstore.NewMockStore(objstore.Config{
+			Name: fmt.Sprintf("mock test %d", i),
+		}, 1<<10)
+		require.NoError(t, err, "construct mock store")
+		stores = append(stores, st)
+	}
+
+	stores[0] = &failingStore{Store: stores[0]}
+
+	cfg := DefaultProxyConfig()
+	cfg.Replicas = 3
+	cfg.RequiredReplicas = 2
+	storeProxy := NewProxy(stores, nil, cfg)
+	defer storeProxy.Close()
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+		bytes.NewReader([]byte("piece data")),
+	)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for i, st := range stores[1:] {
+		_, err := st.Stat(ctx, resourceID)
+		require.NoErrorf(t, err, "piece should be written into store #%d", i+1)
+	}
+
+	stores[1] = &failingStore{Store: stores[1]}
+	stores[2] = &failingStore{Store: stores[2]}
+	storeProxy = NewProxy(stores, nil, cfg)
+	defer storeProxy.Close()
+
+	w = httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, httptest.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+		bytes.NewReader([]byte("piece data")),
+	))
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+type gatedStore struct {
+	objstore.Store
+	gate chan struct{}
+}
+
+func (s *gatedStore) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	<-s.gate
+	return s.Store.Put(ctx, p, r)
+}
+
+func TestStoreProxyPutRequiredReplicas(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	stores := make([]objstore.Store, 0, 2)
+	for i := 0; i < 2; i++ {
+		st, err := objstore.NewMockStore(objstore.Config{
+			Name: fmt.Sprintf("mock test %d", i),
+		}, 1<<10)
+		require.NoError(t, err, "construct mock store")
+		stores = append(stores, st)
+	}
+
+	gate := make(chan struct{})
+	slow := stores[1]
+	stores[1] = &gatedStore{Store: slow, gate: gate}
+
+	cfg := DefaultProxyConfig()
+	cfg.Replicas = 2
+	cfg.ReplicaConcurrency = 1
+	storeProxy := NewProxy(stores, nil, cfg)
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+		bytes.NewReader([]byte("piece data")),
+	)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	_, err := stores[0].Stat(ctx, resourceID)
+	require.NoError(t, err, "piece should be written into the first store")
+
+	_, err = slow.Stat(ctx, resourceID)
+	require.ErrorIs(t, err, objstore.ErrObjectNotFound, "replica should still be pending")
+
+	close(gate)
+	require.Eventually(t, func() bool {
+		_, err := slow.Stat(ctx, resourceID)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond, "replica should be written in the background")
+
+	storeProxy.Close()
+}
+
+func TestStoreProxyPutResumable(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	st, err := objstore.NewMockStore(objstore.Config{
+		Name: "mock test",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+
+	cfg := DefaultProxyConfig()
+	cfg.UploadDir = t.TempDir()
+	storeProxy := NewProxy([]objstore.Store{st}, nil, cfg)
+
+	data := []byte("resumable piece data")
+	putChunk := func(session string, start, end int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(
+			http.MethodPut,
+			fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+			bytes.NewReader(data[start:end]),
+		)
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+		if session != "" {
+			req.Header.Set(HeaderUploadSession, session)
+		}
+		w := httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+		return w
+	}
+
+	w := putChunk("", 0, 8)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	session := w.Header().Get(HeaderUploadSession)
+	require.NotEmpty(t, session)
+	require.Equal(t, "8", w.Header().Get(HeaderUploadOffset))
+
+	_, err = st.Stat(ctx, resourceID)
+	require.ErrorIs(t, err, objstore.ErrObjectNotFound, "piece should not be stored before completed")
+
+	w = putChunk(session, 10, len(data))
+	require.Equal(t, http.StatusConflict, w.Code)
+	require.Equal(t, "8", w.Header().Get(HeaderUploadOffset))
+
+	w = putChunk(session, 8, len(data))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	r, err := st.Get(ctx, resourceID)
+	require.NoError(t, err)
+	got, err := io.ReadAll(r)
+	r.Close()
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+
+	entries, err := os.ReadDir(cfg.UploadDir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "temp file should be removed")
+
+	w = putChunk(session, 0, len(data))
+	require.Equal(t, http.StatusNotFound, w.Code, "session should be closed")
+}
+
+type switchableStore struct {
+	objstore.Store
+	fail atomic.Bool
+}
+
+func (s *switchableStore) Put(ctx context.Context, p string, r io.Reader) (int64, error) {
+	if s.fail.Load() {
+		return 0, fmt.Errorf("disk failure")
+	}
+
+	return s.Store.Put(ctx, p, r)
+}
+
+func TestStoreProxyPutResumableSessions(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+	data := []byte("resumable piece data")
+
+	setup := func(t *testing.T, modify func(*ProxyConfig)) (*Proxy, *switchableStore, string) {
+		st, err := objstore.NewMockStore(objstore.Config{
+			Name: "mock test",
+		}, 1<<10)
+		require.NoError(t, err, "construct mock store")
+
+		cfg := DefaultProxyConfig()
+		cfg.UploadDir = t.TempDir()
+		if modify != nil {
+			modify(&cfg)
+		}
+
+		store := &switchableStore{Store: st}
+		return NewProxy([]objstore.Store{store}, nil, cfg), store, cfg.UploadDir
+	}
+
+	putChunk := func(p *Proxy, session string, start, end int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(
+			http.MethodPut,
+			fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID),
+			bytes.NewReader(data[start:end]),
+		)
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+		if session != "" {
+			req.Header.Set(HeaderUploadSession, session)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w
+	}
+
+	uploadFiles := func(t *testing.T, dir string) int {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	t.Run("retry storing", func(t *testing.T) {
+		p, store, dir := setup(t, nil)
+		defer p.Close()
+
+		store.fail.Store(true)
+		w := putChunk(p, "", 0, len(data))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		session := w.Header().Get(HeaderUploadSession)
+		require.NotEmpty(t, session)
+		require.Equal(t, 1, uploadFiles(t, dir), "uploaded data should be kept")
+
+		store.fail.Store(false)
+		w = putChunk(p, session, 8, len(data))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, 0, uploadFiles(t, dir), "temp file should be removed")
+
+		r, err := store.Get(ctx, resourceID)
+		require.NoError(t, err)
+		got, err := io.ReadAll(r)
+		r.Close()
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		p, _, dir := setup(t, func(cfg *ProxyConfig) {
+			cfg.MaxUploadSize = int64(len(data) - 1)
+		})
+		defer p.Close()
+
+		w := putChunk(p, "", 0, 8)
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Empty(t, w.Header().Get(HeaderUploadSession))
+		require.Equal(t, 0, uploadFiles(t, dir))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		p, _, dir := setup(t, func(cfg *ProxyConfig) {
+			cfg.UploadSessionTimeout = 50 * time.Millisecond
+		})
+		defer p.Close()
+
+		w := putChunk(p, "", 0, 8)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		session := w.Header().Get(HeaderUploadSession)
+
+		require.Eventually(t, func() bool {
+			return uploadFiles(t, dir) == 0
+		}, 5*time.Second, 10*time.Millisecond, "expired session should be swept")
+
+		w = putChunk(p, session, 8, len(data))
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		p, _, dir := setup(t, nil)
+
+		w := putChunk(p, "", 0, 8)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		require.Equal(t, 1, uploadFiles(t, dir))
+
+		p.Close()
+		require.Equal(t, 0, uploadFiles(t, dir), "sessions should be removed on close")
+	})
+}
+
+func TestParseContentRange(t *testing.T) {
+	cr, err := parseContentRange("bytes 10-19/100")
+	require.NoError(t, err)
+	require.Equal(t, contentRange{start: 10, end: 19, total: 100}, cr)
+
+	for _, v := range []string{"items 0-1/2", "bytes 0-1/*", "bytes 5-1/10", "bytes 0-10/10", "bytes 0/10"} {
+		_, err := parseContentRange(v)
+		require.Errorf(t, err, "%q should be rejected", v)
+	}
+}
+
+func TestStoreProxyGetGzip(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	storeProxy := setupStoreProxy(t, "mock")
+	data := bytes.Repeat([]byte("piece data"), 1000)
+	_, err := storeProxy.locals[0].Put(ctx, resourceID, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	for _, accept := range []string{"", "identity", "gzip;q=0", "deflate, gzip;q=0.8"} {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+		if accept != "" {
+			req.Header.Set("Accept-Encoding", accept)
+		}
+		w := httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "accept encoding: %q", accept)
+
+		if !acceptsGzip(req.Header.Values("Accept-Encoding")) {
+			require.Empty(t, w.Header().Get("Content-Encoding"), "accept encoding: %q", accept)
+			require.Equal(t, data, w.Body.Bytes(), "accept encoding: %q", accept)
+			continue
+		}
+
+		require.Equal(t, "gzip", w.Header().Get("Content-Encoding"), "accept encoding: %q", accept)
+		gr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err, "accept encoding: %q", accept)
+		got, err := io.ReadAll(gr)
+		require.NoError(t, err, "accept encoding: %q", accept)
+		require.Equal(t, data, got, "accept encoding: %q", accept)
+	}
+
+	noise := make([]byte, 10000)
+	_, err = rand.Read(noise)
+	require.NoError(t, err)
+
+	for name, data := range map[string][]byte{
+		"small":          []byte("piece data"),
+		"incompressible": noise,
+	} {
+		_, err := storeProxy.locals[0].Put(ctx, resourceID, bytes.NewReader(data))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		storeProxy.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, name)
+		require.Empty(t, w.Header().Get("Content-Encoding"), name)
+		require.Equal(t, data, w.Body.Bytes(), name)
+	}
+}
+
+type stalledStore struct {
+	objstore.Store
+	delay time.Duration
+}
+
+func (s *stalledStore) Get(ctx context.Context, p string) (io.ReadCloser, error) {
+	time.Sleep(s.delay)
+	return s.Store.Get(ctx, p)
+}
+
+func TestStoreProxyGetReadTimeout(t *testing.T) {
+	ctx := context.Background()
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+
+	stalled, err := objstore.NewMockStore(objstore.Config{
+		Name: "stalled",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+	_, err = stalled.Put(ctx, resourceID, bytes.NewReader([]byte("stalled data")))
+	require.NoError(t, err)
+
+	healthy, err := objstore.NewMockStore(objstore.Config{
+		Name: "healthy",
+	}, 1<<10)
+	require.NoError(t, err, "construct mock store")
+	_, err = healthy.Put(ctx, resourceID, bytes.NewReader([]byte("healthy data")))
+	require.NoError(t, err)
+
+	cfg := DefaultProxyConfig()
+	cfg.ReadTimeout = 50 * time.Millisecond
+	storeProxy := NewProxy([]objstore.Store{&stalledStore{Store: stalled, delay: time.Second}, healthy}, nil, cfg)
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+	w := httptest.NewRecorder()
+	start := time.Now()
+	storeProxy.ServeHTTP(w, req)
+	require.Less(t, time.Since(start), time.Second, "stalled store should be skipped")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []byte("healthy data"), w.Body.Bytes())
+}
+
+// blockingReader returns the data at the first read, and blocks the following reads until unblocked.
+type blockingReader struct {
+	data    []byte
+	unblock chan struct{}
+	closed  chan struct{}
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	if len(b.data) > 0 {
+		n := copy(p, b.data)
+		b.data = b.data[n:]
+		return n, nil
+	}
+
+	<-b.unblock
+	return 0, io.EOF
+}
+
+func (b *blockingReader) Close() error {
+	close(b.closed)
+	return nil
+}
+
+func TestTimeoutReader(t *testing.T) {
+	br := &blockingReader{
+		data:    []byte("piece data"),
+		unblock: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+
+	r := newTimeoutReader(br, 50*time.Millisecond)
+	start := time.Now()
+	got, err := io.ReadAll(r)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second, "stalled read should time out")
+	require.Equal(t, []byte("piece data"), got)
+
+	_, err = r.Read(make([]byte, 1))
+	require.ErrorIs(t, err, context.DeadlineExceeded, "reader is not usable after a timeout")
+
+	require.NoError(t, r.Close())
+	select {
+	case <-br.closed:
+		t.Fatal("underlying reader closed before the stalled read returns")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(br.unblock)
+	select {
+	case <-br.closed:
+	case <-time.After(time.Second):
+		t.Fatal("underlying reader not closed after the stalled read returns")
+	}
+}
+
+type staticSource struct {
+	name   string
+	pieces map[cid.Cid][]byte
+}
+
+func (s *staticSource) Name() string { return s.name }
+
+func (s *staticSource) Has(_ context.Context, c cid.Cid) (bool, error) {
+	_, ok := s.pieces[c]
+	return ok, nil
+}
+
+func (s *staticSource) Get(_ context.Context, c cid.Cid) (io.ReadCloser, error) {
+	data, ok := s.pieces[c]
+	if !ok {
+		return nil, ErrPieceNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestStoreProxyGetSources(t *testing.T) {
+	resourceID := "bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw"
+	c, err := cid.Decode(resourceID)
+	require.NoError(t, err)
+
+	sources := []PieceSource{
+		&staticSource{name: "empty"},
+		&staticSource{name: "peer", pieces: map[cid.Cid][]byte{c: []byte("peer data")}},
+	}
+	storeProxy := NewProxyWithSources(nil, sources, DefaultProxyConfig())
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", resourceID), nil)
+	w := httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, []byte("peer data"), w.Body.Bytes())
+
+	other := "bafy2bzacea2a75bbdhr6gjozglrmp4akkgzsw3xh3s62j3hga5uhmkxwne5b6"
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("http://127.0.0.1:3030/%s", other), nil)
+	w = httptest.NewRecorder()
+	storeProxy.ServeHTTP(w, req)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLocalSourceWithIndex(t *testing.T) {
+	ctx := context.Background()
+	st, err := filestore.Open(objstore.Config{
+		Name: "mock test",
+		Path: t.TempDir(),
+	}, false)
+	require.NoError(t, err, "open mock store")
+
+	c, err := cid.Decode("bafy2bzacecc4iu4nsmm5vqkj427xtkqjedcclo77glct2j5rhrrohe3xj7zpw")
+	require.NoError(t, err)
+	missing, err := cid.Decode("bafy2bzacea2a75bbdhr6gjozglrmp4akkgzsw3xh3s62j3hga5uhmkxwne5b6")
+	require.NoError(t, err)
+
+	_, err = st.Put(ctx, c.String(), bytes.NewReader([]byte("piece data")))
+	require.NoError(t, err)
+
+	local := NewLocalSource([]objstore.Store{st}, 0)
+	local.index = newPieceIndex()
+	local.index.rebuild(ctx, local.stores)
+
+	instance, found, authoritative := local.index.lookup(c.String())
+	require.True(t, found)
+	require.True(t, authoritative)
+	require.Equal(t, st.Instance(ctx), instance)
+
+	r, err := local.Get(ctx, c)
+	require.NoError(t, err)
+	r.Close()
+
+	_, err = local.Get(ctx, missing)
+	require.ErrorIs(t, err, ErrPieceNotFound)
+
+	// removed out of band
+	require.NoError(t, st.Del(ctx, c.String()))
+	_, err = local.Get(ctx, c)
+	require.ErrorIs(t, err, ErrPieceNotFound)
+	_, found, _ = local.index.lookup(c.String())
+	require.False(t, found, "stale entry should be dropped")
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	cases := map[string]bool{
+		"":                  false,
+		"identity":          false,
+		"gzip":              true,
+		"GZIP":              true,
+		"x-gzip":            true,
+		"gzip;q=0":          false,
+		"gzip; q=0.5":       true,
+		"br, gzip;q=0.8":    true,
+		"deflate, identity": false,
+	}
+
+	for header, expected := range cases {
+		require.Equal(t, expected, acceptsGzip([]string{header}), "accept encoding: %q", header)
+	}
 }
 
 func TestParsePieceName(t *testing.T) {
@@ -0,0 +1,180 @@
+package piecestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
+)
+
+type replicaResult struct {
+	store objstore.Store
+	count int64
+	err   error
+}
+
+// writeReplicas writes the piece data into the targets, and returns the size of the data.
+// The data is teed into at most ReplicaConcurrency targets at the same time, a failed write doesn't affect the others,
+// the rest of the targets are copied from a store holding the piece. It returns once RequiredReplicas of the writes
+// have succeeded, the others keep running in the background until the proxy is closed.
+func (p *Proxy) writeReplicas(
+	ctx context.Context,
+	key string,
+	data io.Reader,
+	targets []objstore.Store,
+) (int64, error) {
+	width := p.replicaConcurrency()
+	if width > len(targets) {
+		width = len(targets)
+	}
+
+	required := p.cfg.RequiredReplicas
+	if required <= 0 {
+		required = 1
+	}
+
+	if required > len(targets) {
+		required = len(targets)
+	}
+
+	teed, rest := targets[:width], targets[width:]
+	results := make(chan replicaResult, len(targets))
+	fanout := &fanoutWriter{writers: make([]io.Writer, 0, len(teed))}
+	writers := make([]*io.PipeWriter, 0, len(teed))
+	for _, target := range teed {
+		pr, pw := io.Pipe()
+		fanout.writers = append(fanout.writers, pw)
+		writers = append(writers, pw)
+		// the writes may outlive the request, they are bound to the proxy instead
+		go func(target objstore.Store) {
+			count, err := target.Put(p.ctx, key, pr)
+			// unblock the tee if the store stops reading early
+			pr.Close()
+			results <- replicaResult{store: target, count: count, err: err}
+		}(target)
+	}
+
+	size, copyErr := io.Copy(fanout, data)
+	for _, pw := range writers {
+		pw.CloseWithError(copyErr)
+	}
+
+	notify := make(chan error, 1)
+	p.background(func(bctx context.Context) {
+		p.collectReplicas(bctx, key, rest, results, len(teed), required, notify)
+	})
+
+	select {
+	case err := <-notify:
+		return size, err
+	case <-ctx.Done():
+		return size, ctx.Err()
+	}
+}
+
+func (p *Proxy) replicaConcurrency() int {
+	if p.cfg.ReplicaConcurrency <= 0 {
+		return 1
+	}
+
+	return p.cfg.ReplicaConcurrency
+}
+
+// collectReplicas gathers the results of the pending writes, and copies the piece into the rest of the targets
+// from a store holding it, keeping at most ReplicaConcurrency writes running. The outcome is sent to notify once
+// the required count of the writes have succeeded, or can no longer be reached, failures are logged.
+func (p *Proxy) collectReplicas(
+	ctx context.Context,
+	key string,
+	rest []objstore.Store,
+	results chan replicaResult,
+	pending int,
+	required int,
+	notify chan<- error,
+) {
+	limit := p.replicaConcurrency()
+	succeeded := 0
+	notified := false
+	var holder objstore.Store
+	var lastErr error
+	for pending > 0 {
+		res := <-results
+		pending--
+
+		instance := res.store.Instance(ctx)
+		if res.err != nil {
+			log.Warnw("write piece replica", "key", key, "store", instance, "count", res.count, "err", res.err)
+			lastErr = res.err
+		} else {
+			p.index.add(key, instance)
+			succeeded++
+			if holder == nil {
+				holder = res.store
+			}
+		}
+
+		for holder != nil && len(rest) > 0 && pending < limit && ctx.Err() == nil {
+			dest := rest[0]
+			rest = rest[1:]
+			pending++
+			go func(src, dest objstore.Store) {
+				count, err := copyReplica(ctx, key, src, dest)
+				results <- replicaResult{store: dest, count: count, err: err}
+			}(holder, dest)
+		}
+
+		if !notified && succeeded >= required {
+			notify <- nil
+			notified = true
+		}
+	}
+
+	if notified {
+		return
+	}
+
+	if succeeded == 0 {
+		notify <- fmt.Errorf("all the writes failed, the last one: %w", lastErr)
+		return
+	}
+
+	notify <- fmt.Errorf("only %d of the %d required replicas written, the last failure: %w", succeeded, required, lastErr)
+}
+
+// fanoutWriter writes into all the live writers, the failed ones are dropped,
+// it fails only if all of them have failed.
+type fanoutWriter struct {
+	writers []io.Writer
+}
+
+func (f *fanoutWriter) Write(b []byte) (int, error) {
+	var lastErr error
+	live := f.writers[:0]
+	for _, w := range f.writers {
+		if _, err := w.Write(b); err != nil {
+			lastErr = err
+			continue
+		}
+
+		live = append(live, w)
+	}
+
+	f.writers = live
+	if len(live) == 0 {
+		return 0, fmt.Errorf("all the writers failed: %w", lastErr)
+	}
+
+	return len(b), nil
+}
+
+// copyReplica copies the piece data from src into dest.
+func copyReplica(ctx context.Context, key string, src, dest objstore.Store) (int64, error) {
+	r, err := src.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("open piece data in %s: %w", src.Instance(ctx), err)
+	}
+
+	defer r.Close()
+	return dest.Put(ctx, key, r)
+}
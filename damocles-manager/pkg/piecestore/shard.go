@@ -0,0 +1,37 @@
+package piecestore
+
+import (
+	"path"
+	"strings"
+)
+
+// shardKey places the object under the sub dir named after the trailing chars of the piece cid,
+// the leading chars are shared by all the piece cids with the same codec & multihash, e.g. `baga6ea4seaq`.
+// The key is kept as is if sharding is disabled.
+func shardKey(key string, chars int) string {
+	if chars <= 0 {
+		return key
+	}
+
+	name := strings.TrimSuffix(key, carSuffix)
+	if len(name) <= chars {
+		return key
+	}
+
+	return path.Join(name[len(name)-chars:], key)
+}
+
+// withShardedKeys puts the sharded keys ahead of the given ones, which are kept for the pieces
+// stored before sharding was enabled.
+func withShardedKeys(keys []string, chars int) []string {
+	if chars <= 0 {
+		return keys
+	}
+
+	all := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		all = append(all, shardKey(key, chars))
+	}
+
+	return append(all, keys...)
+}
@@ -0,0 +1,337 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/market"
+	"github.com/ipfs-force-community/damocles/damocles-manager/pkg/objstore"
+)
+
+var ErrPieceNotFound = errors.New("piece not found")
+
+// PieceSource is a place the piece data could be retrieved from,
+// the proxy tries the sources in order for each GET request.
+type PieceSource interface {
+	Name() string
+	// Has checks if the piece is available in this source.
+	Has(ctx context.Context, c cid.Cid) (bool, error)
+	// Get returns the reader of the piece data, ErrPieceNotFound if the piece is not available.
+	Get(ctx context.Context, c cid.Cid) (io.ReadCloser, error)
+}
+
+// PieceRedirector is implemented by the sources which serve the pieces by themselves,
+// the clients will be redirected to them instead of being served by the proxy.
+type PieceRedirector interface {
+	PieceURL(ctx context.Context, c cid.Cid) (string, error)
+}
+
+type requestedNameKey struct{}
+
+// withRequestedName attaches the cid string in the request to the context,
+// so that the pieces stored under the non-canonical names could be found.
+func withRequestedName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, requestedNameKey{}, name)
+}
+
+func requestedName(ctx context.Context, c cid.Cid) string {
+	if name, ok := ctx.Value(requestedNameKey{}).(string); ok && name != "" {
+		return name
+	}
+
+	return c.String()
+}
+
+var _ PieceSource = (*LocalSource)(nil)
+
+// NewLocalSource returns a source backed by the local stores,
+// readTimeout is the timeout of opening the piece data in each store, and of each read on the opened data,
+// 0 means no timeout.
+func NewLocalSource(stores []objstore.Store, readTimeout time.Duration) *LocalSource {
+	return &LocalSource{
+		stores:      stores,
+		readTimeout: readTimeout,
+	}
+}
+
+type LocalSource struct {
+	stores      []objstore.Store
+	readTimeout time.Duration
+	index       *pieceIndex
+	shardChars  int
+}
+
+func (*LocalSource) Name() string {
+	return "local"
+}
+
+func (l *LocalSource) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	keys := withShardedKeys(pieceKeys(c, requestedName(ctx, c)), l.shardChars)
+	if found, authoritative := l.indexedHas(ctx, keys); found || authoritative {
+		return found, nil
+	}
+
+	for _, store := range l.stores {
+		for _, key := range keys {
+			if _, err := store.Stat(ctx, key); err == nil {
+				l.index.add(key, store.Instance(ctx))
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (l *LocalSource) Get(ctx context.Context, c cid.Cid) (io.ReadCloser, error) {
+	keys := withShardedKeys(pieceKeys(c, requestedName(ctx, c)), l.shardChars)
+	r, authoritative := l.indexedGet(ctx, keys)
+	if r != nil {
+		return r, nil
+	}
+
+	if authoritative {
+		return nil, ErrPieceNotFound
+	}
+
+	for _, store := range l.stores {
+		for _, key := range keys {
+			r, err := l.open(ctx, store, key)
+			if errors.Is(err, context.DeadlineExceeded) {
+				log.Warnw("open piece data timed out, skip the store", "key", key, "store", store.Instance(ctx))
+				break
+			}
+
+			if err == nil {
+				l.index.add(key, store.Instance(ctx))
+				return r, nil
+			}
+		}
+	}
+
+	return nil, ErrPieceNotFound
+}
+
+// indexedHas checks the keys with the piece index, the stale entries will be dropped.
+func (l *LocalSource) indexedHas(ctx context.Context, keys []string) (found bool, authoritative bool) {
+	authoritative = true
+	for _, key := range keys {
+		instance, ok, complete := l.index.lookup(key)
+		authoritative = authoritative && complete
+		if !ok {
+			continue
+		}
+
+		if store := l.store(ctx, instance); store != nil {
+			if _, err := store.Stat(ctx, key); err == nil {
+				return true, authoritative
+			}
+		}
+
+		// changed out of band, fallback to scanning the stores
+		l.index.remove(key, instance)
+		authoritative = false
+	}
+
+	return false, authoritative
+}
+
+// indexedGet opens the piece data with the piece index, the stale entries will be dropped.
+func (l *LocalSource) indexedGet(ctx context.Context, keys []string) (r io.ReadCloser, authoritative bool) {
+	authoritative = true
+	for _, key := range keys {
+		instance, ok, complete := l.index.lookup(key)
+		authoritative = authoritative && complete
+		if !ok {
+			continue
+		}
+
+		if store := l.store(ctx, instance); store != nil {
+			r, err := l.open(ctx, store, key)
+			if err == nil {
+				return r, authoritative
+			}
+
+			// a stalled store says nothing about the existence of the piece, keep the entry
+			if errors.Is(err, context.DeadlineExceeded) {
+				authoritative = false
+				continue
+			}
+		}
+
+		l.index.remove(key, instance)
+		authoritative = false
+	}
+
+	return nil, authoritative
+}
+
+func (l *LocalSource) store(ctx context.Context, instance string) objstore.Store {
+	for _, store := range l.stores {
+		if store.Instance(ctx) == instance {
+			return store
+		}
+	}
+
+	return nil
+}
+
+// open opens the piece data in the given store, within the read timeout.
+// The returned reader fails the reads which exceed the read timeout as well.
+func (l *LocalSource) open(ctx context.Context, store objstore.Store, key string) (io.ReadCloser, error) {
+	if l.readTimeout <= 0 {
+		return store.Get(ctx, key)
+	}
+
+	type result struct {
+		r   io.ReadCloser
+		err error
+	}
+
+	// the reader should not be bound to the timeout context, it's used after this function returns
+	done := make(chan result, 1)
+	go func() {
+		r, err := store.Get(ctx, key)
+		done <- result{r: r, err: err}
+	}()
+
+	timer := time.NewTimer(l.readTimeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		return newTimeoutReader(res.r, l.readTimeout), nil
+
+	case <-timer.C:
+		// release the reader once the stalled store returns
+		go func() {
+			if res := <-done; res.err == nil {
+				res.r.Close()
+			}
+		}()
+		return nil, fmt.Errorf("open %s in store %s: %w", key, store.Instance(ctx), context.DeadlineExceeded)
+
+	case <-ctx.Done():
+		go func() {
+			if res := <-done; res.err == nil {
+				res.r.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// timeoutReader fails the reads which can not be done within the timeout, e.g. on a hung NFS mount,
+// which won't be interrupted by the context. Each read is run in its own goroutine, and the reader is
+// not usable anymore after a timeout, the underlying reader is closed once the stalled read returns.
+type timeoutReader struct {
+	r       io.ReadCloser
+	timeout time.Duration
+	buf     []byte
+	err     error
+	stalled chan struct{}
+}
+
+func newTimeoutReader(r io.ReadCloser, timeout time.Duration) *timeoutReader {
+	return &timeoutReader{
+		r:       r,
+		timeout: timeout,
+	}
+}
+
+func (t *timeoutReader) Read(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+
+	// the stalled read may still write into the buffer, so p is never handed to the underlying reader
+	if len(t.buf) < len(p) {
+		t.buf = make([]byte, len(p))
+	}
+
+	buf := t.buf[:len(p)]
+	type result struct {
+		n   int
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		n, err := t.r.Read(buf)
+		done <- result{n: n, err: err}
+	}()
+
+	timer := time.NewTimer(t.timeout)
+	defer timer.Stop()
+
+	select {
+	case res := <-done:
+		copy(p, buf[:res.n])
+		return res.n, res.err
+
+	case <-timer.C:
+		t.err = fmt.Errorf("read piece data: %w", context.DeadlineExceeded)
+		t.stalled = make(chan struct{})
+		go func() {
+			<-done
+			close(t.stalled)
+		}()
+
+		return 0, t.err
+	}
+}
+
+func (t *timeoutReader) Close() error {
+	if t.stalled == nil {
+		return t.r.Close()
+	}
+
+	go func() {
+		<-t.stalled
+		t.r.Close()
+	}()
+
+	return nil
+}
+
+var (
+	_ PieceSource     = (*MarketSource)(nil)
+	_ PieceRedirector = (*MarketSource)(nil)
+)
+
+// NewMarketSource returns a source which redirects the clients to the market service.
+func NewMarketSource(mapi market.API) *MarketSource {
+	return &MarketSource{
+		market: mapi,
+	}
+}
+
+type MarketSource struct {
+	market market.API
+}
+
+func (*MarketSource) Name() string {
+	return "market"
+}
+
+// Has always returns true, the availability is left to the market service.
+func (*MarketSource) Has(context.Context, cid.Cid) (bool, error) {
+	return true, nil
+}
+
+func (*MarketSource) Get(context.Context, cid.Cid) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("market source only supports redirection: %w", ErrPieceNotFound)
+}
+
+func (m *MarketSource) PieceURL(_ context.Context, c cid.Cid) (string, error) {
+	return m.market.PieceResourceURL(c), nil
+}
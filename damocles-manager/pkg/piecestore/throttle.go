@@ -0,0 +1,111 @@
+package piecestore
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// throttleChunk is the max size of each read of the throttled readers, to keep the transfers smooth.
+const throttleChunk = 32 << 10
+
+// bandwidthLimiter is a token bucket of bytes, holding at most one second worth of tokens.
+// The rate is read on each wait, so that it could be changed on the fly.
+type bandwidthLimiter struct {
+	rate *atomic.Int64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newBandwidthLimiter(rate *atomic.Int64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		rate: rate,
+	}
+}
+
+// wait takes n tokens, and blocks until they are available, 0 or negative rate means no limit.
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	rate := float64(l.rate.Load())
+	if rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.last.IsZero() {
+		l.tokens = rate
+	} else {
+		l.tokens += now.Sub(l.last).Seconds() * rate
+	}
+
+	if l.tokens > rate {
+		l.tokens = rate
+	}
+
+	l.last = now
+	l.tokens -= float64(n)
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type throttledReader struct {
+	ctx      context.Context
+	r        io.Reader
+	limiters []*bandwidthLimiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleChunk {
+		p = p[:throttleChunk]
+	}
+
+	n, err := t.r.Read(p)
+	if n > 0 {
+		for _, l := range t.limiters {
+			if werr := l.wait(t.ctx, n); werr != nil {
+				return n, werr
+			}
+		}
+	}
+
+	return n, err
+}
+
+// SetBandwidthLimit changes the bandwidth limits of the transfers on the fly, in bytes per second,
+// 0 means no limit. The transfers in progress are affected as well. The changes are not persisted,
+// the limits in the config will be used again after restart.
+func (p *Proxy) SetBandwidthLimit(perTransfer, aggregate int64) {
+	p.transferRate.Store(perTransfer)
+	p.aggregateRate.Store(aggregate)
+}
+
+// throttle wraps the reader of a piece transfer with the per-transfer and the aggregate bandwidth limits.
+// The reader is always wrapped, so that the limits set later apply to the transfer as well.
+func (p *Proxy) throttle(ctx context.Context, r io.Reader) io.Reader {
+	return &throttledReader{
+		ctx:      ctx,
+		r:        r,
+		limiters: []*bandwidthLimiter{newBandwidthLimiter(&p.transferRate), p.aggregate},
+	}
+}
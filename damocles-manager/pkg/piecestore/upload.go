@@ -0,0 +1,350 @@
+package piecestore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// HeaderUploadSession carries the id of the resumable upload session.
+	HeaderUploadSession = "Upload-Session"
+	// HeaderUploadOffset carries the count of the bytes already received in the upload session.
+	HeaderUploadOffset = "Upload-Offset"
+
+	defaultUploadSessionTimeout = time.Hour
+	// the upper bound of the interval of sweeping the expired upload sessions
+	uploadSweepInterval = time.Minute
+	// the size of the largest piece, i.e. the one filling a 64GiB sector
+	defaultMaxUploadSize = 64 << 30
+)
+
+// contentRange is the parsed `Content-Range: bytes <start>-<end>/<total>` header.
+type contentRange struct {
+	start int64
+	end   int64 // inclusive
+	total int64
+}
+
+func parseContentRange(v string) (contentRange, error) {
+	var cr contentRange
+	spec, ok := strings.CutPrefix(strings.TrimSpace(v), "bytes ")
+	if !ok {
+		return cr, fmt.Errorf("unsupported range unit in %q", v)
+	}
+
+	rng, total, ok := strings.Cut(spec, "/")
+	if !ok {
+		return cr, fmt.Errorf("missing total size in %q", v)
+	}
+
+	start, end, ok := strings.Cut(rng, "-")
+	if !ok {
+		return cr, fmt.Errorf("malformed range in %q", v)
+	}
+
+	var err error
+	if cr.start, err = strconv.ParseInt(start, 10, 64); err != nil {
+		return cr, fmt.Errorf("parse range start: %w", err)
+	}
+
+	if cr.end, err = strconv.ParseInt(end, 10, 64); err != nil {
+		return cr, fmt.Errorf("parse range end: %w", err)
+	}
+
+	if cr.total, err = strconv.ParseInt(total, 10, 64); err != nil {
+		return cr, fmt.Errorf("parse total size: %w", err)
+	}
+
+	if cr.start < 0 || cr.end < cr.start || cr.end >= cr.total {
+		return cr, fmt.Errorf("invalid range %q", v)
+	}
+
+	return cr, nil
+}
+
+// uploadSession holds the partial piece data of a resumable upload in a temp file.
+type uploadSession struct {
+	mu       sync.Mutex
+	id       string
+	key      string
+	path     string
+	total    int64
+	received int64
+	active   time.Time
+	// closed is set once the session has been removed, the requests waiting for the lock should give up
+	closed bool
+}
+
+// uploadSessions tracks the resumable upload sessions, the expired ones will be cleaned up
+// along with their temp files.
+type uploadSessions struct {
+	dir     string
+	timeout time.Duration
+	maxSize int64
+
+	mu       sync.Mutex
+	sessions map[string]*uploadSession
+}
+
+func newUploadSessions(dir string, timeout time.Duration, maxSize int64) *uploadSessions {
+	if timeout <= 0 {
+		timeout = defaultUploadSessionTimeout
+	}
+
+	if maxSize <= 0 {
+		maxSize = defaultMaxUploadSize
+	}
+
+	return &uploadSessions{
+		dir:      dir,
+		timeout:  timeout,
+		maxSize:  maxSize,
+		sessions: map[string]*uploadSession{},
+	}
+}
+
+func (us *uploadSessions) create(key string, total int64) (*uploadSession, error) {
+	f, err := os.CreateTemp(us.dir, "piece-upload-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp file: %w", err)
+	}
+
+	f.Close()
+
+	sess := &uploadSession{
+		id:     uuid.NewString(),
+		key:    key,
+		path:   f.Name(),
+		total:  total,
+		active: time.Now(),
+	}
+
+	us.mu.Lock()
+	us.sessions[sess.id] = sess
+	us.mu.Unlock()
+	return sess, nil
+}
+
+func (us *uploadSessions) get(id string) (*uploadSession, bool) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+	sess, ok := us.sessions[id]
+	return sess, ok
+}
+
+// remove drops the session along with its temp file, the caller should hold the lock of the session.
+func (us *uploadSessions) remove(sess *uploadSession) {
+	sess.closed = true
+
+	us.mu.Lock()
+	delete(us.sessions, sess.id)
+	us.mu.Unlock()
+
+	if err := os.Remove(sess.path); err != nil && !os.IsNotExist(err) {
+		log.Warnw("remove upload temp file", "session", sess.id, "path", sess.path, "err", err)
+	}
+}
+
+// sweep removes the sessions which have been inactive for longer than the timeout.
+func (us *uploadSessions) sweep() {
+	deadline := time.Now().Add(-us.timeout)
+	var expired []*uploadSession
+
+	us.mu.Lock()
+	for _, sess := range us.sessions {
+		// the sessions in use are skipped
+		if sess.mu.TryLock() {
+			if sess.active.Before(deadline) {
+				expired = append(expired, sess)
+			} else {
+				sess.mu.Unlock()
+			}
+		}
+	}
+	us.mu.Unlock()
+
+	for _, sess := range expired {
+		log.Infow("upload session expired", "session", sess.id, "key", sess.key, "received", sess.received)
+		us.remove(sess)
+		sess.mu.Unlock()
+	}
+}
+
+// sweepLoop sweeps the expired sessions periodically, and removes all the sessions once the ctx is done.
+func (us *uploadSessions) sweepLoop(ctx context.Context) {
+	interval := us.timeout
+	if interval > uploadSweepInterval {
+		interval = uploadSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			us.clear()
+			return
+		case <-ticker.C:
+			us.sweep()
+		}
+	}
+}
+
+// clear removes all the sessions, waiting for the ones in use.
+func (us *uploadSessions) clear() {
+	us.mu.Lock()
+	sessions := make([]*uploadSession, 0, len(us.sessions))
+	for _, sess := range us.sessions {
+		sessions = append(sessions, sess)
+	}
+	us.mu.Unlock()
+
+	for _, sess := range sessions {
+		sess.mu.Lock()
+		if !sess.closed {
+			us.remove(sess)
+		}
+		sess.mu.Unlock()
+	}
+}
+
+// handleChunkedPut appends the chunk described by the `Content-Range` header into the upload session,
+// and stores the piece once the full range has been received.
+// The first chunk starts a new session, whose id is returned in the `Upload-Session` header,
+// and should be carried by the following chunks. A chunk not starting at the received offset will be
+// rejected with 409, along with the `Upload-Offset` header for the client to resume from.
+// If the piece fails to be stored, the session is kept, and any chunk carrying it retries storing the piece.
+func (p *Proxy) handleChunkedPut(rw http.ResponseWriter, req *http.Request, key string) {
+	cr, err := parseContentRange(req.Header.Get("Content-Range"))
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if cr.total > p.uploads.maxSize {
+		http.Error(
+			rw,
+			fmt.Sprintf("total size %d exceeds the limit of %d bytes", cr.total, p.uploads.maxSize),
+			http.StatusRequestEntityTooLarge,
+		)
+		return
+	}
+
+	var sess *uploadSession
+	if id := req.Header.Get(HeaderUploadSession); id != "" {
+		found, ok := p.uploads.get(id)
+		if !ok {
+			http.Error(rw, fmt.Sprintf("upload session %s not found", id), http.StatusNotFound)
+			return
+		}
+
+		if found.key != key || found.total != cr.total {
+			http.Error(rw, fmt.Sprintf("upload session %s mismatched", id), http.StatusBadRequest)
+			return
+		}
+
+		sess = found
+	} else {
+		if cr.start != 0 {
+			http.Error(rw, "the first chunk of an upload session should start at 0", http.StatusBadRequest)
+			return
+		}
+
+		sess, err = p.uploads.create(key, cr.total)
+		if err != nil {
+			log.Errorw("create upload session", "key", key, "err", err)
+			http.Error(rw, fmt.Sprintf("create upload session: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		log.Infow("upload session created", "session", sess.id, "key", key, "total", cr.total)
+	}
+
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	if sess.closed {
+		http.Error(rw, fmt.Sprintf("upload session %s not found", sess.id), http.StatusNotFound)
+		return
+	}
+
+	rw.Header().Set(HeaderUploadSession, sess.id)
+	if sess.received == sess.total {
+		p.storeUploaded(rw, req, sess)
+		return
+	}
+
+	if cr.start != sess.received {
+		rw.Header().Set(HeaderUploadOffset, strconv.FormatInt(sess.received, 10))
+		http.Error(
+			rw,
+			fmt.Sprintf("chunk starts at %d, expected %d", cr.start, sess.received),
+			http.StatusConflict,
+		)
+		return
+	}
+
+	written, err := appendChunk(sess.path, p.throttle(req.Context(), req.Body), cr.end-cr.start+1)
+	sess.received += written
+	sess.active = time.Now()
+	rw.Header().Set(HeaderUploadOffset, strconv.FormatInt(sess.received, 10))
+	if err != nil {
+		log.Warnw("append upload chunk", "session", sess.id, "key", key, "written", written, "err", err)
+		http.Error(rw, fmt.Sprintf("append chunk: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if sess.received < sess.total {
+		rw.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	p.storeUploaded(rw, req, sess)
+}
+
+// storeUploaded stores the piece assembled in the session, which will be removed only if succeeded,
+// the caller should hold the lock of the session.
+func (p *Proxy) storeUploaded(rw http.ResponseWriter, req *http.Request, sess *uploadSession) {
+	f, err := os.Open(sess.path)
+	if err != nil {
+		log.Errorw("open upload temp file", "session", sess.id, "key", sess.key, "err", err)
+		http.Error(rw, fmt.Sprintf("open uploaded data: %s", err), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	if p.storePiece(rw, req, sess.key, f, sess.total) {
+		p.uploads.remove(sess)
+	}
+}
+
+// appendChunk appends exactly `size` bytes from r into the file, the partial data will be
+// kept so that the upload can be resumed from the returned offset.
+func appendChunk(path string, r io.Reader, size int64) (int64, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return 0, fmt.Errorf("open temp file: %w", err)
+	}
+	defer f.Close()
+
+	written, err := io.Copy(f, io.LimitReader(r, size))
+	if err != nil {
+		return written, fmt.Errorf("copy chunk data: %w", err)
+	}
+
+	if written != size {
+		return written, fmt.Errorf("chunk data truncated, %d of %d bytes received", written, size)
+	}
+
+	return written, nil
+}